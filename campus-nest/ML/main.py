@@ -1,7 +1,7 @@
 from flask import Flask, request, jsonify
 from flask_cors import CORS
 import requests
-from models.resume import parse_resume
+from models.resume import parse_resume, extract_from_pdf, groq_llm
 from pymongo import MongoClient
 from bson import ObjectId
 from datetime import datetime
@@ -107,6 +107,27 @@ def parse_and_store_resume():
         print(f"An error occurred: {e}")
         return jsonify({"error": str(e)}), 500
     
+@app.route("/parse", methods=["POST"])
+def parse_uploaded_resume():
+    # Used by the backend's parse queue. It only parses; storing the result
+    # is up to the caller. 4xx answers are final, 5xx answers are retried.
+    upload = request.files.get("file")
+    if upload is None:
+        return jsonify({"error": "Missing 'file' in multipart body"}), 400
+    if upload.mimetype != "application/pdf":
+        return jsonify({"error": "Only PDF resumes can be parsed"}), 415
+
+    text = extract_from_pdf(upload.read())
+    if not text or not text.strip():
+        return jsonify({"error": "Could not extract text from the resume"}), 422
+
+    parsed_data = groq_llm(text)
+    if "error" in parsed_data:
+        return jsonify(parsed_data), 502
+
+    return jsonify({"text": text, "parsed_data": parsed_data}), 200
+
+
 @app.route('/api/v1/student/resume/analyze', methods=['POST'])
 def analyze_resume():
     auth_header = request.headers.get('Authorization')
@@ -0,0 +1,38 @@
+﻿package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"backend/config"
+	"backend/services"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report drift without writing changes")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment")
+	}
+	client, err := config.ConnectDB()
+	if err != nil {
+		log.Fatal("Error connecting to database:", err)
+	}
+	defer client.Disconnect(context.TODO())
+
+	placementService := services.NewPlacementService(client.Database(config.DatabaseName))
+	report, err := placementService.ReconcilePlacements(*dryRun)
+	if report != nil {
+		for _, change := range report.Changes {
+			log.Printf("%s (%s): %q -> %q %s %s %.2f", change.StudentID.Hex(), change.Email, change.FromStatus, change.ToStatus, change.Company, change.Role, change.Salary)
+		}
+		log.Printf("Checked %d student(s), %d drifted, %d updated (dry run: %v)", report.Checked, len(report.Changes), report.Updated, report.DryRun)
+	}
+	if err != nil {
+		log.Fatal("Reconciliation failed:", err)
+	}
+}
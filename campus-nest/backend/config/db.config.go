@@ -10,6 +10,8 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const DatabaseName = "campusNestDB"
+
 func ConnectDB() (*mongo.Client, error) {
 	uri := os.Getenv("MONGO_URI")
 	if uri == "" {
@@ -6,19 +6,20 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AdminController struct {
 	adminService          services.AdminService
+	placementService      services.PlacementService
+	jobService            services.JobService
+	savedSearchService    services.SavedSearchService
 	JobCollection         *mongo.Collection
 	CompanyCollection     *mongo.Collection
 	ApplicationCollection *mongo.Collection
@@ -178,7 +179,7 @@ func (ac *AdminController) ExportReport(c *gin.Context) {
 				company = v
 				companyHires[company]++
 			}
-			if v, ok := s["role"].(string); ok {
+			if v, ok := s["placedRole"].(string); ok {
 				role = v
 				roleDistribution[role]++
 			}
@@ -463,6 +464,9 @@ func (ac *AdminController) ExportReport(c *gin.Context) {
 func NewAdminController(db *mongo.Database) *AdminController {
 	return &AdminController{
 		adminService:          services.NewAdminService(db),
+		placementService:      services.NewPlacementService(db),
+		jobService:            services.NewJobService(db),
+		savedSearchService:    services.NewSavedSearchService(db),
 		JobCollection:         db.Collection("jobs"),
 		CompanyCollection:     db.Collection("companies"),
 		ApplicationCollection: db.Collection("applications"),
@@ -558,6 +562,17 @@ func (ac *AdminController) GetPlacementStats(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"stats": stats})
 }
+func (ac *AdminController) ReconcilePlacements(c *gin.Context) {
+	dryRun := c.Query("dryRun") == "true"
+
+	report, err := ac.placementService.ReconcilePlacements(dryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile placements", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
 func (ac *AdminController) GetCompanyAnalytics(c *gin.Context) {
 	filters := map[string]string{}
 	if v := c.Query("from"); v != "" {
@@ -587,9 +602,6 @@ func (ac *AdminController) GetCompanyAnalytics(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"analytics": analytics})
 }
 func (ac *AdminController) CreateJobDrive(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
 	userIDHex, _ := c.Get("userID")
 	adminID, err := primitive.ObjectIDFromHex(userIDHex.(string))
 	if err != nil {
@@ -603,76 +615,22 @@ func (ac *AdminController) CreateJobDrive(c *gin.Context) {
 		return
 	}
 
-	if job.Position == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Position is required"})
-		return
-	}
-	if job.CompanyName.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Company name is required"})
-		return
-	}
-	if job.Description == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Job description is required"})
-		return
-	}
-	if !job.CompanyName.CompanyID.IsZero() {
-		var company models.Company
-		err = ac.CompanyCollection.FindOne(ctx, bson.M{"_id": job.CompanyName.CompanyID}).Decode(&company)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid company ID - company not found"})
-			return
-		}
-	}
-
-	
-	if job.ApplicationDeadline.Before(time.Now()) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Application deadline must be in the future"})
-		return
-	}
-
-	
-	if job.Eligibility.MinCGPA < 0 || job.Eligibility.MinCGPA > 10 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Minimum CGPA must be between 0 and 10"})
-		return
-	}
-
-	
-	if job.Eligibility.GraduationYear != 0 {
-		currentYear := time.Now().Year()
-		if job.Eligibility.GraduationYear < currentYear || job.Eligibility.GraduationYear > currentYear+10 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Graduation year must be between %d and %d", currentYear, currentYear+10)})
-			return
-		}
-	}
-
-	
-	if job.Eligibility.MaxBacklogs < 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Maximum backlogs cannot be negative"})
-		return
-	}
-
-	
-	if job.Location == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Job location is required"})
-		return
-	}
-
-	
-	job.ID = primitive.NewObjectID()
 	job.PostedBy = adminID
-	job.CreatedAt = time.Now()
-	job.Status = "open"
-
-	
-	_, err = ac.JobCollection.InsertOne(ctx, job)
+	driveID, err := ac.jobService.CreateJob(&job)
 	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create drive"})
 		return
 	}
+	alertSavedSearches(ac.savedSearchService, job)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Drive created successfully",
-		"driveId": job.ID,
+		"driveId": driveID,
+		"drive":   job,
 	})
 }
 
@@ -681,7 +639,12 @@ func (ac *AdminController) GetAllDrives(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	
+	q, ok := listQuery(c, services.DriveListSpec)
+	if !ok {
+		return
+	}
+
+
 	pipeline := []bson.M{
 		{
 			"$lookup": bson.M{
@@ -696,27 +659,15 @@ func (ac *AdminController) GetAllDrives(c *gin.Context) {
 				"applicantCount": bson.M{"$size": "$applications"},
 			},
 		},
-		{
-			"$sort": bson.M{"created_at": -1},
-		},
 	}
 
-	cursor, err := ac.JobCollection.Aggregate(ctx, pipeline)
+	page, _, err := services.AggregatePage[bson.M](ctx, ac.JobCollection, pipeline, nil, q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch drives"})
 		return
 	}
 
-	var drives []bson.M
-	if err = cursor.All(ctx, &drives); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode drives"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"drives": drives,
-		"total":  len(drives),
-	})
+	c.JSON(http.StatusOK, page)
 }
 
 
@@ -774,8 +725,13 @@ func (ac *AdminController) GetDriveApplications(c *gin.Context) {
 		return
 	}
 
+	q, ok := listQuery(c, services.ApplicationListSpec)
+	if !ok {
+		return
+	}
+
 	
-	pipeline := []bson.M{
+	match := []bson.M{
 		{
 			"$match": bson.M{"job_id": objectID},
 		},
@@ -787,6 +743,11 @@ func (ac *AdminController) GetDriveApplications(c *gin.Context) {
 				"as":           "student",
 			},
 		},
+		{
+			"$unwind": "$student",
+		},
+	}
+	shape := []bson.M{
 		{
 			"$lookup": bson.M{
 				"from":         "resumes",
@@ -795,9 +756,6 @@ func (ac *AdminController) GetDriveApplications(c *gin.Context) {
 				"as":           "resume",
 			},
 		},
-		{
-			"$unwind": "$student",
-		},
 		{
 			"$unwind": bson.M{
 				"path":                       "$resume",
@@ -821,31 +779,19 @@ func (ac *AdminController) GetDriveApplications(c *gin.Context) {
 				},
 				"resume": bson.M{
 					"_id":      "$resume._id",
-					"file_url": "$resume.file_url",
+					"link_url": resumeLinkURL,
 				},
 			},
 		},
-		{
-			"$sort": bson.M{"applied_on": -1},
-		},
 	}
 
-	cursor, err := ac.ApplicationCollection.Aggregate(ctx, pipeline)
+	page, _, err := services.AggregatePage[bson.M](ctx, ac.ApplicationCollection, match, shape, q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
 		return
 	}
 
-	var applications []bson.M
-	if err = cursor.All(ctx, &applications); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode applications"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"applications": applications,
-		"total":        len(applications),
-	})
+	c.JSON(http.StatusOK, page)
 }
 
 func (ac *AdminController) GetStudents(c *gin.Context) {
@@ -872,60 +818,24 @@ func (ac *AdminController) GetStudents(c *gin.Context) {
 		}
 	}
 
-	limit := int64(0)
-	page := int64(0)
-	if l := c.Query("limit"); l != "" {
-		if v, err := strconv.ParseInt(l, 10, 64); err == nil {
-			limit = v
-		}
-	}
-	if p := c.Query("page"); p != "" {
-		if v, err := strconv.ParseInt(p, 10, 64); err == nil {
-			page = v
-		}
+	q, ok := listQuery(c, services.StudentListSpec)
+	if !ok {
+		return
 	}
 
 	userCol := ac.adminService.(*services.AdminServiceImpl).UserCollection()
-	var cursor *mongo.Cursor
-	var err error
-	if limit > 0 {
-		opts := options.Find().SetLimit(limit).SetSkip(page * limit)
-		cursor, err = userCol.Find(ctx, filter, opts)
-	} else {
-		cursor, err = userCol.Find(ctx, filter)
-	}
+	page, rawStudents, err := services.FindPage[bson.M](ctx, userCol, filter, q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch students", "details": err.Error()})
 		return
 	}
-	defer cursor.Close(ctx)
-
-	var rawStudents []bson.M
-	if err := cursor.All(ctx, &rawStudents); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode students", "details": err.Error()})
-		return
-	}
 
-	total := len(rawStudents)
-	placed := 0
-	shortlisted := 0
-	interviewed := 0
-
-	studentsOut := make([]gin.H, 0, total)
+	studentsOut := make([]gin.H, 0, len(rawStudents))
 	for _, s := range rawStudents {
-		ps := "Unplaced"
+		ps := services.PlacementStatusUnplaced
 		if p, ok := s["placedStatus"].(string); ok && p != "" {
 			ps = p
 		}
-		if ps == "Placed" {
-			placed++
-		}
-		if v, ok := s["shortlistedCount"].(int); ok && v > 0 {
-			shortlisted += v
-		}
-		if v, ok := s["interviewedCount"].(int); ok && v > 0 {
-			interviewed += v
-		}
 
 		studentsOut = append(studentsOut, gin.H{
 			"id":           s["_id"],
@@ -938,26 +848,44 @@ func (ac *AdminController) GetStudents(c *gin.Context) {
 		})
 	}
 
-	companyCount, _ := ac.adminService.(*services.AdminServiceImpl).UserCollection().Database().Collection("companies").CountDocuments(ctx, bson.M{})
-	applicationCount, _ := ac.adminService.(*services.AdminServiceImpl).UserCollection().Database().Collection("applications").CountDocuments(ctx, bson.M{})
+	// The statistics describe every matching student, not only this page.
+	db := userCol.Database()
+	placed, _ := userCol.CountDocuments(ctx, bson.M{"$and": bson.A{filter, bson.M{"placedStatus": services.PlacementStatusPlaced}}})
+	shortlisted, _ := db.Collection("applications").CountDocuments(ctx, bson.M{"status": models.ApplicationStatusShortlisted})
+	interviewed, _ := db.Collection("applications").CountDocuments(ctx, bson.M{"status": models.ApplicationStatusInterviewed})
+	companyCount, _ := db.Collection("companies").CountDocuments(ctx, bson.M{})
+	applicationCount, _ := db.Collection("applications").CountDocuments(ctx, bson.M{})
 
-	c.JSON(http.StatusOK, gin.H{
-		"students": studentsOut,
-		"statistics": gin.H{
-			"totalReturned": total,
-			"placed":        placed,
-			"shortlisted":   shortlisted,
-			"interviewed":   interviewed,
-			"companies":     companyCount,
-			"applications":  applicationCount,
-		},
-	})
+	page.Items = studentsOut
+	page.Summary = gin.H{
+		"placed":       placed,
+		"shortlisted":  shortlisted,
+		"interviewed":  interviewed,
+		"companies":    companyCount,
+		"applications": applicationCount,
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+var tpoListSpec = services.ListSpec{
+	Sorts: map[string]string{
+		"firstName":  "firstName",
+		"lastName":   "lastName",
+		"department": "department",
+		"createdAt":  "createdAt",
+	},
+	DefaultSort: "firstName",
 }
 
 func (ac *AdminController) GetAllTPOs(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	q, ok := listQuery(c, tpoListSpec)
+	if !ok {
+		return
+	}
+
 	filter := bson.M{"role": "tpo"}
 
 	if dept := c.Query("department"); dept != "" {
@@ -974,19 +902,14 @@ func (ac *AdminController) GetAllTPOs(c *gin.Context) {
 	}
 
 	userCol := ac.adminService.(*services.AdminServiceImpl).UserCollection()
-	cursor, err := userCol.Find(ctx, filter)
+	page, found, err := services.FindPage[models.User](ctx, userCol, filter, q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch TPOs", "details": err.Error()})
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var tpos []gin.H
-	for cursor.Next(ctx) {
-		var tpo models.User
-		if err := cursor.Decode(&tpo); err != nil {
-			continue
-		}
+	tpos := make([]gin.H, 0, len(found))
+	for _, tpo := range found {
 
 		studentCount := int64(0)
 		if tpo.Department != nil {
@@ -1009,10 +932,8 @@ func (ac *AdminController) GetAllTPOs(c *gin.Context) {
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"tpos":  tpos,
-		"total": len(tpos),
-	})
+	page.Items = tpos
+	c.JSON(http.StatusOK, page)
 }
 
 func (ac *AdminController) AddTPO(c *gin.Context) {
@@ -0,0 +1,172 @@
+﻿package controllers
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/models"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AttachmentController struct {
+	UserCollection *mongo.Collection
+
+	attachmentService services.AttachmentService
+}
+
+func NewAttachmentController(db *mongo.Database) *AttachmentController {
+	return &AttachmentController{
+		UserCollection: db.Collection("users"),
+
+		attachmentService: services.NewAttachmentService(db),
+	}
+}
+
+// caller loads the signed in user. Drive routes name the drive :driveId for
+// TPOs and admins and :jobId for recruiters and students.
+func (ac *AttachmentController) caller(c *gin.Context) (*models.User, primitive.ObjectID, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	driveParam := c.Param("driveId")
+	if driveParam == "" {
+		driveParam = c.Param("jobId")
+	}
+	jobID, err := primitive.ObjectIDFromHex(driveParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid drive ID"})
+		return nil, jobID, false
+	}
+
+	userIDHex, _ := c.Get("userID")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	var user models.User
+	if err := ac.UserCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil, jobID, false
+	}
+	return &user, jobID, true
+}
+
+// companyScope limits recruiters to their own company's drives.
+func companyScope(c *gin.Context, user *models.User) (*primitive.ObjectID, bool) {
+	if user.Role != "rec" {
+		return nil, true
+	}
+	if user.CompanyID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No company associated. Please contact admin."})
+		return nil, false
+	}
+	return user.CompanyID, true
+}
+
+func (ac *AttachmentController) UploadDriveAttachment(c *gin.Context) {
+	user, jobID, ok := ac.caller(c)
+	if !ok {
+		return
+	}
+	companyID, ok := companyScope(c, user)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxAttachmentSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attach the document as the 'file' form field, at most " + strconv.Itoa(services.MaxAttachmentSize>>20) + " MB"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := ac.attachmentService.AddJobAttachment(jobID, companyID, header.Filename, file, header.Size, user.ID)
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Drive not found or not accessible"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store attachment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Attachment uploaded successfully",
+		"attachment": attachment,
+	})
+}
+
+func (ac *AttachmentController) DeleteDriveAttachment(c *gin.Context) {
+	user, jobID, ok := ac.caller(c)
+	if !ok {
+		return
+	}
+	companyID, ok := companyScope(c, user)
+	if !ok {
+		return
+	}
+
+	attachmentID, err := primitive.ObjectIDFromHex(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	if err := ac.attachmentService.DeleteJobAttachment(jobID, attachmentID, companyID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete attachment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
+
+func (ac *AttachmentController) DownloadDriveAttachment(c *gin.Context) {
+	user, jobID, ok := ac.caller(c)
+	if !ok {
+		return
+	}
+
+	attachmentID, err := primitive.ObjectIDFromHex(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	attachment, file, err := ac.attachmentService.OpenJobAttachment(jobID, attachmentID, user)
+	if err != nil {
+		if err == services.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to download this attachment"})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open attachment"})
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, file, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+	})
+}
@@ -21,12 +21,17 @@ func NewCompanyController(db *mongo.Database) *CompanyController {
 }
 
 func (cc *CompanyController) GetAllCompanies(c *gin.Context) {
-	companies, err := cc.companyService.GetAllCompanies()
+	q, ok := listQuery(c, services.CompanyListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := cc.companyService.GetAllCompanies(q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch companies", "details": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"companies": companies})
+	c.JSON(http.StatusOK, page)
 }
 
 func (cc *CompanyController) AddCompanyWithRecruiters(c *gin.Context) {
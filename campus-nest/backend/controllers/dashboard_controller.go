@@ -4,9 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"math"
+	"mime"
 	"net/http"
-	"strconv"
 	"time"
 
 	"backend/models"
@@ -36,11 +37,13 @@ type DashboardController struct {
 	companyService   services.CompanyService
 	studentService   services.StudentService
 	tpoService       services.TPOService
-}
-
+	placementService services.PlacementService
+	waitlistService  services.WaitlistService
 
-type UpdateSkillsRequest struct {
-	Skills []string `json:"skills" binding:"required"`
+	savedSearchService  services.SavedSearchService
+	resumeExportService services.ResumeExportService
+	matchService        services.MatchService
+	skillService        services.SkillService
 }
 
 
@@ -59,6 +62,13 @@ func NewDashboardController(db *mongo.Database) *DashboardController {
 		companyService:   services.NewCompanyService(db),
 		studentService:   services.NewStudentService(db),
 		tpoService:       services.NewTPOService(db),
+		placementService: services.NewPlacementService(db),
+		waitlistService:  services.NewWaitlistService(db),
+
+		savedSearchService:  services.NewSavedSearchService(db),
+		resumeExportService: services.NewResumeExportService(db),
+		matchService:        services.NewMatchService(db),
+		skillService:        services.NewSkillService(db),
 	}
 }
 
@@ -693,42 +703,41 @@ func (dc *DashboardController) getRecruiterDashboard(c *gin.Context) {
 		"recruiterName":   recruiter.FirstName + " " + recruiter.LastName,
 	})
 }
-func (dc *DashboardController) UpdateSkills(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+// UpdateProfile saves the profile fields a student sends: skills, preferred
+// locations, graduation year and the resume builder's lists.
+func (dc *DashboardController) UpdateProfile(c *gin.Context) {
 	userIDHex, _ := c.Get("userID")
-	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
-
+	studentID, err := primitive.ObjectIDFromHex(userIDHex.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
 
-	var req UpdateSkillsRequest
+	var req services.ProfileUpdate
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
 		return
 	}
 
-
-	update := bson.M{
-		"$set": bson.M{
-			"skills": req.Skills,
-		},
-	}
-
-
-	_, err := dc.UserCollection.UpdateByID(ctx, studentID, update)
+	profile, err := dc.studentService.UpdateStudentProfile(studentID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update skills in the database"})
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Skills updated successfully", "skills": req.Skills})
+	c.JSON(http.StatusOK, gin.H{"message": "Profile updated successfully", "skills": profile.Skills, "profile": profile})
 }
 
 
 func (dc *DashboardController) CreateDrive(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
 	userIDHex, _ := c.Get("userID")
 	tpoID, err := primitive.ObjectIDFromHex(userIDHex.(string))
 	if err != nil {
@@ -742,81 +751,22 @@ func (dc *DashboardController) CreateDrive(c *gin.Context) {
 		return
 	}
 
-
-
-
-	if job.Position == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Position is required"})
-		return
-	}
-	if job.CompanyName.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Company name is required"})
-		return
-	}
-	if job.Description == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Job description is required"})
-		return
-	}
-
-
-	if !job.CompanyName.CompanyID.IsZero() {
-		var company models.Company
-		err = dc.CompanyCollection.FindOne(ctx, bson.M{"_id": job.CompanyName.CompanyID}).Decode(&company)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid company ID - company not found"})
-			return
-		}
-	}
-
-
-	if job.ApplicationDeadline.Before(time.Now()) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Application deadline must be in the future"})
-		return
-	}
-
-
-	if job.Eligibility.MinCGPA < 0 || job.Eligibility.MinCGPA > 10 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Minimum CGPA must be between 0 and 10"})
-		return
-	}
-
-
-	if job.Eligibility.GraduationYear != 0 {
-		currentYear := time.Now().Year()
-		if job.Eligibility.GraduationYear < currentYear || job.Eligibility.GraduationYear > currentYear+10 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Graduation year must be between %d and %d", currentYear, currentYear+10)})
-			return
-		}
-	}
-
-
-	if job.Eligibility.MaxBacklogs < 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Maximum backlogs cannot be negative"})
-		return
-	}
-
-
-	if job.Location == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Job location is required"})
-		return
-	}
-
-
-	job.ID = primitive.NewObjectID()
 	job.PostedBy = tpoID
-	job.CreatedAt = time.Now()
-	job.Status = "open"
-
-
-	_, err = dc.JobCollection.InsertOne(ctx, job)
+	driveID, err := dc.jobService.CreateJob(&job)
 	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create drive"})
 		return
 	}
+	alertSavedSearches(dc.savedSearchService, job)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Drive created successfully",
-		"driveId": job.ID,
+		"driveId": driveID,
+		"drive":   job,
 	})
 }
 
@@ -1161,6 +1111,11 @@ func (dc *DashboardController) GetAllDrives(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
+	q, ok := listQuery(c, services.DriveListSpec)
+	if !ok {
+		return
+	}
+
 
 	pipeline := []bson.M{
 		{
@@ -1176,27 +1131,15 @@ func (dc *DashboardController) GetAllDrives(c *gin.Context) {
 				"applicantCount": bson.M{"$size": "$applications"},
 			},
 		},
-		{
-			"$sort": bson.M{"created_at": -1},
-		},
 	}
 
-	cursor, err := dc.JobCollection.Aggregate(ctx, pipeline)
+	page, _, err := services.AggregatePage[bson.M](ctx, dc.JobCollection, pipeline, nil, q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch drives"})
 		return
 	}
 
-	var drives []bson.M
-	if err = cursor.All(ctx, &drives); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode drives"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"drives": drives,
-		"total":  len(drives),
-	})
+	c.JSON(http.StatusOK, page)
 }
 
 
@@ -1254,8 +1197,13 @@ func (dc *DashboardController) GetDriveApplications(c *gin.Context) {
 		return
 	}
 
+	q, ok := listQuery(c, services.ApplicationListSpec)
+	if !ok {
+		return
+	}
 
-	pipeline := []bson.M{
+
+	match := []bson.M{
 		{
 			"$match": bson.M{"job_id": objectID},
 		},
@@ -1267,6 +1215,11 @@ func (dc *DashboardController) GetDriveApplications(c *gin.Context) {
 				"as":           "student",
 			},
 		},
+		{
+			"$unwind": "$student",
+		},
+	}
+	shape := []bson.M{
 		{
 			"$lookup": bson.M{
 				"from":         "resumes",
@@ -1275,9 +1228,6 @@ func (dc *DashboardController) GetDriveApplications(c *gin.Context) {
 				"as":           "resume",
 			},
 		},
-		{
-			"$unwind": "$student",
-		},
 		{
 			"$unwind": bson.M{
 				"path":                       "$resume",
@@ -1301,34 +1251,152 @@ func (dc *DashboardController) GetDriveApplications(c *gin.Context) {
 				},
 				"resume": bson.M{
 					"_id":      "$resume._id",
-					"file_url": "$resume.file_url",
+					"link_url": resumeLinkURL,
 				},
 			},
 		},
-		{
-			"$sort": bson.M{"applied_on": -1},
-		},
 	}
 
-	cursor, err := dc.ApplicationCollection.Aggregate(ctx, pipeline)
+	page, _, err := services.AggregatePage[bson.M](ctx, dc.ApplicationCollection, match, shape, q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
 		return
 	}
 
-	var applications []bson.M
-	if err = cursor.All(ctx, &applications); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode applications"})
+	c.JSON(http.StatusOK, page)
+}
+
+
+func (dc *DashboardController) ImportDrives(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	tpoID, err := primitive.ObjectIDFromHex(userIDHex.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid TPO ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A CSV or XLSX file is required in the 'file' field"})
+		return
+	}
+	if fileHeader.Size > 10<<20 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is too large, the limit is 10 MB"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
 		return
 	}
+	defer file.Close()
+
+	opts := services.DriveImportOptions{
+		DryRun:      c.Query("dryRun") == "true",
+		SkipInvalid: c.Query("skipInvalid") == "true",
+	}
+	report, err := dc.jobService.ImportJobs(file, fileHeader.Filename, opts, tpoID)
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import drives", "details": err.Error()})
+		return
+	}
+
+	alertSavedSearches(dc.savedSearchService, report.Drives...)
+
+	status := http.StatusCreated
+	message := fmt.Sprintf("Imported %d drive(s)", report.Created)
+	switch {
+	case opts.DryRun:
+		status = http.StatusOK
+		message = "Preview only, nothing was saved"
+	case report.Created == 0 && report.InvalidRows > 0:
+		status = http.StatusUnprocessableEntity
+		message = "No drives were imported, fix the invalid rows or retry with skipInvalid=true"
+	}
+	c.JSON(status, gin.H{
+		"message": message,
+		"report":  report,
+	})
+}
 
+
+func (dc *DashboardController) UpdateDrive(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	tpoID, err := primitive.ObjectIDFromHex(userIDHex.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid TPO ID"})
+		return
+	}
+
+	driveID, err := primitive.ObjectIDFromHex(c.Param("driveId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid drive ID"})
+		return
+	}
+
+	var req services.JobUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	result, err := dc.jobService.UpdateJob(driveID, &req, tpoID)
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Drive not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update drive", "details": err.Error()})
+		return
+	}
+
+	// Publishing a draft is when students first see the drive.
+	if result.Published {
+		alertSavedSearches(dc.savedSearchService, *result.Job)
+	}
+
+	message := "Drive updated successfully"
+	if len(result.ChangedFields) == 0 {
+		message = "No changes to apply"
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"applications": applications,
-		"total":        len(applications),
+		"message": message,
+		"result":  result,
 	})
 }
 
 
+func (dc *DashboardController) GetDriveVersions(c *gin.Context) {
+	driveID, err := primitive.ObjectIDFromHex(c.Param("driveId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid drive ID"})
+		return
+	}
+
+	q, ok := listQuery(c, services.JobVersionListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := dc.jobService.GetJobVersions(driveID, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch drive history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+
 func (dc *DashboardController) UpdateDriveStatus(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -1363,15 +1431,21 @@ func (dc *DashboardController) UpdateDriveStatus(c *gin.Context) {
 		},
 	}
 
-	result, err := dc.JobCollection.UpdateByID(ctx, objectID, update)
+	var previous models.Job
+	err = dc.JobCollection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update).Decode(&previous)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Drive not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update drive status"})
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Drive not found"})
-		return
+	// Publishing a draft is when students first see the drive.
+	if previous.Status == models.JobStatusDraft && req.Status == models.JobStatusOpen {
+		previous.Status = req.Status
+		alertSavedSearches(dc.savedSearchService, previous)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -1673,10 +1747,24 @@ func (dc *DashboardController) PreviewNotification(c *gin.Context) {
 }
 
 
+// notificationHistorySpec sorts the broadcasts grouped from student inboxes.
+var notificationHistorySpec = services.ListSpec{
+	Sorts: map[string]string{
+		"sentAt":     "sentAt",
+		"recipients": "recipientCount",
+	},
+	DefaultSort: "-sentAt",
+}
+
+
 func (dc *DashboardController) GetNotificationHistory(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 
+	q, ok := listQuery(c, notificationHistorySpec)
+	if !ok {
+		return
+	}
 
 
 	pipeline := []bson.M{
@@ -1704,34 +1792,62 @@ func (dc *DashboardController) GetNotificationHistory(c *gin.Context) {
 				"recipientCount": bson.M{"$sum": 1},
 			},
 		},
-		{
-			"$sort": bson.M{"sentAt": -1},
-		},
-		{
-			"$limit": 50,
-		},
 	}
 
-	cursor, err := dc.UserCollection.Aggregate(ctx, pipeline)
+	page, _, err := services.AggregatePage[bson.M](ctx, dc.UserCollection, pipeline, nil, q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification history"})
 		return
 	}
 
-	var notificationHistory []bson.M
-	if err = cursor.All(ctx, &notificationHistory); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode notification history"})
-		return
-	}
+	c.JSON(http.StatusOK, page)
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"notifications": notificationHistory,
-		"total":         len(notificationHistory),
-	})
+
+
+
+var candidateListSpec = services.ListSpec{
+	Sorts: map[string]string{
+		"appliedOn":  "appliedOn",
+		"name":       "student.name",
+		"cgpa":       "student.cgpa",
+		"matchScore": "match.score",
+	},
+	DefaultSort: "-appliedOn",
+}
+
+// driveCandidateSorts are the orders GetJobDriveDetails accepts in ?sort=.
+var driveCandidateSorts = map[string]bson.D{
+	"-appliedOn":  {{Key: "applied_on", Value: -1}},
+	"appliedOn":   {{Key: "applied_on", Value: 1}},
+	"-matchScore": {{Key: "match.score", Value: -1}, {Key: "applied_on", Value: -1}},
+	"matchScore":  {{Key: "match.score", Value: 1}, {Key: "applied_on", Value: -1}},
 }
 
 
+// submitted projects a profile field of the joined $student as it was when
+// they applied. Applications from before snapshots show the live value.
+func submitted(snapshotField, studentField string) bson.M {
+	return bson.M{"$ifNull": bson.A{"$snapshot." + snapshotField, "$student." + studentField}}
+}
 
+// changedSinceApplied flags applications whose student has since changed
+// the fields shown in candidate lists. The student details endpoint lists
+// every change.
+var changedSinceApplied = bson.M{"$and": bson.A{
+	bson.M{"$gt": bson.A{"$snapshot", nil}},
+	bson.M{"$or": bson.A{
+		bson.M{"$ne": bson.A{"$snapshot.first_name", "$student.firstName"}},
+		bson.M{"$ne": bson.A{"$snapshot.last_name", "$student.lastName"}},
+		bson.M{"$ne": bson.A{"$snapshot.email", "$student.email"}},
+		bson.M{"$ne": bson.A{"$snapshot.department", "$student.department"}},
+		bson.M{"$ne": bson.A{"$snapshot.cgpa", "$student.cgpa"}},
+		bson.M{"$not": bson.A{bson.M{"$setEquals": bson.A{
+			bson.M{"$ifNull": bson.A{"$snapshot.skills", bson.A{}}},
+			bson.M{"$ifNull": bson.A{"$student.skills", bson.A{}}},
+		}}}},
+	}},
+}}
 
 func (dc *DashboardController) GetRecruiterCandidates(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
@@ -1745,6 +1861,11 @@ func (dc *DashboardController) GetRecruiterCandidates(c *gin.Context) {
 		return
 	}
 
+	q, ok := listQuery(c, candidateListSpec)
+	if !ok {
+		return
+	}
+
 
 	var recruiter models.User
 	err = dc.UserCollection.FindOne(ctx, bson.M{"_id": recruiterID}).Decode(&recruiter)
@@ -1754,13 +1875,7 @@ func (dc *DashboardController) GetRecruiterCandidates(c *gin.Context) {
 	}
 
 	if recruiter.CompanyID == nil {
-		c.JSON(http.StatusOK, gin.H{
-			"candidates":       []gin.H{},
-			"total":            0,
-			"message":          "No company associated. Please contact admin to associate your account with a company.",
-			"jobPosition":      "",
-			"totalShortlisted": 0,
-		})
+		c.JSON(http.StatusOK, services.ListPage{Items: []gin.H{}})
 		return
 	}
 
@@ -1784,14 +1899,14 @@ func (dc *DashboardController) GetRecruiterCandidates(c *gin.Context) {
 	}
 
 	if len(jobIDs) == 0 {
-		c.JSON(http.StatusOK, gin.H{
-			"candidates": []gin.H{},
-			"total":      0,
-			"message":    "No candidates found for your company",
-		})
+		c.JSON(http.StatusOK, services.ListPage{Items: []gin.H{}})
 		return
 	}
 
+	if _, err := dc.matchService.ScoreUnscoredApplications(jobIDs); err != nil {
+		log.Printf("Could not score candidates of company %s: %v", recruiter.CompanyID.Hex(), err)
+	}
+
 
 	pipeline := []bson.M{
 		{
@@ -1840,71 +1955,33 @@ func (dc *DashboardController) GetRecruiterCandidates(c *gin.Context) {
 			"$project": bson.M{
 				"_id": 1,
 				"student": bson.M{
-					"name":       bson.M{"$concat": []interface{}{"$student.firstName", " ", "$student.lastName"}},
-					"email":      "$student.email",
-					"department": "$student.department",
-					"cgpa":       "$student.cgpa",
-					"skills":     "$student.skills",
+					"name":       bson.M{"$concat": []interface{}{submitted("first_name", "firstName"), " ", submitted("last_name", "lastName")}},
+					"email":      submitted("email", "email"),
+					"department": submitted("department", "department"),
+					"cgpa":       submitted("cgpa", "cgpa"),
+					"skills":     submitted("skills", "skills"),
 				},
+				"changedSinceApplied": changedSinceApplied,
 				"job": bson.M{
 					"position": "$job.position",
 					"title":    "$job.position",
 				},
 				"resume": bson.M{
-					"file_url": "$resume.file_url",
+					"link_url": resumeLinkURL,
 				},
+				"match":     "$match",
 				"appliedOn": "$applied_on",
 			},
 		},
-		{
-			"$sort": bson.M{"appliedOn": -1},
-		},
 	}
 
-	cursor, err := dc.ApplicationCollection.Aggregate(ctx, pipeline)
+	page, _, err := services.AggregatePage[bson.M](ctx, dc.ApplicationCollection, pipeline, nil, q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch candidates"})
 		return
 	}
 
-	var candidates []bson.M
-	if err = cursor.All(ctx, &candidates); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode candidates"})
-		return
-	}
-
-
-	mockCandidates := []gin.H{
-		{
-			"studentName": "Priya Sharma",
-			"department":  "CS",
-			"gpa":         9.2,
-			"keySkills":   "Python, ML, AWS",
-			"resumeUrl":   "/api/v1/resumes/download/resume1.pdf",
-		},
-		{
-			"studentName": "Rahul Verma",
-			"department":  "IT",
-			"gpa":         8.8,
-			"keySkills":   "Java, SQL, JS",
-			"resumeUrl":   "/api/v1/resumes/download/resume2.pdf",
-		},
-		{
-			"studentName": "Anjali Singh",
-			"department":  "CS",
-			"gpa":         8.5,
-			"keySkills":   "C++, DS, Algo",
-			"resumeUrl":   "/api/v1/resumes/download/resume3.pdf",
-		},
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"candidates":       mockCandidates,
-		"actualData":       candidates,
-		"total":            len(mockCandidates),
-		"jobPosition":      "Software Engineer",
-		"totalShortlisted": 15,
-	})
+	c.JSON(http.StatusOK, page)
 }
 
 
@@ -1920,6 +1997,12 @@ func (dc *DashboardController) DownloadAllResumes(c *gin.Context) {
 		return
 	}
 
+	jobID, err := primitive.ObjectIDFromHex(c.Query("jobId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A valid jobId query parameter is required"})
+		return
+	}
+
 	var recruiter models.User
 	err = dc.UserCollection.FindOne(ctx, bson.M{"_id": recruiterID}).Decode(&recruiter)
 	if err != nil {
@@ -1928,25 +2011,118 @@ func (dc *DashboardController) DownloadAllResumes(c *gin.Context) {
 	}
 
 	if recruiter.CompanyID == nil {
-		c.JSON(http.StatusOK, gin.H{
-			"message":        "No company associated. Cannot generate resume zip file.",
-			"downloadUrl":    "",
-			"fileSize":       "0 MB",
-			"candidateCount": 0,
-			"note":           "Please contact admin to associate your account with a company.",
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No company associated. Please contact admin."})
+		return
+	}
+
+	selection, err := dc.resumeExportService.ExportCandidates(*recruiter.CompanyID, jobID, queryList(c, "status"))
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job drive not found or not accessible"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch candidates"})
+		return
+	}
+	if len(selection.Candidates) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No candidates match the selected filters"})
+		return
+	}
+
+	if len(selection.Candidates) > services.ResumeExportSyncLimit || c.Query("async") == "true" {
+		export, err := dc.resumeExportService.QueueResumeExport(recruiterID, selection)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start resume export"})
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":     "Resume zip file generation initiated",
+			"export":      export,
+			"statusUrl":   "/api/v1/rec/resumes/exports/" + export.ID.Hex(),
+			"downloadUrl": "/api/v1/rec/resumes/exports/" + export.ID.Hex() + "/download",
+			"expiresAt":   export.ExpiresAt,
 		})
 		return
 	}
 
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": selection.ExportFileName()}))
+	c.Status(http.StatusOK)
+	if _, err := dc.resumeExportService.WriteResumeZip(c.Request.Context(), c.Writer, selection); err != nil {
+		log.Printf("Resume export for job %s aborted: %v", jobID.Hex(), err)
+	}
+	// Resumes already streamed may have reached the recruiter even if the
+	// archive was cut short, so they are logged either way.
+	if err := dc.resumeExportService.LogExportDownloads(recruiterID, selection.ExportedResumeIDs(), c.ClientIP(), c.Request.UserAgent()); err != nil {
+		log.Printf("Resume export for job %s: could not log downloads: %v", jobID.Hex(), err)
+	}
+}
 
 
+func (dc *DashboardController) GetResumeExport(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	recruiterID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":        "Resume zip file generation initiated",
-		"downloadUrl":    "/api/v1/recruiter/resumes/download/shortlisted_candidates.zip",
-		"fileSize":       "2.5 MB",
-		"candidateCount": 15,
-		"expiresAt":      time.Now().Add(24 * time.Hour),
+	exportID, err := primitive.ObjectIDFromHex(c.Param("exportId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export ID"})
+		return
+	}
+
+	export, err := dc.resumeExportService.GetResumeExport(recruiterID, exportID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch export"})
+		return
+	}
+
+	c.JSON(http.StatusOK, export)
+}
+
+
+func (dc *DashboardController) DownloadResumeExport(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	recruiterID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	exportID, err := primitive.ObjectIDFromHex(c.Param("exportId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export ID"})
+		return
+	}
+
+	export, file, err := dc.resumeExportService.OpenResumeExport(recruiterID, exportID)
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err == services.ErrExportExpired {
+			c.JSON(http.StatusGone, gin.H{"error": "The download link has expired, please export again"})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open export"})
+		return
+	}
+	defer file.Close()
+
+	if err := dc.resumeExportService.LogExportDownloads(recruiterID, export.ResumeIDs, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open export"})
+		return
+	}
+
+	c.DataFromReader(http.StatusOK, export.Size, "application/zip", file, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": export.FileName}),
 	})
 }
 
@@ -1963,6 +2139,11 @@ func (dc *DashboardController) GetCompanyJobDrives(c *gin.Context) {
 		return
 	}
 
+	q, ok := listQuery(c, services.DriveListSpec)
+	if !ok {
+		return
+	}
+
 	var recruiter models.User
 	err = dc.UserCollection.FindOne(ctx, bson.M{"_id": recruiterID}).Decode(&recruiter)
 	if err != nil {
@@ -1971,11 +2152,7 @@ func (dc *DashboardController) GetCompanyJobDrives(c *gin.Context) {
 	}
 
 	if recruiter.CompanyID == nil {
-		c.JSON(http.StatusOK, gin.H{
-			"jobDrives": []gin.H{},
-			"total":     0,
-			"message":   "No company associated. Please contact admin to associate your account with a company.",
-		})
+		c.JSON(http.StatusOK, services.ListPage{Items: []gin.H{}})
 		return
 	}
 
@@ -1995,6 +2172,7 @@ func (dc *DashboardController) GetCompanyJobDrives(c *gin.Context) {
 		{
 			"$addFields": bson.M{
 				"totalApplications": bson.M{"$size": "$applications"},
+				"applicantCount":    bson.M{"$size": "$applications"},
 				"shortlistedCount": bson.M{
 					"$size": bson.M{
 						"$filter": bson.M{
@@ -2013,27 +2191,15 @@ func (dc *DashboardController) GetCompanyJobDrives(c *gin.Context) {
 				},
 			},
 		},
-		{
-			"$sort": bson.M{"created_at": -1},
-		},
 	}
 
-	cursor, err := dc.JobCollection.Aggregate(ctx, pipeline)
+	page, _, err := services.AggregatePage[bson.M](ctx, dc.JobCollection, pipeline, nil, q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch company job drives"})
 		return
 	}
 
-	var jobDrives []bson.M
-	if err = cursor.All(ctx, &jobDrives); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode job drives"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"jobDrives": jobDrives,
-		"total":     len(jobDrives),
-	})
+	c.JSON(http.StatusOK, page)
 }
 
 
@@ -2041,16 +2207,14 @@ func (dc *DashboardController) SearchStudents(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
+	q, ok := listQuery(c, services.StudentListSpec)
+	if !ok {
+		return
+	}
+
 
 	searchQuery := c.Query("q")
 	department := c.Query("department")
-	limit := c.DefaultQuery("limit", "20")
-
-
-	limitInt := 20
-	if l, err := strconv.Atoi(limit); err == nil && l > 0 && l <= 100 {
-		limitInt = l
-	}
 
 
 	filter := bson.M{"role": "student"}
@@ -2073,67 +2237,27 @@ func (dc *DashboardController) SearchStudents(c *gin.Context) {
 	}
 
 
-	fmt.Printf("SearchStudents filter: %+v\n", filter)
-
-
-	totalStudents, err := dc.UserCollection.CountDocuments(ctx, bson.M{"role": "student"})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to count students",
-			"details": err.Error(),
-		})
-		return
-	}
-	fmt.Printf("Total students in database: %d\n", totalStudents)
-
-
-	findOptions := options.Find().
-		SetLimit(int64(limitInt)).
-		SetProjection(bson.M{
-			"_id":        1,
-			"firstName":  1,
-			"lastName":   1,
-			"email":      1,
-			"rollNumber": 1,
-			"department": 1,
-			"cgpa":       1,
-		}).
-		SetSort(bson.D{
-			{Key: "firstName", Value: 1},
-			{Key: "lastName", Value: 1},
-		})
+	projection := options.Find().SetProjection(bson.M{
+		"_id":        1,
+		"firstName":  1,
+		"lastName":   1,
+		"email":      1,
+		"rollNumber": 1,
+		"department": 1,
+		"cgpa":       1,
+		"createdAt":  1,
+	})
 
-	cursor, err := dc.UserCollection.Find(ctx, filter, findOptions)
+	page, _, err := services.FindPage[bson.M](ctx, dc.UserCollection, filter, q, projection)
 	if err != nil {
-
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to search students",
 			"details": err.Error(),
-			"filter":  filter,
 		})
 		return
 	}
 
-	var students []bson.M
-	if err = cursor.All(ctx, &students); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode student results"})
-		return
-	}
-
-
-	totalCount, err := dc.UserCollection.CountDocuments(ctx, filter)
-	if err != nil {
-		totalCount = int64(len(students))
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"students":    students,
-		"total":       totalCount,
-		"limit":       limitInt,
-		"hasMore":     totalCount > int64(len(students)),
-		"searchQuery": searchQuery,
-		"department":  department,
-	})
+	c.JSON(http.StatusOK, page)
 }
 
 
@@ -2187,6 +2311,17 @@ func (dc *DashboardController) GetJobDriveDetails(c *gin.Context) {
 		return
 	}
 
+	sortBy := c.DefaultQuery("sort", "-appliedOn")
+	sortStage, ok := driveCandidateSorts[sortBy]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort, use one of: appliedOn, -appliedOn, matchScore, -matchScore"})
+		return
+	}
+
+	if _, err := dc.matchService.ScoreUnscoredApplications([]primitive.ObjectID{jobDriveObjectID}); err != nil {
+		log.Printf("Could not score candidates of job drive %s: %v", jobDriveObjectID.Hex(), err)
+	}
+
 
 	pipeline := []bson.M{
 		{
@@ -2225,24 +2360,26 @@ func (dc *DashboardController) GetJobDriveDetails(c *gin.Context) {
 				"remarks":    1,
 				"student": bson.M{
 					"_id":             "$student._id",
-					"firstName":       "$student.firstName",
-					"lastName":        "$student.lastName",
-					"email":           "$student.email",
-					"rollNumber":      "$student.rollNumber",
-					"department":      "$student.department",
-					"cgpa":            "$student.cgpa",
-					"skills":          "$student.skills",
+					"firstName":       submitted("first_name", "firstName"),
+					"lastName":        submitted("last_name", "lastName"),
+					"email":           submitted("email", "email"),
+					"rollNumber":      submitted("roll_number", "rollNumber"),
+					"department":      submitted("department", "department"),
+					"cgpa":            submitted("cgpa", "cgpa"),
+					"skills":          submitted("skills", "skills"),
 					"placementStatus": "$student.placedStatus",
-					"gender":          "$student.gender",
+					"gender":          submitted("gender", "gender"),
 				},
 				"resume": bson.M{
 					"_id":      "$resume._id",
-					"file_url": "$resume.file_url",
+					"link_url": resumeLinkURL,
 				},
+				"match":               1,
+				"changedSinceApplied": changedSinceApplied,
 			},
 		},
 		{
-			"$sort": bson.M{"applied_on": -1},
+			"$sort": sortStage,
 		},
 	}
 
@@ -2411,8 +2548,8 @@ func (dc *DashboardController) GetStudentDetailsForJobDrive(c *gin.Context) {
 				},
 				"resume": bson.M{
 					"_id":        "$resume._id",
-					"file_url":   "$resume.file_url",
-					"parsedData": "$resume.parsedData",
+					"link_url":   resumeLinkURL,
+					"parsedData": "$resume.parsed_data",
 				},
 			},
 		},
@@ -2437,9 +2574,35 @@ func (dc *DashboardController) GetStudentDetailsForJobDrive(c *gin.Context) {
 
 	studentData := results[0]
 
-
-
-
+	// student and resume above are live. The snapshot is what was submitted;
+	// applications sent before snapshots existed have none.
+	var application models.Application
+	if err := dc.ApplicationCollection.FindOne(ctx, bson.M{"job_id": jobDriveObjectID, "student_id": studentObjectID}).Decode(&application); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch student details"})
+		return
+	}
+	studentData["snapshot"] = application.Snapshot
+	studentData["changesSinceApplied"] = []services.SnapshotChange{}
+	if application.Snapshot != nil {
+		var student models.User
+		if err := dc.UserCollection.FindOne(ctx, bson.M{"_id": studentObjectID}).Decode(&student); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch student details"})
+			return
+		}
+		var resume *models.Resume
+		if application.Snapshot.Resume != nil {
+			var current models.Resume
+			err := dc.ResumeCollection.FindOne(ctx, bson.M{"_id": application.Snapshot.Resume.ID}, options.FindOne().SetProjection(bson.M{"ocr_text": 0})).Decode(&current)
+			if err != nil && err != mongo.ErrNoDocuments {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch student details"})
+				return
+			}
+			if err == nil {
+				resume = &current
+			}
+		}
+		studentData["changesSinceApplied"] = services.SnapshotChanges(application.Snapshot, &student, resume)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"jobDrive": gin.H{
@@ -2493,7 +2656,7 @@ func (dc *DashboardController) UpdateStudentPlacementStatus(c *gin.Context) {
 	}
 
 
-	validStatuses := []string{"applied", "shortlisted", "selected", "rejected", "interviewed"}
+	validStatuses := []string{"applied", "shortlisted", "selected", "rejected", "interviewed", "waitlisted"}
 	isValidStatus := false
 	for _, status := range validStatuses {
 		if req.Status == status {
@@ -2546,22 +2709,49 @@ func (dc *DashboardController) UpdateStudentPlacementStatus(c *gin.Context) {
 	}
 
 
-	update := bson.M{
-		"$set": bson.M{
-			"status":     req.Status,
-			"updated_on": time.Now(),
-			"remarks":    req.Remarks,
-		},
+	if req.Status == "waitlisted" {
+		if _, err := dc.waitlistService.AddToWaitlist(jobObjectID, studentObjectID, req.Remarks); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update application status"})
+			return
+		}
+	} else {
+		update := bson.M{
+			"$set": bson.M{
+				"status":     req.Status,
+				"updated_on": time.Now(),
+				"remarks":    req.Remarks,
+			},
+			"$unset": bson.M{"waitlist_rank": ""},
+		}
+
+		result, err := dc.ApplicationCollection.UpdateByID(ctx, application.ID, update)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update application status"})
+			return
+		}
+
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+			return
+		}
 	}
 
-	result, err := dc.ApplicationCollection.UpdateByID(ctx, application.ID, update)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update application status"})
-		return
+	// The status change is saved; waitlist upkeep failing is logged rather
+	// than reported as a failed update.
+	if application.Status == "waitlisted" && req.Status != "waitlisted" {
+		if err := dc.waitlistService.CompactRanks(jobObjectID); err != nil {
+			log.Printf("Drive %s: could not compact waitlist ranks: %v", jobObjectID.Hex(), err)
+		}
+	}
+	if application.Status == "selected" && req.Status != "selected" {
+		if _, err := dc.waitlistService.HandleVacancy(jobObjectID); err != nil {
+			log.Printf("Drive %s: could not fill the vacancy: %v", jobObjectID.Hex(), err)
+		}
 	}
 
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+	placement, err := dc.placementService.SyncStudentPlacement(studentObjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Application updated but failed to sync placement status", "details": err.Error()})
 		return
 	}
 
@@ -2594,10 +2784,11 @@ func (dc *DashboardController) UpdateStudentPlacementStatus(c *gin.Context) {
 		},
 		{
 			"$project": bson.M{
-				"_id":        1,
-				"status":     1,
-				"updated_on": 1,
-				"remarks":    1,
+				"_id":           1,
+				"status":        1,
+				"updated_on":    1,
+				"remarks":       1,
+				"waitlist_rank": 1,
 				"student": bson.M{
 					"_id":       "$student._id",
 					"firstName": "$student.firstName",
@@ -2633,6 +2824,7 @@ func (dc *DashboardController) UpdateStudentPlacementStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message":     "Application status updated successfully",
 		"application": updatedApplication,
+		"placement":   placement,
 		"updatedBy": gin.H{
 			"recruiterId":   recruiter.ID,
 			"recruiterName": recruiter.FirstName + " " + recruiter.LastName,
@@ -2715,7 +2907,7 @@ func (dc *DashboardController) UpdateStudentApplicationStatus(c *gin.Context) {
 	}
 
 
-	validStatuses := []string{"applied", "shortlisted", "selected", "rejected", "interviewed"}
+	validStatuses := []string{"applied", "shortlisted", "selected", "rejected", "interviewed", "waitlisted"}
 	for _, update := range req.Updates {
 		isValidStatus := false
 		for _, status := range validStatuses {
@@ -2764,6 +2956,8 @@ func (dc *DashboardController) UpdateStudentApplicationStatus(c *gin.Context) {
 
 
 	statusGroups := make(map[string][]primitive.ObjectID)
+	leftWaitlist := false
+	vacancies := 0
 
 	for _, update := range req.Updates {
 		studentObjectID, err := primitive.ObjectIDFromHex(update.StudentID)
@@ -2783,15 +2977,31 @@ func (dc *DashboardController) UpdateStudentApplicationStatus(c *gin.Context) {
 			"student_id": studentObjectID,
 		}
 
-		updateDoc := bson.M{
-			"$set": bson.M{
-				"status":     update.Status,
-				"updated_on": time.Now(),
-			},
+		var previous models.Application
+		if err := dc.ApplicationCollection.FindOne(ctx, filter).Decode(&previous); err != nil {
+			failedCount++
+			updateResults = append(updateResults, gin.H{
+				"studentId": update.StudentID,
+				"status":    "failed",
+				"error":     "Application not found or update failed",
+			})
+			continue
 		}
 
-		result, err := dc.ApplicationCollection.UpdateOne(ctx, filter, updateDoc)
-		if err != nil || result.MatchedCount == 0 {
+		rank := 0
+		if update.Status == "waitlisted" {
+			rank, err = dc.waitlistService.AddToWaitlist(jobObjectID, studentObjectID, update.Remarks)
+		} else {
+			updateDoc := bson.M{
+				"$set": bson.M{
+					"status":     update.Status,
+					"updated_on": time.Now(),
+				},
+				"$unset": bson.M{"waitlist_rank": ""},
+			}
+			_, err = dc.ApplicationCollection.UpdateOne(ctx, filter, updateDoc)
+		}
+		if err != nil {
 			failedCount++
 			updateResults = append(updateResults, gin.H{
 				"studentId": update.StudentID,
@@ -2801,12 +3011,28 @@ func (dc *DashboardController) UpdateStudentApplicationStatus(c *gin.Context) {
 			continue
 		}
 
+		if previous.Status == "waitlisted" && update.Status != "waitlisted" {
+			leftWaitlist = true
+		}
+		if previous.Status == "selected" && update.Status != "selected" {
+			vacancies++
+		}
+
 		successCount++
-		updateResults = append(updateResults, gin.H{
+		entry := gin.H{
 			"studentId": update.StudentID,
 			"status":    "success",
 			"newStatus": update.Status,
-		})
+		}
+		if rank > 0 {
+			entry["waitlistRank"] = rank
+		}
+		if placement, err := dc.placementService.SyncStudentPlacement(studentObjectID); err != nil {
+			entry["placementError"] = err.Error()
+		} else {
+			entry["placedStatus"] = placement.Status
+		}
+		updateResults = append(updateResults, entry)
 
 
 		if statusGroups[update.Status] == nil {
@@ -2816,6 +3042,25 @@ func (dc *DashboardController) UpdateStudentApplicationStatus(c *gin.Context) {
 	}
 
 
+	if leftWaitlist {
+		if err := dc.waitlistService.CompactRanks(jobObjectID); err != nil {
+			log.Printf("Drive %s: could not compact waitlist ranks: %v", jobObjectID.Hex(), err)
+		}
+	}
+	promotedCount := 0
+	for i := 0; i < vacancies; i++ {
+		promoted, err := dc.waitlistService.HandleVacancy(jobObjectID)
+		if err != nil {
+			log.Printf("Drive %s: could not fill a vacancy: %v", jobObjectID.Hex(), err)
+			break
+		}
+		if promoted == nil {
+			break
+		}
+		promotedCount++
+	}
+
+
 	notificationsSent := 0
 	companyName := job.CompanyName.Name
 	position := job.Position
@@ -2849,6 +3094,14 @@ func (dc *DashboardController) UpdateStudentApplicationStatus(c *gin.Context) {
 				IsRead:    false,
 				CreatedAt: time.Now(),
 			}
+		case "waitlisted":
+			notification = models.Notification{
+				ID:        primitive.NewObjectID(),
+				Subject:   "You've been waitlisted",
+				Message:   fmt.Sprintf("Your application for the position of %s at %s has been placed on the waitlist. If a selected candidate drops out, you may be moved up and selected. We will notify you of any change.", position, companyName),
+				IsRead:    false,
+				CreatedAt: time.Now(),
+			}
 		case "interviewed":
 			notification = models.Notification{
 				ID:        primitive.NewObjectID(),
@@ -2881,12 +3134,13 @@ func (dc *DashboardController) UpdateStudentApplicationStatus(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":           "Bulk status update completed",
-		"totalUpdates":      len(req.Updates),
-		"successCount":      successCount,
-		"failedCount":       failedCount,
-		"notificationsSent": notificationsSent,
-		"results":           updateResults,
+		"message":              "Bulk status update completed",
+		"totalUpdates":         len(req.Updates),
+		"successCount":         successCount,
+		"failedCount":          failedCount,
+		"notificationsSent":    notificationsSent,
+		"promotedFromWaitlist": promotedCount,
+		"results":              updateResults,
 		"job": gin.H{
 			"id":       job.ID,
 			"position": job.Position,
@@ -2918,36 +3172,29 @@ func (dc *DashboardController) GetRecruiterNotifications(c *gin.Context) {
 	}
 
 
-	var recruiter models.User
-	err = dc.UserCollection.FindOne(ctx, bson.M{
-		"_id":  recruiterID,
-		"role": "rec",
-	}).Decode(&recruiter)
+	q, ok := listQuery(c, services.NotificationListSpec)
+	if !ok {
+		return
+	}
 
+	owner := bson.M{"_id": recruiterID, "role": "rec"}
+	count, err := dc.UserCollection.CountDocuments(ctx, owner)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Recruiter not found"})
-			return
-		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
 		return
 	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recruiter not found"})
+		return
+	}
 
-
-	notifications := recruiter.Notifications
-	if notifications == nil {
-		notifications = []models.Notification{}
+	page, err := services.ListNotifications(ctx, dc.UserCollection, owner, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"notifications": notifications,
-		"count":         len(notifications),
-		"recruiter": gin.H{
-			"id":      recruiter.ID,
-			"name":    recruiter.FirstName + " " + recruiter.LastName,
-			"company": recruiter.CompanyID,
-		},
-	})
+	c.JSON(http.StatusOK, page)
 }
 
 
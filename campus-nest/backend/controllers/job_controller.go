@@ -4,14 +4,17 @@ import (
 	"backend/models"
 	"backend/services"
 	"context"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 
@@ -19,11 +22,18 @@ type JobController struct {
 	JobCollection         *mongo.Collection
 	UserCollection        *mongo.Collection
 	ApplicationCollection *mongo.Collection
+	ResumeCollection      *mongo.Collection
 
 
 	jobService     services.JobService
 	userService    services.UserService
 	studentService services.StudentService
+	policyService  services.PolicyService
+
+	jobSearchService      services.JobSearchService
+	recommendationService services.RecommendationService
+	bookmarkService       services.BookmarkService
+	matchService          services.MatchService
 }
 
 
@@ -32,113 +42,190 @@ func NewJobController(db *mongo.Database) *JobController {
 		JobCollection:         db.Collection("jobs"),
 		UserCollection:        db.Collection("users"),
 		ApplicationCollection: db.Collection("applications"),
+		ResumeCollection:      db.Collection("resumes"),
 
 
 		jobService:     services.NewJobService(db),
 		userService:    services.NewUserService(db),
 		studentService: services.NewStudentService(db),
+		policyService:  services.NewPolicyService(db),
+
+		jobSearchService:      services.NewJobSearchService(db),
+		recommendationService: services.NewRecommendationService(db),
+		bookmarkService:       services.NewBookmarkService(db),
+		matchService:          services.NewMatchService(db),
 	}
 }
 
 
 func (jc *JobController) GetAvailableJobs(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-
-
-	userIDHex, _ := c.Get("userID")
-	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
-
-
-	appliedJobsCursor, err := jc.ApplicationCollection.Find(ctx, bson.M{"student_id": studentID})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applied jobs"})
-		return
-	}
-	var appliedJobs []struct {
-		JobID primitive.ObjectID `bson:"job_id"`
-	}
-	if err = appliedJobsCursor.All(ctx, &appliedJobs); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode applied jobs"})
+	result, ok := jc.searchJobs(c)
+	if !ok {
 		return
 	}
 
-
-	appliedJobIDs := make([]primitive.ObjectID, len(appliedJobs))
-	for i, app := range appliedJobs {
-		appliedJobIDs[i] = app.JobID
+	jobsWithStatus := []gin.H{}
+	for _, hit := range result.Jobs {
+		jobsWithStatus = append(jobsWithStatus, gin.H{
+			"job":         hit.Job,
+			"has_applied": hit.HasApplied,
+		})
 	}
 
 
-	filter := bson.M{"status": "open"}
+	c.JSON(http.StatusOK, services.ListPage{
+		Items:      jobsWithStatus,
+		NextCursor: result.NextCursor,
+		Total:      result.Total,
+	})
+}
 
 
+func (jc *JobController) SearchJobs(c *gin.Context) {
+	result, ok := jc.searchJobs(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, services.ListPage{
+		Items:      result.Jobs,
+		NextCursor: result.NextCursor,
+		Total:      result.Total,
+		Summary:    gin.H{"facets": result.Facets},
+	})
+}
 
 
-	eligibleOnly, _ := strconv.ParseBool(c.Query("eligibleOnly"))
-	if eligibleOnly {
-		var user models.User
-		if err := jc.UserCollection.FindOne(ctx, bson.M{"_id": studentID}).Decode(&user); err == nil {
+func (jc *JobController) GetRecommendedJobs(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
 
-			if user.CGPA != nil {
-				filter["eligibility.min_cgpa"] = bson.M{"$lte": *user.CGPA}
-			}
-			if user.Department != nil {
-				filter["eligibility.course"] = bson.M{"$in": []string{*user.Department}}
-			}
+	limit := services.DefaultListLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Limit must be a number"})
+			return
+		}
+		limit = n
+	}
 
+	recommendations, total, err := jc.recommendationService.RecommendJobs(studentID, limit)
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recommended jobs"})
+		return
 	}
 
+	c.JSON(http.StatusOK, services.ListPage{
+		Items: recommendations,
+		Total: total,
+	})
+}
+
 
-	if industry := c.Query("industry"); industry != "" {
+// legacyJobSorts maps the sort names accepted before cursor pagination. They
+// all sort best match first.
+var legacyJobSorts = map[string]string{
+	"newest":    "-createdAt",
+	"relevance": "-relevance",
+	"package":   "-package",
+}
 
 
+func (jc *JobController) searchJobs(c *gin.Context) (*services.JobSearchResult, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
 
-	}
+
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
 
 
-	if location := c.Query("location"); location != "" {
-		filter["location"] = location
+	search := c.Query("q")
+	if search == "" {
+		search = c.Query("search")
 	}
 
+	sort := c.Query("sort")
+	if legacy, ok := legacyJobSorts[sort]; ok {
+		sort = legacy
+	}
+	if sort == "" && strings.TrimSpace(search) != "" {
+		sort = "-relevance"
+	}
+	q, err := services.NewListQuery(services.JobSearchListSpec, c.Query("limit"), c.Query("cursor"), sort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	params := &services.JobSearchParams{
+		Query:        search,
+		Industries:   queryList(c, "industry"),
+		Locations:    queryList(c, "location"),
+		JobTypes:     queryList(c, "jobType"),
+		PackageBands: queryList(c, "package"),
+		List:         q,
+	}
 
-	if search := c.Query("search"); search != "" {
 
-		filter["position"] = bson.M{"$regex": search, "$options": "i"}
+	eligibleOnly, _ := strconv.ParseBool(c.Query("eligibleOnly"))
+	if eligibleOnly {
+		var user models.User
+		if err := jc.UserCollection.FindOne(ctx, bson.M{"_id": studentID}).Decode(&user); err == nil {
+			params.EligibleFor = &user
+		}
 	}
 
 
-	cursor, err := jc.JobCollection.Find(ctx, filter)
+	result, err := jc.jobSearchService.SearchJobs(params)
 	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, false
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch available jobs"})
-		return
-	}
-
-	var jobs []models.Job
-	if err = cursor.All(ctx, &jobs); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode jobs"})
-		return
+		return nil, false
 	}
 
 
+	appliedJobIDs, err := jc.ApplicationCollection.Distinct(ctx, "job_id", bson.M{"student_id": studentID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applied jobs"})
+		return nil, false
+	}
 	appliedJobsMap := make(map[primitive.ObjectID]bool)
-	for _, jobID := range appliedJobIDs {
-		appliedJobsMap[jobID] = true
+	for _, id := range appliedJobIDs {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			appliedJobsMap[oid] = true
+		}
 	}
-
-
-	var jobsWithStatus []gin.H
-	for _, job := range jobs {
-		hasApplied := appliedJobsMap[job.ID]
-		jobsWithStatus = append(jobsWithStatus, gin.H{
-			"job":         job,
-			"has_applied": hasApplied,
-		})
+	for i := range result.Jobs {
+		result.Jobs[i].HasApplied = appliedJobsMap[result.Jobs[i].Job.ID]
 	}
 
+	return result, true
+}
+
 
-	c.JSON(http.StatusOK, jobsWithStatus)
+// queryList reads a multi-value filter given either as repeated parameters
+// or as a single comma-separated value.
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(key) {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
 }
 func (jc *JobController) GetJobById(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
@@ -164,7 +251,7 @@ func (jc *JobController) GetJobById(c *gin.Context) {
 	hasApplied := count > 0
 
 	var job models.Job
-	if err := jc.JobCollection.FindOne(ctx, bson.M{"_id": jobID}).Decode(&job); err != nil {
+	if err := jc.JobCollection.FindOne(ctx, bson.M{"_id": jobID, "status": bson.M{"$ne": models.JobStatusDraft}}).Decode(&job); err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 		return
 	}
@@ -205,6 +292,13 @@ func (jc *JobController) ApplyForJob(c *gin.Context) {
 	}
 
 
+	published, err := jc.JobCollection.CountDocuments(ctx, bson.M{"_id": jobID, "status": bson.M{"$ne": models.JobStatusDraft}})
+	if err != nil || published == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+
 	count, err := jc.ApplicationCollection.CountDocuments(ctx, bson.M{"student_id": studentID, "job_id": jobID})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing applications"})
@@ -236,6 +330,25 @@ func (jc *JobController) ApplyForJob(c *gin.Context) {
 	}
 
 
+	decision, err := jc.policyService.EvaluateApplication(studentID, jobID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to evaluate placement policy"})
+		return
+	}
+	if !decision.Allowed {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":      "This application is not allowed by the placement policy",
+			"violations": decision.Violations,
+			"policy":     decision,
+		})
+		return
+	}
+
+
 	newApplication := models.Application{
 		ID:        primitive.NewObjectID(),
 		JobID:     jobID,
@@ -245,6 +358,27 @@ func (jc *JobController) ApplyForJob(c *gin.Context) {
 		AppliedOn: time.Now(),
 	}
 
+	// Recruiters see the profile and resume as they were now, whatever the
+	// student edits later.
+	var resume models.Resume
+	err = jc.ResumeCollection.FindOne(ctx, bson.M{"_id": resumeID}, options.FindOne().SetProjection(bson.M{"ocr_text": 0})).Decode(&resume)
+	switch err {
+	case nil:
+		newApplication.Snapshot = services.NewApplicationSnapshot(&user, &resume)
+	case mongo.ErrNoDocuments:
+		newApplication.Snapshot = services.NewApplicationSnapshot(&user, nil)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read the resume"})
+		return
+	}
+
+	// A missing score is filled in later when recruiters list the drive, so
+	// scoring problems do not block the application.
+	match, err := jc.matchService.ScoreApplication(studentID, jobID, resumeID)
+	if err != nil {
+		log.Printf("Could not score application of %s to %s: %v", studentID.Hex(), jobID.Hex(), err)
+	}
+	newApplication.Match = match
 
 	_, err = jc.ApplicationCollection.InsertOne(ctx, newApplication)
 	if err != nil {
@@ -254,3 +388,107 @@ func (jc *JobController) ApplyForJob(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Application submitted successfully"})
 }
+
+func (jc *JobController) BookmarkJob(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	jobID, err := primitive.ObjectIDFromHex(c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID format"})
+		return
+	}
+
+	var req struct {
+		RemindBeforeHours int `json:"remind_before_hours"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+			return
+		}
+	}
+
+	bookmark, created, err := jc.bookmarkService.BookmarkJob(studentID, jobID, req.RemindBeforeHours)
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to bookmark job"})
+		return
+	}
+
+	status, message := http.StatusOK, "Bookmark updated"
+	if created {
+		status, message = http.StatusCreated, "Job bookmarked"
+	}
+	c.JSON(status, gin.H{"message": message, "bookmark": bookmark})
+}
+
+func (jc *JobController) RemoveBookmark(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	jobID, err := primitive.ObjectIDFromHex(c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID format"})
+		return
+	}
+
+	if err := jc.bookmarkService.RemoveBookmark(studentID, jobID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Bookmark not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove bookmark"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bookmark removed"})
+}
+
+func (jc *JobController) GetBookmarks(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	q, ok := listQuery(c, services.BookmarkListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := jc.bookmarkService.ListBookmarks(studentID, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bookmarks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (jc *JobController) CheckApplicationPolicy(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	jobID, err := primitive.ObjectIDFromHex(c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID format"})
+		return
+	}
+
+	decision, err := jc.policyService.EvaluateApplication(studentID, jobID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to evaluate placement policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
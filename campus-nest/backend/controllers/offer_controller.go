@@ -0,0 +1,374 @@
+﻿package controllers
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type OfferController struct {
+	UserCollection *mongo.Collection
+
+	offerService services.OfferService
+	ppoService   services.PPOService
+}
+
+func NewOfferController(db *mongo.Database) *OfferController {
+	return &OfferController{
+		UserCollection: db.Collection("users"),
+
+		offerService: services.NewOfferService(db),
+		ppoService:   services.NewPPOService(db),
+	}
+}
+
+func (oc *OfferController) recruiterCompany(c *gin.Context) (*models.User, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userIDHex, _ := c.Get("userID")
+	recruiterID, err := primitive.ObjectIDFromHex(userIDHex.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recruiter ID"})
+		return nil, false
+	}
+
+	var recruiter models.User
+	if err := oc.UserCollection.FindOne(ctx, bson.M{"_id": recruiterID}).Decode(&recruiter); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recruiter not found"})
+		return nil, false
+	}
+	if recruiter.CompanyID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No company associated. Please contact admin."})
+		return nil, false
+	}
+	return &recruiter, true
+}
+
+func (oc *OfferController) CreateOffer(c *gin.Context) {
+	recruiter, ok := oc.recruiterCompany(c)
+	if !ok {
+		return
+	}
+
+	jobID, err := primitive.ObjectIDFromHex(c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+	studentID, err := primitive.ObjectIDFromHex(c.Param("studentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	var req struct {
+		CTC              float64   `json:"ctc" binding:"required"`
+		JoiningDate      time.Time `json:"joiningDate" binding:"required"`
+		Location         string    `json:"location"`
+		OfferLetterURL   string    `json:"offerLetterUrl"`
+		ResponseDeadline time.Time `json:"responseDeadline" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	offer, err := oc.offerService.CreateOffer(&models.Offer{
+		JobID:            jobID,
+		StudentID:        studentID,
+		CompanyID:        *recruiter.CompanyID,
+		CTC:              req.CTC,
+		JoiningDate:      req.JoiningDate,
+		Location:         req.Location,
+		OfferLetterURL:   req.OfferLetterURL,
+		ResponseDeadline: req.ResponseDeadline,
+		IssuedBy:         recruiter.ID,
+	})
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job or application not found or not accessible"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create offer", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Offer created successfully",
+		"offer":   offer,
+	})
+}
+
+func (oc *OfferController) RecordPPOOutcome(c *gin.Context) {
+	recruiter, ok := oc.recruiterCompany(c)
+	if !ok {
+		return
+	}
+
+	jobID, err := primitive.ObjectIDFromHex(c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+	studentID, err := primitive.ObjectIDFromHex(c.Param("studentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	var req struct {
+		Status  string `json:"status" binding:"required"`
+		Remarks string `json:"remarks"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	application, err := oc.ppoService.RecordPPOOutcome(*recruiter.CompanyID, jobID, studentID,
+		strings.ToLower(strings.TrimSpace(req.Status)), req.Remarks, recruiter.ID)
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job or application not found or not accessible"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record PPO outcome"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "PPO outcome recorded",
+		"ppo":     application.PPO,
+	})
+}
+
+func (oc *OfferController) GetPPOAnalytics(c *gin.Context) {
+	var companyID *primitive.ObjectID
+	if v := c.Query("companyId"); v != "" {
+		oid, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid company ID"})
+			return
+		}
+		companyID = &oid
+	}
+
+	stats, err := oc.ppoService.GetPPOStats(companyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch PPO analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (oc *OfferController) GetCompanyOffers(c *gin.Context) {
+	recruiter, ok := oc.recruiterCompany(c)
+	if !ok {
+		return
+	}
+
+	q, ok := listQuery(c, services.OfferListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := oc.offerService.GetCompanyOffers(*recruiter.CompanyID, c.Query("status"), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch offers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (oc *OfferController) GetMyOffers(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	q, ok := listQuery(c, services.OfferListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := oc.offerService.GetStudentOffers(studentID, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch offers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (oc *OfferController) GetMyOffer(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	offerID, err := primitive.ObjectIDFromHex(c.Param("offerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	offer, err := oc.offerService.GetOfferByID(offerID)
+	if err != nil || offer.StudentID != studentID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offer not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"offer": offer})
+}
+
+func (oc *OfferController) AcceptOffer(c *gin.Context) {
+	oc.respond(c, true)
+}
+
+func (oc *OfferController) DeclineOffer(c *gin.Context) {
+	oc.respond(c, false)
+}
+
+func (oc *OfferController) respond(c *gin.Context, accept bool) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	offerID, err := primitive.ObjectIDFromHex(c.Param("offerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	offer, err := oc.offerService.RespondToOffer(studentID, offerID, accept, req.Reason)
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Offer not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record your response", "details": err.Error()})
+		return
+	}
+
+	message := "Offer declined"
+	if accept {
+		message = "Offer accepted. Congratulations!"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"offer":   offer,
+	})
+}
+
+// UploadOfferLetter attaches the offer letter as a file, sent as the 'file'
+// form field.
+func (oc *OfferController) UploadOfferLetter(c *gin.Context) {
+	recruiter, ok := oc.recruiterCompany(c)
+	if !ok {
+		return
+	}
+
+	offerID, err := primitive.ObjectIDFromHex(c.Param("offerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxAttachmentSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attach the offer letter as the 'file' form field, at most " + strconv.Itoa(services.MaxAttachmentSize>>20) + " MB"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	offer, err := oc.offerService.AttachOfferLetter(offerID, *recruiter.CompanyID, header.Filename, file, header.Size, recruiter.ID)
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Offer not found or not accessible"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store the offer letter"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Offer letter uploaded successfully",
+		"offer":   offer,
+	})
+}
+
+// DownloadOfferLetter serves an uploaded offer letter to the student it was
+// made to or to the company's recruiters.
+func (oc *OfferController) DownloadOfferLetter(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	offerID, err := primitive.ObjectIDFromHex(c.Param("offerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	userIDHex, _ := c.Get("userID")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	var viewer models.User
+	if err := oc.UserCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&viewer); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	letter, file, err := oc.offerService.OpenOfferLetter(offerID, &viewer)
+	if err != nil {
+		if err == services.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to download this offer letter"})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Offer letter not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open the offer letter"})
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, letter.Size, letter.ContentType, file, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": letter.FileName}),
+	})
+}
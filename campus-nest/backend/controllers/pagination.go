@@ -0,0 +1,20 @@
+﻿package controllers
+
+import (
+	"net/http"
+
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listQuery parses ?limit=&cursor=&sort= against spec and answers 400 when
+// they are not acceptable.
+func listQuery(c *gin.Context, spec services.ListSpec) (*services.ListQuery, bool) {
+	q, err := services.NewListQuery(spec, c.Query("limit"), c.Query("cursor"), c.Query("sort"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return q, true
+}
@@ -0,0 +1,162 @@
+﻿package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"backend/models"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type PolicyController struct {
+	policyService services.PolicyService
+}
+
+func NewPolicyController(db *mongo.Database) *PolicyController {
+	return &PolicyController{
+		policyService: services.NewPolicyService(db),
+	}
+}
+
+func (pc *PolicyController) GetPolicies(c *gin.Context) {
+	academicYear := c.Query("academicYear")
+
+	q, ok := listQuery(c, services.PolicyListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := pc.policyService.ListPolicies(academicYear, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch policies", "details": err.Error()})
+		return
+	}
+
+	page.Summary = gin.H{"currentAcademicYear": services.CurrentAcademicYear(time.Now())}
+	c.JSON(http.StatusOK, page)
+}
+
+func (pc *PolicyController) GetPolicy(c *gin.Context) {
+	policyID, err := primitive.ObjectIDFromHex(c.Param("policyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+
+	policy, err := pc.policyService.GetPolicyByID(policyID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Policy not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policy": policy})
+}
+
+func (pc *PolicyController) CreatePolicy(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	adminID, err := primitive.ObjectIDFromHex(userIDHex.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Admin ID"})
+		return
+	}
+
+	var policy models.PlacementPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+	if policy.AcademicYear == "" {
+		policy.AcademicYear = services.CurrentAcademicYear(time.Now())
+	}
+	policy.CreatedBy = adminID
+
+	policyID, err := pc.policyService.CreatePolicy(&policy)
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create policy", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Policy created successfully",
+		"policyId": policyID,
+	})
+}
+
+func (pc *PolicyController) UpdatePolicy(c *gin.Context) {
+	policyID, err := primitive.ObjectIDFromHex(c.Param("policyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+
+	var policy models.PlacementPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	err = pc.policyService.UpdatePolicy(policyID, &policy)
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Policy not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update policy", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Policy updated successfully"})
+}
+
+func (pc *PolicyController) DeletePolicy(c *gin.Context) {
+	policyID, err := primitive.ObjectIDFromHex(c.Param("policyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+
+	if err := pc.policyService.DeletePolicy(policyID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Policy not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete policy", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Policy deleted successfully"})
+}
+
+func (pc *PolicyController) ActivatePolicy(c *gin.Context) {
+	policyID, err := primitive.ObjectIDFromHex(c.Param("policyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+
+	if err := pc.policyService.ActivatePolicy(policyID); err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Policy not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to activate policy", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Policy activated successfully"})
+}
@@ -0,0 +1,379 @@
+﻿package controllers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"backend/models"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// resumeLinkURL projects, for the joined $resume, the endpoint that issues a
+// signed download link. Views of other students' applications use it instead
+// of exposing the stored file URL.
+var resumeLinkURL = bson.M{"$concat": bson.A{"/api/v1/resumes/", bson.M{"$toString": "$resume._id"}, "/link"}}
+
+type ResumeController struct {
+	resumeService       services.ResumeService
+	resumeParseService  services.ResumeParseService
+	resumeAccessService services.ResumeAccessService
+	resumeSearchService services.ResumeSearchService
+}
+
+func NewResumeController(db *mongo.Database) *ResumeController {
+	return &ResumeController{
+		resumeService:       services.NewResumeService(db),
+		resumeParseService:  services.NewResumeParseService(db),
+		resumeAccessService: services.NewResumeAccessService(db),
+		resumeSearchService: services.NewResumeSearchService(db),
+	}
+}
+
+func resumeParams(c *gin.Context) (primitive.ObjectID, primitive.ObjectID, bool) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	resumeID, err := primitive.ObjectIDFromHex(c.Param("resumeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resume ID"})
+		return studentID, resumeID, false
+	}
+	return studentID, resumeID, true
+}
+
+func resumeError(c *gin.Context, err error, action string) {
+	if services.IsValidationError(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Resume not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action})
+}
+
+func (rc *ResumeController) UploadResume(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxResumeSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attach the resume as the 'file' form field, at most " + strconv.Itoa(services.MaxResumeSize>>20) + " MB"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	resume, err := rc.resumeService.UploadResume(studentID, header.Filename, c.PostForm("resume_name"), file, header.Size)
+	if err != nil {
+		resumeError(c, err, "upload resume")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Resume uploaded successfully",
+		"resume":  resume,
+	})
+}
+
+// GenerateResume builds a PDF resume from the student's profile.
+func (rc *ResumeController) GenerateResume(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	var req struct {
+		Template   string `json:"template"`
+		ResumeName string `json:"resume_name"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	resume, err := rc.resumeService.GenerateResume(studentID, req.Template, req.ResumeName)
+	if err != nil {
+		resumeError(c, err, "generate resume")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Resume generated successfully",
+		"resume":  resume,
+	})
+}
+
+func (rc *ResumeController) GetResumeTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"templates": services.ResumeTemplates})
+}
+
+func (rc *ResumeController) GetMyResumes(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	resumes, err := rc.resumeService.ListResumes(studentID)
+	if err != nil {
+		resumeError(c, err, "fetch resumes")
+		return
+	}
+
+	c.JSON(http.StatusOK, services.ListPage{
+		Items: resumes,
+		Total: int64(len(resumes)),
+	})
+}
+
+func (rc *ResumeController) RenameResume(c *gin.Context) {
+	studentID, resumeID, ok := resumeParams(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ResumeName string `json:"resume_name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "resume_name is required in request body"})
+		return
+	}
+
+	resume, err := rc.resumeService.RenameResume(studentID, resumeID, req.ResumeName)
+	if err != nil {
+		resumeError(c, err, "rename resume")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Resume renamed successfully",
+		"resume":  resume,
+	})
+}
+
+func (rc *ResumeController) DeleteResume(c *gin.Context) {
+	studentID, resumeID, ok := resumeParams(c)
+	if !ok {
+		return
+	}
+
+	if err := rc.resumeService.DeleteResume(studentID, resumeID); err != nil {
+		resumeError(c, err, "delete resume")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Resume deleted successfully"})
+}
+
+func (rc *ResumeController) SetPrimaryResume(c *gin.Context) {
+	studentID, resumeID, ok := resumeParams(c)
+	if !ok {
+		return
+	}
+
+	if err := rc.resumeService.SetPrimaryResume(studentID, resumeID); err != nil {
+		resumeError(c, err, "set primary resume")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Primary resume updated"})
+}
+
+func (rc *ResumeController) DownloadResume(c *gin.Context) {
+	studentID, resumeID, ok := resumeParams(c)
+	if !ok {
+		return
+	}
+
+	resume, file, err := rc.resumeService.OpenResume(studentID, resumeID)
+	if err != nil {
+		resumeError(c, err, "open resume")
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, resume.Size, resume.ContentType, file, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("inline", map[string]string{"filename": resume.ResumeName + extensionFor(resume.ContentType)}),
+	})
+}
+
+func (rc *ResumeController) GetParseStatus(c *gin.Context) {
+	studentID, resumeID, ok := resumeParams(c)
+	if !ok {
+		return
+	}
+
+	status, err := rc.resumeParseService.GetParseStatus(studentID, resumeID)
+	if err != nil {
+		resumeError(c, err, "fetch parse status")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (rc *ResumeController) RetryParse(c *gin.Context) {
+	studentID, resumeID, ok := resumeParams(c)
+	if !ok {
+		return
+	}
+
+	if err := rc.resumeParseService.RetryParse(studentID, resumeID); err != nil {
+		resumeError(c, err, "queue resume for parsing")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Resume queued for parsing",
+		"status":  models.ResumeParsePending,
+	})
+}
+
+// GetResumeLink returns a short-lived download link for any resume the
+// caller may see, whatever their role.
+func (rc *ResumeController) GetResumeLink(c *gin.Context) {
+	viewerID, resumeID, ok := resumeParams(c)
+	if !ok {
+		return
+	}
+
+	link, err := rc.resumeAccessService.CreateResumeLink(resumeID, viewerID)
+	if err != nil {
+		if err == services.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to view this resume"})
+			return
+		}
+		resumeError(c, err, "create download link")
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// DownloadSignedResume serves a link from GetResumeLink. The signature
+// authenticates the request, so the route sits outside the auth middleware.
+func (rc *ResumeController) DownloadSignedResume(c *gin.Context) {
+	resumeID, err := primitive.ObjectIDFromHex(c.Param("resumeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resume ID"})
+		return
+	}
+
+	file, err := rc.resumeAccessService.OpenSignedResume(resumeID, services.SignedResumeLink{
+		ViewerID:  c.Query("viewer"),
+		Expires:   c.Query("expires"),
+		Signature: c.Query("sig"),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		switch err {
+		case services.ErrInvalidLink:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid download link"})
+		case services.ErrLinkExpired:
+			c.JSON(http.StatusGone, gin.H{"error": "The download link has expired, request a new one"})
+		case services.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to view this resume"})
+		default:
+			resumeError(c, err, "open resume")
+		}
+		return
+	}
+	defer file.Body.Close()
+
+	size := file.Size
+	if size <= 0 {
+		size = -1
+	}
+	c.DataFromReader(http.StatusOK, size, file.ContentType, file.Body, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": file.FileName}),
+		"Cache-Control":       "private, no-store",
+	})
+}
+
+func (rc *ResumeController) GetResumeDownloads(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	q, ok := listQuery(c, services.ResumeDownloadListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := rc.resumeAccessService.ListResumeDownloads(studentID, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch resume downloads"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func extensionFor(contentType string) string {
+	if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// SearchResumes serves ?q= boolean keyword searches over resume contents,
+// filtered by ?department=, ?batch= and ?minCgpa=/?maxCgpa=.
+func (rc *ResumeController) SearchResumes(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	viewerID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	q, ok := listQuery(c, services.ResumeSearchListSpec)
+	if !ok {
+		return
+	}
+	params := &services.ResumeSearchParams{
+		Query:       c.Query("q"),
+		Departments: queryList(c, "department"),
+		List:        q,
+	}
+	for _, raw := range queryList(c, "batch") {
+		year, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Batch must be a graduation year"})
+			return
+		}
+		params.Batches = append(params.Batches, year)
+	}
+	for _, bound := range []struct {
+		key    string
+		target **float64
+	}{{"minCgpa", &params.MinCGPA}, {"maxCgpa", &params.MaxCGPA}} {
+		raw := c.Query(bound.key)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || value < 0 || value > 10 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": bound.key + " must be a number between 0 and 10"})
+			return
+		}
+		*bound.target = &value
+	}
+
+	page, err := rc.resumeSearchService.SearchResumes(viewerID, params)
+	if err != nil {
+		if err == services.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You cannot search resumes"})
+			return
+		}
+		resumeError(c, err, "search resumes")
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,164 @@
+﻿package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"backend/models"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SavedSearchController struct {
+	savedSearchService services.SavedSearchService
+}
+
+func NewSavedSearchController(db *mongo.Database) *SavedSearchController {
+	return &SavedSearchController{
+		savedSearchService: services.NewSavedSearchService(db),
+	}
+}
+
+type savedSearchRequest struct {
+	Name          string   `json:"name"`
+	Keywords      string   `json:"keywords"`
+	Locations     []string `json:"locations"`
+	MinPackageLPA float64  `json:"min_package_lpa"`
+	EligibleOnly  bool     `json:"eligible_only"`
+	AlertsEnabled *bool    `json:"alerts_enabled"`
+}
+
+func (r *savedSearchRequest) toModel(studentID primitive.ObjectID) *models.SavedSearch {
+	search := &models.SavedSearch{
+		StudentID:     studentID,
+		Name:          r.Name,
+		Keywords:      r.Keywords,
+		Locations:     r.Locations,
+		MinPackageLPA: r.MinPackageLPA,
+		EligibleOnly:  r.EligibleOnly,
+		AlertsEnabled: true,
+	}
+	if r.AlertsEnabled != nil {
+		search.AlertsEnabled = *r.AlertsEnabled
+	}
+	return search
+}
+
+func (sc *SavedSearchController) GetSavedSearches(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	q, ok := listQuery(c, services.SavedSearchListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := sc.savedSearchService.ListSavedSearches(studentID, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch saved searches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (sc *SavedSearchController) CreateSavedSearch(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	var req savedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	search := req.toModel(studentID)
+	if err := sc.savedSearchService.CreateSavedSearch(search); err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save search"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Search saved successfully",
+		"search":  search,
+	})
+}
+
+func (sc *SavedSearchController) UpdateSavedSearch(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	searchID, err := primitive.ObjectIDFromHex(c.Param("searchId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search ID"})
+		return
+	}
+
+	var req savedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	search, err := sc.savedSearchService.UpdateSavedSearch(studentID, searchID, req.toModel(studentID))
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update saved search"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Saved search updated successfully",
+		"search":  search,
+	})
+}
+
+func (sc *SavedSearchController) DeleteSavedSearch(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	searchID, err := primitive.ObjectIDFromHex(c.Param("searchId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search ID"})
+		return
+	}
+
+	if err := sc.savedSearchService.DeleteSavedSearch(studentID, searchID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete saved search"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Saved search deleted successfully"})
+}
+
+// alertSavedSearches matches newly published drives against saved searches
+// in the background so publishing does not wait on the notifications. The
+// drives are alerted one after another so each sees the alerts sent for the
+// ones before it when applying the daily cap.
+func alertSavedSearches(svc services.SavedSearchService, jobs ...models.Job) {
+	go func() {
+		for i := range jobs {
+			if _, err := svc.AlertNewDrive(&jobs[i]); err != nil {
+				log.Printf("saved search alerts for drive %s: %v", jobs[i].ID.Hex(), err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,189 @@
+﻿package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SkillController struct {
+	skillService    services.SkillService
+	skillGapService services.SkillGapService
+}
+
+func NewSkillController(db *mongo.Database) *SkillController {
+	return &SkillController{
+		skillService:    services.NewSkillService(db),
+		skillGapService: services.NewSkillGapService(db),
+	}
+}
+
+func (sc *SkillController) GetSkills(c *gin.Context) {
+	q, ok := listQuery(c, services.SkillListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := sc.skillService.ListSkills(c.Query("search"), c.Query("category"), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skills"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (sc *SkillController) SuggestSkills(c *gin.Context) {
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		limit = n
+	}
+
+	suggestions, err := sc.skillService.SuggestSkills(c.Query("q"), limit)
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suggest skills"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suggestions": suggestions})
+}
+
+func (sc *SkillController) CreateSkill(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	var input services.SkillInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	skill, err := sc.skillService.CreateSkill(&input, userID)
+	if err != nil {
+		sc.skillError(c, err, "Failed to create skill")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Skill created successfully",
+		"skill":   skill,
+	})
+}
+
+func (sc *SkillController) UpdateSkill(c *gin.Context) {
+	skillID, err := primitive.ObjectIDFromHex(c.Param("skillId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill ID"})
+		return
+	}
+
+	var input services.SkillInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	skill, err := sc.skillService.UpdateSkill(skillID, &input)
+	if err != nil {
+		sc.skillError(c, err, "Failed to update skill")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Skill updated successfully",
+		"skill":   skill,
+	})
+}
+
+func (sc *SkillController) DeleteSkill(c *gin.Context) {
+	skillID, err := primitive.ObjectIDFromHex(c.Param("skillId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill ID"})
+		return
+	}
+
+	if err := sc.skillService.DeleteSkill(skillID); err != nil {
+		sc.skillError(c, err, "Failed to delete skill")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Skill deleted successfully"})
+}
+
+func (sc *SkillController) NormalizeStoredSkills(c *gin.Context) {
+	report, err := sc.skillService.NormalizeStoredSkills()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to normalise stored skills", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+func (sc *SkillController) GetSkillGaps(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	viewerID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	params := &services.SkillGapParams{Departments: queryList(c, "department")}
+	for _, raw := range queryList(c, "batch") {
+		year, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Batch must be a graduation year"})
+			return
+		}
+		params.Batches = append(params.Batches, year)
+	}
+	for _, field := range []struct {
+		key    string
+		target *int
+	}{{"months", &params.Months}, {"limit", &params.Limit}} {
+		raw := c.Query(field.key)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": field.key + " must be a number"})
+			return
+		}
+		*field.target = n
+	}
+
+	report, err := sc.skillGapService.GetSkillGaps(viewerID, params)
+	if err != nil {
+		if err == services.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You cannot view skill gap analytics"})
+			return
+		}
+		sc.skillError(c, err, "Failed to compute skill gaps")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (sc *SkillController) skillError(c *gin.Context, err error, message string) {
+	if services.IsValidationError(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
@@ -28,12 +28,13 @@ type StudentController struct {
 }
 
 type ApplicationDetails struct {
-	ID          primitive.ObjectID `bson:"_id" json:"id"`
-	StudentID   primitive.ObjectID `bson:"student_id" json:"studentId"`
-	AppliedOn   time.Time          `bson:"applied_on" json:"appliedOn"`
-	Status      string             `bson:"status" json:"status"`
-	CompanyName string             `json:"companyName"`
-	Role        string             `json:"role"`
+	ID           primitive.ObjectID `bson:"_id" json:"id"`
+	StudentID    primitive.ObjectID `bson:"student_id" json:"studentId"`
+	AppliedOn    time.Time          `bson:"applied_on" json:"appliedOn"`
+	Status       string             `bson:"status" json:"status"`
+	WaitlistRank int                `bson:"waitlist_rank,omitempty" json:"waitlistRank,omitempty"`
+	CompanyName  string             `bson:"companyName" json:"companyName"`
+	Role         string             `bson:"role" json:"role"`
 }
 
 
@@ -162,42 +163,41 @@ func (sc *StudentController) GetMyApplications(c *gin.Context) {
 	studentID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
 
 
-	pipeline := mongo.Pipeline{
-
-		{{Key: "$match", Value: bson.D{{Key: "student_id", Value: studentID}}}},
-
-		{{Key: "$lookup", Value: bson.D{
-			{Key: "from", Value: "jobs"},
-			{Key: "localField", Value: "job_id"},
-			{Key: "foreignField", Value: "_id"},
-			{Key: "as", Value: "jobDetails"},
-		}}},
+	q, ok := listQuery(c, services.ApplicationListSpec)
+	if !ok {
+		return
+	}
 
-		{{Key: "$unwind", Value: "$jobDetails"}},
 
-		{{Key: "$project", Value: bson.D{
-			{Key: "_id", Value: 1},
-			{Key: "student_id", Value: 1},
-			{Key: "applied_on", Value: 1},
-			{Key: "status", Value: 1},
-			{Key: "companyName", Value: "$jobDetails.company_name.name"},
-			{Key: "role", Value: "$jobDetails.position"},
-		}}},
+	pipeline := []bson.M{
+		{"$match": bson.M{"student_id": studentID}},
+		{"$lookup": bson.M{
+			"from":         "jobs",
+			"localField":   "job_id",
+			"foreignField": "_id",
+			"as":           "jobDetails",
+		}},
+		{"$unwind": "$jobDetails"},
+	}
+	shape := []bson.M{
+		{"$project": bson.M{
+			"_id":           1,
+			"student_id":    1,
+			"applied_on":    1,
+			"status":        1,
+			"waitlist_rank": 1,
+			"companyName":   "$jobDetails.company_name.name",
+			"role":          "$jobDetails.position",
+		}},
 	}
 
-	cursor, err := sc.ApplicationCollection.Aggregate(ctx, pipeline)
+	page, _, err := services.AggregatePage[ApplicationDetails](ctx, sc.ApplicationCollection, pipeline, shape, q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
 		return
 	}
 
-	var results []ApplicationDetails
-	if err = cursor.All(ctx, &results); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode application data"})
-		return
-	}
-
-	c.JSON(http.StatusOK, results)
+	c.JSON(http.StatusOK, page)
 }
 
 
@@ -341,31 +341,29 @@ func (sc *StudentController) GetMyNotifications(c *gin.Context) {
 	}
 
 
-	var user models.User
-	err = sc.UserCollection.FindOne(ctx, bson.M{
-		"_id":  studentID,
-		"role": "student",
-	}).Decode(&user)
+	q, ok := listQuery(c, services.NotificationListSpec)
+	if !ok {
+		return
+	}
 
+	owner := bson.M{"_id": studentID, "role": "student"}
+	count, err := sc.UserCollection.CountDocuments(ctx, owner)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
-			return
-		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
 		return
 	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return
+	}
 
-
-	notifications := user.Notifications
-	if notifications == nil {
-		notifications = []models.Notification{}
+	page, err := services.ListNotifications(ctx, sc.UserCollection, owner, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"notifications": notifications,
-		"count":         len(notifications),
-	})
+	c.JSON(http.StatusOK, page)
 
 
 
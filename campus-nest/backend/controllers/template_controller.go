@@ -0,0 +1,174 @@
+﻿package controllers
+
+import (
+	"net/http"
+
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type TemplateController struct {
+	templateService services.TemplateService
+	jobService      services.JobService
+}
+
+func NewTemplateController(db *mongo.Database) *TemplateController {
+	return &TemplateController{
+		templateService: services.NewTemplateService(db),
+		jobService:      services.NewJobService(db),
+	}
+}
+
+func (tc *TemplateController) GetTemplates(c *gin.Context) {
+	var companyID *primitive.ObjectID
+	if v := c.Query("companyId"); v != "" {
+		oid, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid company ID"})
+			return
+		}
+		companyID = &oid
+	}
+
+	q, ok := listQuery(c, services.TemplateListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := tc.templateService.ListTemplates(companyID, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch drive templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (tc *TemplateController) GetTemplate(c *gin.Context) {
+	templateID, err := primitive.ObjectIDFromHex(c.Param("templateId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	template, err := tc.templateService.GetTemplate(templateID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"template": template})
+}
+
+func (tc *TemplateController) SaveDriveAsTemplate(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	driveID, err := primitive.ObjectIDFromHex(c.Param("driveId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid drive ID"})
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	template, err := tc.templateService.SaveJobAsTemplate(driveID, req.Name, userID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Drive not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save template", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Template saved successfully",
+		"template": template,
+	})
+}
+
+func (tc *TemplateController) DeleteTemplate(c *gin.Context) {
+	templateID, err := primitive.ObjectIDFromHex(c.Param("templateId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	if err := tc.templateService.DeleteTemplate(templateID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted successfully"})
+}
+
+func (tc *TemplateController) CreateDriveFromTemplate(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	templateID, err := primitive.ObjectIDFromHex(c.Param("templateId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	var cycle services.DriveCycle
+	if err := c.ShouldBindJSON(&cycle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	drive, err := tc.templateService.CreateDriveFromTemplate(templateID, &cycle, userID)
+	tc.respondWithDraft(c, drive, err, "Template not found")
+}
+
+func (tc *TemplateController) CloneDrive(c *gin.Context) {
+	userIDHex, _ := c.Get("userID")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	driveID, err := primitive.ObjectIDFromHex(c.Param("driveId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid drive ID"})
+		return
+	}
+
+	var cycle services.DriveCycle
+	if err := c.ShouldBindJSON(&cycle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	drive, err := tc.jobService.CloneJob(driveID, &cycle, userID)
+	tc.respondWithDraft(c, drive, err, "Drive not found")
+}
+
+func (tc *TemplateController) respondWithDraft(c *gin.Context, drive interface{}, err error, notFound string) {
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create draft drive", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Draft drive created. Review it and set the status to open to publish.",
+		"drive":   drive,
+	})
+}
@@ -113,113 +113,24 @@ func (tc *TPOController) GetStudentsInDepartment(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-
-	var tpo models.User
-	err = tc.UserCollection.FindOne(ctx, bson.M{"_id": tpoID, "role": "tpo"}).Decode(&tpo)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "TPO not found"})
-		return
-	}
-
-
-	query := bson.M{
-		"role": "student",
-	}
-
-
-	if tpo.Department == nil || *tpo.Department == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "TPO department not set; cannot fetch students"})
+	q, ok := listQuery(c, services.StudentListSpec)
+	if !ok {
 		return
 	}
-	query["department"] = *tpo.Department
-
-
-	search := c.Query("search")
-	if search != "" {
-
-		query["$or"] = []bson.M{
-			{"firstName": bson.M{"$regex": search, "$options": "i"}},
-			{"lastName": bson.M{"$regex": search, "$options": "i"}},
-			{"rollNumber": bson.M{"$regex": search, "$options": "i"}},
-		}
-	}
-
 
-	cursor, err := tc.UserCollection.Find(ctx, query)
+	page, err := tc.tpoService.GetStudentsInDepartment(tpoID, c.Query("search"), q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch students"})
-		return
-	}
-	defer cursor.Close(ctx)
-
-
-	var rawStudents []bson.M
-	if err = cursor.All(ctx, &rawStudents); err != nil {
-		println("DEBUG: Error decoding raw students:", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode students", "details": err.Error()})
-		return
-	}
-
-
-	placedCount := 0
-	unplacedCount := 0
-	studentData := make([]gin.H, 0, len(rawStudents))
-
-	for _, rawStudent := range rawStudents {
-
-		placementStatus := "Unplaced"
-		if status, ok := rawStudent["placedStatus"].(string); ok && status != "" {
-			placementStatus = status
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
-
-
-		if placementStatus == "Placed" {
-			placedCount++
-		} else {
-			unplacedCount++
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "TPO not found"})
+			return
 		}
-
-
-		var skills []string
-		if skillsData, ok := rawStudent["skills"].(primitive.A); ok {
-			for _, skill := range skillsData {
-				if skillStr, ok := skill.(string); ok {
-					skills = append(skills, skillStr)
-				}
-			}
-		}
-
-		studentData = append(studentData, gin.H{
-			"id":              rawStudent["_id"],
-			"firstName":       rawStudent["firstName"],
-			"lastName":        rawStudent["lastName"],
-			"email":           rawStudent["email"],
-			"rollNumber":      rawStudent["rollNumber"],
-			"department":      rawStudent["department"],
-			"cgpa":            rawStudent["cgpa"],
-			"placementStatus": placementStatus,
-			"skills":          skills,
-		})
-	}
-
-
-	totalStudents := len(rawStudents)
-	placementRate := 0.0
-	if totalStudents > 0 {
-		placementRate = float64(placedCount) / float64(totalStudents) * 100
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch students"})
+		return
 	}
 
-
-	c.JSON(http.StatusOK, gin.H{
-		"students": studentData,
-		"statistics": gin.H{
-			"totalStudents":    totalStudents,
-			"placedStudents":   placedCount,
-			"unplacedStudents": unplacedCount,
-			"placementRate":    placementRate,
-		},
-	})
+	c.JSON(http.StatusOK, page)
 }
@@ -0,0 +1,168 @@
+﻿package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"backend/models"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type WaitlistController struct {
+	UserCollection *mongo.Collection
+	JobCollection  *mongo.Collection
+
+	waitlistService services.WaitlistService
+}
+
+func NewWaitlistController(db *mongo.Database) *WaitlistController {
+	return &WaitlistController{
+		UserCollection: db.Collection("users"),
+		JobCollection:  db.Collection("jobs"),
+
+		waitlistService: services.NewWaitlistService(db),
+	}
+}
+
+func (wc *WaitlistController) recruiterJob(c *gin.Context) (*models.Job, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userIDHex, _ := c.Get("userID")
+	recruiterID, err := primitive.ObjectIDFromHex(userIDHex.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recruiter ID"})
+		return nil, false
+	}
+
+	jobID, err := primitive.ObjectIDFromHex(c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return nil, false
+	}
+
+	var recruiter models.User
+	if err := wc.UserCollection.FindOne(ctx, bson.M{"_id": recruiterID}).Decode(&recruiter); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recruiter not found"})
+		return nil, false
+	}
+	if recruiter.CompanyID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No company associated. Please contact admin."})
+		return nil, false
+	}
+
+	var job models.Job
+	err = wc.JobCollection.FindOne(ctx, bson.M{
+		"_id":                    jobID,
+		"company_name.companyId": *recruiter.CompanyID,
+	}).Decode(&job)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found or not accessible"})
+		return nil, false
+	}
+	return &job, true
+}
+
+func (wc *WaitlistController) GetWaitlist(c *gin.Context) {
+	job, ok := wc.recruiterJob(c)
+	if !ok {
+		return
+	}
+
+	q, ok := listQuery(c, services.WaitlistListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := wc.waitlistService.ListWaitlist(job.ID, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch waitlist"})
+		return
+	}
+
+	page.Summary = gin.H{"autoPromote": job.AutoPromoteWaitlist}
+	c.JSON(http.StatusOK, page)
+}
+
+func (wc *WaitlistController) UpdateWaitlist(c *gin.Context) {
+	job, ok := wc.recruiterJob(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		StudentIDs  []string `json:"studentIds"`
+		AutoPromote *bool    `json:"autoPromote"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	if req.AutoPromote != nil {
+		if err := wc.waitlistService.SetAutoPromote(job.ID, *req.AutoPromote); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update waitlist settings"})
+			return
+		}
+		job.AutoPromoteWaitlist = *req.AutoPromote
+	}
+
+	var waitlist []*models.Application
+	var err error
+	if req.StudentIDs != nil {
+		order := make([]primitive.ObjectID, 0, len(req.StudentIDs))
+		for _, id := range req.StudentIDs {
+			oid, err := primitive.ObjectIDFromHex(id)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID: " + id})
+				return
+			}
+			order = append(order, oid)
+		}
+		waitlist, err = wc.waitlistService.ReorderWaitlist(job.ID, order)
+	} else {
+		waitlist, err = wc.waitlistService.GetWaitlist(job.ID)
+	}
+	if err != nil {
+		if services.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update waitlist", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Waitlist updated successfully",
+		"waitlist":    waitlist,
+		"autoPromote": job.AutoPromoteWaitlist,
+	})
+}
+
+func (wc *WaitlistController) PromoteNext(c *gin.Context) {
+	job, ok := wc.recruiterJob(c)
+	if !ok {
+		return
+	}
+
+	promoted, err := wc.waitlistService.PromoteNext(job.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "The waitlist for this drive is empty"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to promote candidate", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Candidate promoted from the waitlist",
+		"application": promoted,
+	})
+}
@@ -7,7 +7,11 @@ import (
 	"os"
 
 	"backend/config"
+	"backend/parser"
 	"backend/routes"
+	"backend/scheduler"
+	"backend/services"
+	"backend/storage"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -28,6 +32,54 @@ func main() {
 	}()
 	log.Println("Database connection established successfully")
 
+	if err := storage.Configure(); err != nil {
+		log.Fatal("Error configuring file storage:", err)
+	}
+	if err := parser.Configure(); err != nil {
+		log.Fatal("Error configuring resume parser:", err)
+	}
+	switch parser.Default().(type) {
+	case nil:
+		log.Println("Warning: ML_SERVICE_URL is not set, uploaded resumes stay pending until a parser is configured")
+	case *parser.Fake:
+		log.Println("PARSER=fake, resumes are parsed by the local fake parser")
+	}
+
+	if err := services.EnsureJobSearchIndexes(client.Database(config.DatabaseName)); err != nil {
+		log.Println("Warning: could not prepare job search indexes:", err)
+	}
+	if err := services.EnsureSavedSearchIndexes(client.Database(config.DatabaseName)); err != nil {
+		log.Println("Warning: could not prepare saved search indexes:", err)
+	}
+	if err := services.EnsureBookmarkIndexes(client.Database(config.DatabaseName)); err != nil {
+		log.Println("Warning: could not prepare bookmark indexes:", err)
+	}
+	if err := services.EnsureResumeParseIndexes(client.Database(config.DatabaseName)); err != nil {
+		log.Println("Warning: could not prepare resume parse indexes:", err)
+	}
+	if err := services.EnsureResumeDownloadIndexes(client.Database(config.DatabaseName)); err != nil {
+		log.Println("Warning: could not prepare resume download indexes:", err)
+	}
+	if err := services.EnsurePolicyIndexes(client.Database(config.DatabaseName)); err != nil {
+		log.Println("Warning: could not prepare placement policy indexes:", err)
+	}
+	if err := services.EnsureSkillTaxonomy(client.Database(config.DatabaseName)); err != nil {
+		log.Println("Warning: could not prepare the skill taxonomy:", err)
+	}
+	if err := services.EnsureStudentBatches(client.Database(config.DatabaseName)); err != nil {
+		log.Println("Warning: could not fill in student graduation years:", err)
+	}
+	if err := services.EnsurePlacementSources(client.Database(config.DatabaseName)); err != nil {
+		log.Println("Warning: could not record student placement sources:", err)
+	}
+
+	stopParsing := services.StartResumeParseWorkers(client.Database(config.DatabaseName), 2)
+	defer stopParsing()
+
+	jobs := scheduler.NewDefault(client.Database(config.DatabaseName))
+	jobs.Start()
+	defer jobs.Stop()
+
 	if os.Getenv("GIN_MODE") == "" {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -4,6 +4,75 @@ import (
 	"time"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
+
+const (
+	ApplicationStatusApplied     = "applied"
+	ApplicationStatusShortlisted = "shortlisted"
+	ApplicationStatusInterviewed = "interviewed"
+	ApplicationStatusWaitlisted  = "waitlisted"
+	ApplicationStatusSelected    = "selected"
+	ApplicationStatusRejected    = "rejected"
+	ApplicationStatusIneligible  = "ineligible"
+)
+
+// PPO outcomes of interns selected on internship drives.
+const (
+	PPOStatusPending    = "pending"
+	PPOStatusOffered    = "offered"
+	PPOStatusNotOffered = "not_offered"
+	PPOStatusAccepted   = "accepted"
+	PPOStatusDeclined   = "declined"
+)
+
+type PPOOutcome struct {
+	Status    string             `bson:"status" json:"status"`
+	Remarks   string             `bson:"remarks,omitempty" json:"remarks,omitempty"`
+	UpdatedBy primitive.ObjectID `bson:"updated_by" json:"updated_by"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// MatchScore compares the skills a drive asks for with the applicant's.
+type MatchScore struct {
+	Score             float64   `bson:"score" json:"score"`
+	MatchedSkills     []string  `bson:"matched_skills" json:"matched_skills"`
+	MissingSkills     []string  `bson:"missing_skills" json:"missing_skills"`
+	ProfileOnlySkills []string  `bson:"profile_only_skills,omitempty" json:"profile_only_skills,omitempty"`
+	ScoredAt          time.Time `bson:"scored_at" json:"scored_at"`
+}
+
+// ApplicationSnapshot freezes the profile fields and resume recruiters look
+// at as they were when the student applied, so later edits do not change
+// what was submitted.
+type ApplicationSnapshot struct {
+	FirstName      string          `bson:"first_name" json:"first_name"`
+	LastName       string          `bson:"last_name" json:"last_name"`
+	Email          string          `bson:"email" json:"email"`
+	RollNumber     *string         `bson:"roll_number,omitempty" json:"roll_number,omitempty"`
+	Department     *string         `bson:"department,omitempty" json:"department,omitempty"`
+	Gender         *string         `bson:"gender,omitempty" json:"gender,omitempty"`
+	CGPA           *float64        `bson:"cgpa,omitempty" json:"cgpa,omitempty"`
+	Skills         []string        `bson:"skills" json:"skills"`
+	Qualifications []Qualification `bson:"qualifications,omitempty" json:"qualifications,omitempty"`
+	Education      []Education     `bson:"education,omitempty" json:"education,omitempty"`
+	Projects       []Project       `bson:"projects,omitempty" json:"projects,omitempty"`
+	Resume         *ResumeSnapshot `bson:"resume,omitempty" json:"resume,omitempty"`
+	TakenAt        time.Time       `bson:"taken_at" json:"taken_at"`
+}
+
+// ResumeSnapshot describes the submitted resume. Its file is kept for as long
+// as applications refer to it, so only the details are copied.
+type ResumeSnapshot struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	ResumeName  string             `bson:"resume_name,omitempty" json:"resume_name,omitempty"`
+	ContentType string             `bson:"content_type,omitempty" json:"content_type,omitempty"`
+	Size        int64              `bson:"size,omitempty" json:"size,omitempty"`
+	UploadedAt  time.Time          `bson:"uploaded_at" json:"uploaded_at"`
+	// Parsing may still be running when the student applies; the parsed data
+	// is filled in when it finishes.
+	ParseStatus string       `bson:"parse_status,omitempty" json:"parse_status,omitempty"`
+	ParsedData  ParsedResume `bson:"parsed_data" json:"parsed_data"`
+}
+
 type Application struct {
 	ID primitive.ObjectID `bson:"_id,omitempty"`
 	JobID primitive.ObjectID `bson:"job_id"`
@@ -13,4 +82,8 @@ type Application struct {
 	AppliedOn time.Time `bson:"applied_on"`
 	UpdatedOn time.Time `bson:"updated_on"`
 	Remarks string `bson:"remarks,omitempty"`
+	WaitlistRank int `bson:"waitlist_rank,omitempty"`
+	PPO *PPOOutcome `bson:"ppo,omitempty"`
+	Match *MatchScore `bson:"match,omitempty"`
+	Snapshot *ApplicationSnapshot `bson:"snapshot,omitempty"`
 }
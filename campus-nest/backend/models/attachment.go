@@ -0,0 +1,18 @@
+﻿package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Attachment is an uploaded file kept in storage under StorageKey.
+type Attachment struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	FileName    string             `bson:"file_name" json:"file_name"`
+	ContentType string             `bson:"content_type" json:"content_type"`
+	Size        int64              `bson:"size" json:"size"`
+	StorageKey  string             `bson:"storage_key" json:"-"`
+	UploadedBy  primitive.ObjectID `bson:"uploaded_by" json:"uploaded_by"`
+	UploadedAt  time.Time          `bson:"uploaded_at" json:"uploaded_at"`
+}
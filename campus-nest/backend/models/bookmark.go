@@ -0,0 +1,16 @@
+﻿package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Bookmark struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	StudentID         primitive.ObjectID `bson:"student_id" json:"student_id"`
+	JobID             primitive.ObjectID `bson:"job_id" json:"job_id"`
+	RemindBeforeHours int                `bson:"remind_before_hours" json:"remind_before_hours"`
+	RemindedAt        *time.Time         `bson:"reminded_at,omitempty" json:"reminded_at,omitempty"`
+	CreatedAt         time.Time          `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,24 @@
+﻿package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DriveTemplate struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name        string              `bson:"name" json:"name"`
+	CompanyName EmbeddedCompany     `bson:"company_name" json:"company_name"`
+	Position    string              `bson:"position" json:"position"`
+	Description string              `bson:"description" json:"description"`
+	Eligibility Eligibility         `bson:"eligibility" json:"eligibility"`
+	SalaryRange string              `bson:"salary_range,omitempty" json:"salary_range"`
+	JobType     ProgramType         `bson:"job_type,omitempty" json:"job_type"`
+	Internship  *InternshipDetails  `bson:"internship,omitempty" json:"internship,omitempty"`
+	Location    string              `bson:"location,omitempty" json:"location"`
+	SourceJobID *primitive.ObjectID `bson:"source_job_id,omitempty" json:"source_job_id,omitempty"`
+	CreatedBy   primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time           `bson:"updated_at" json:"updated_at"`
+}
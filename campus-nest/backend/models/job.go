@@ -6,6 +6,30 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	JobStatusDraft  = "draft"
+	JobStatusOpen   = "open"
+	JobStatusClosed = "closed"
+)
+
+// ProgramType is the kind of engagement a drive hires for. It is stored as
+// job_type.
+type ProgramType string
+
+const (
+	JobTypeFullTime   ProgramType = "full-time"
+	JobTypeInternship ProgramType = "internship"
+	JobTypePartTime   ProgramType = "part-time"
+	JobTypeContract   ProgramType = "contract"
+)
+
+// InternshipDetails is only set on internship drives.
+type InternshipDetails struct {
+	DurationMonths  int     `bson:"duration_months" json:"duration_months"`
+	StipendPerMonth float64 `bson:"stipend_per_month,omitempty" json:"stipend_per_month"`
+	PPOPossible     bool    `bson:"ppo_possible" json:"ppo_possible"`
+}
+
 type Eligibility struct {
 	MinCGPA        float64  `bson:"min_cgpa,omitempty" json:"min_cgpa"`
 	Course         []string `bson:"course,omitempty" json:"course"`
@@ -27,7 +51,14 @@ type Job struct {
 	CreatedAt           time.Time          `bson:"created_at" json:"created_at"`
 	Eligibility         Eligibility        `bson:"eligibility" json:"eligibility"`
 	SalaryRange         string             `bson:"salary_range,omitempty" json:"salary_range"`
+	PackageLPA          float64            `bson:"package_lpa,omitempty" json:"package_lpa"`
+	JobType             ProgramType        `bson:"job_type,omitempty" json:"job_type"`
+	Internship          *InternshipDetails `bson:"internship,omitempty" json:"internship,omitempty"`
 	ApplicationDeadline time.Time          `bson:"application_deadline" json:"application_deadline"`
 	Location            string             `bson:"location,omitempty" json:"location"`
 	Status              string             `bson:"status,omitempty" json:"status"`
+	AutoPromoteWaitlist bool               `bson:"auto_promote_waitlist,omitempty" json:"auto_promote_waitlist"`
+	Version             int                `bson:"version,omitempty" json:"version"`
+	UpdatedAt           time.Time          `bson:"updated_at,omitempty" json:"updated_at"`
+	Attachments         []Attachment       `bson:"attachments,omitempty" json:"attachments,omitempty"`
 }
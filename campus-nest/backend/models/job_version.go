@@ -0,0 +1,17 @@
+﻿package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type JobVersion struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	JobID         primitive.ObjectID `bson:"job_id" json:"job_id"`
+	Version       int                `bson:"version" json:"version"`
+	Snapshot      Job                `bson:"snapshot" json:"snapshot"`
+	ChangedFields []string           `bson:"changed_fields" json:"changed_fields"`
+	EditedBy      primitive.ObjectID `bson:"edited_by" json:"edited_by"`
+	EditedAt      time.Time          `bson:"edited_at" json:"edited_at"`
+}
@@ -0,0 +1,36 @@
+﻿package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	OfferStatusPending  = "pending"
+	OfferStatusAccepted = "accepted"
+	OfferStatusDeclined = "declined"
+	OfferStatusLapsed   = "lapsed"
+)
+
+type Offer struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ApplicationID    primitive.ObjectID `bson:"application_id" json:"application_id"`
+	JobID            primitive.ObjectID `bson:"job_id" json:"job_id"`
+	StudentID        primitive.ObjectID `bson:"student_id" json:"student_id"`
+	CompanyID        primitive.ObjectID `bson:"company_id" json:"company_id"`
+	CompanyName      string             `bson:"company_name" json:"company_name"`
+	Position         string             `bson:"position" json:"position"`
+	CTC              float64            `bson:"ctc" json:"ctc"`
+	JoiningDate      time.Time          `bson:"joining_date" json:"joining_date"`
+	Location         string             `bson:"location" json:"location"`
+	OfferLetterURL   string             `bson:"offer_letter_url,omitempty" json:"offer_letter_url"`
+	OfferLetter      *Attachment        `bson:"offer_letter,omitempty" json:"offer_letter,omitempty"`
+	ResponseDeadline time.Time          `bson:"response_deadline" json:"response_deadline"`
+	Status           string             `bson:"status" json:"status"`
+	DeclineReason    string             `bson:"decline_reason,omitempty" json:"decline_reason,omitempty"`
+	IssuedBy         primitive.ObjectID `bson:"issued_by" json:"issued_by"`
+	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time          `bson:"updated_at" json:"updated_at"`
+	RespondedAt      *time.Time         `bson:"responded_at,omitempty" json:"responded_at,omitempty"`
+}
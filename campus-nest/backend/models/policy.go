@@ -0,0 +1,33 @@
+﻿package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CompanyTier struct {
+	CompanyID primitive.ObjectID `bson:"companyId" json:"companyId"`
+	Tier      int                `bson:"tier" json:"tier"`
+}
+
+type TierRule struct {
+	PlacedTier   int   `bson:"placed_tier" json:"placed_tier"`
+	AllowedTiers []int `bson:"allowed_tiers" json:"allowed_tiers"`
+}
+
+type PlacementPolicy struct {
+	ID                   primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	AcademicYear         string             `bson:"academic_year" json:"academic_year"`
+	Name                 string             `bson:"name" json:"name"`
+	Description          string             `bson:"description,omitempty" json:"description"`
+	IsActive             bool               `bson:"is_active" json:"is_active"`
+	MaxOffers            int                `bson:"max_offers" json:"max_offers"`
+	DefaultTier          int                `bson:"default_tier" json:"default_tier"`
+	CompanyTiers         []CompanyTier      `bson:"company_tiers,omitempty" json:"company_tiers"`
+	TierRules            []TierRule         `bson:"tier_rules,omitempty" json:"tier_rules"`
+	MinSalaryJumpPercent float64            `bson:"min_salary_jump_percent" json:"min_salary_jump_percent"`
+	CreatedBy            primitive.ObjectID `bson:"created_by,omitempty" json:"created_by"`
+	CreatedAt            time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt            time.Time          `bson:"updated_at" json:"updated_at"`
+}
@@ -6,6 +6,12 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	ResumeParsePending = "pending"
+	ResumeParseDone    = "done"
+	ResumeParseFailed  = "failed"
+)
+
 type Education struct {
 	Degree      string      `bson:"degree,omitempty" json:"degree,omitempty"`
 	Institution string      `bson:"institution,omitempty" json:"institution,omitempty"`
@@ -27,4 +33,22 @@ type Resume struct {
 	OCRText    string             `bson:"ocr_text,omitempty" json:"ocr_text,omitempty"`
 	ParsedData ParsedResume       `bson:"parsed_data" json:"parsed_data"`
 	UploadedAt time.Time          `bson:"uploaded_at" json:"uploaded_at"`
+
+	// Set on resumes uploaded through the API rather than linked by URL.
+	StorageKey  string `bson:"storage_key,omitempty" json:"-"`
+	ContentType string `bson:"content_type,omitempty" json:"content_type,omitempty"`
+	Size        int64  `bson:"size,omitempty" json:"size,omitempty"`
+	// Template is set on resumes generated from the student's profile.
+	Template string `bson:"template,omitempty" json:"template,omitempty"`
+	// ArchivedAt is set when a student deletes a resume that applications
+	// still point to. It is kept for recruiters but no longer offered.
+	ArchivedAt *time.Time `bson:"archived_at,omitempty" json:"archived_at,omitempty"`
+
+	// Parsing of uploaded resumes runs in the background. Resumes created by
+	// the ML service directly have no status and are already parsed.
+	ParseStatus   string     `bson:"parse_status,omitempty" json:"parse_status,omitempty"`
+	ParseError    string     `bson:"parse_error,omitempty" json:"parse_error,omitempty"`
+	ParseAttempts int        `bson:"parse_attempts,omitempty" json:"-"`
+	ParseAfter    *time.Time `bson:"parse_after,omitempty" json:"-"`
+	ParsedAt      *time.Time `bson:"parsed_at,omitempty" json:"parsed_at,omitempty"`
 }
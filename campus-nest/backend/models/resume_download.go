@@ -0,0 +1,23 @@
+﻿package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ResumeDownload records who opened a student's resume so the student can
+// review it.
+type ResumeDownload struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ResumeID     primitive.ObjectID `bson:"resume_id" json:"resume_id"`
+	ResumeName   string             `bson:"resume_name,omitempty" json:"resume_name,omitempty"`
+	StudentID    primitive.ObjectID `bson:"student_id" json:"-"`
+	ViewerID     primitive.ObjectID `bson:"viewer_id" json:"viewer_id"`
+	ViewerRole   string             `bson:"viewer_role" json:"viewer_role"`
+	ViewerName   string             `bson:"viewer_name" json:"viewer_name"`
+	CompanyName  string             `bson:"company_name,omitempty" json:"company_name,omitempty"`
+	IPAddress    string             `bson:"ip_address,omitempty" json:"ip_address,omitempty"`
+	UserAgent    string             `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	DownloadedAt time.Time          `bson:"downloaded_at" json:"downloaded_at"`
+}
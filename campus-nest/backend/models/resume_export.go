@@ -0,0 +1,33 @@
+﻿package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ResumeExportPending = "pending"
+	ResumeExportReady   = "ready"
+	ResumeExportFailed  = "failed"
+)
+
+// ResumeExport is a ZIP of a drive's resumes built in the background for a
+// recruiter. The archive is deleted once ExpiresAt passes.
+type ResumeExport struct {
+	ID             primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	RecruiterID    primitive.ObjectID   `bson:"recruiter_id" json:"recruiter_id"`
+	JobID          primitive.ObjectID   `bson:"job_id" json:"job_id"`
+	Statuses       []string             `bson:"statuses,omitempty" json:"statuses,omitempty"`
+	Status         string               `bson:"status" json:"status"`
+	Error          string               `bson:"error,omitempty" json:"error,omitempty"`
+	CandidateCount int                  `bson:"candidate_count" json:"candidate_count"`
+	MissingCount   int                  `bson:"missing_count" json:"missing_count"`
+	FileName       string               `bson:"file_name" json:"file_name"`
+	Size           int64                `bson:"size,omitempty" json:"size,omitempty"`
+	StorageKey     string               `bson:"storage_key,omitempty" json:"-"`
+	ResumeIDs      []primitive.ObjectID `bson:"resume_ids,omitempty" json:"-"`
+	CreatedAt      time.Time            `bson:"created_at" json:"created_at"`
+	CompletedAt    *time.Time           `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	ExpiresAt      time.Time            `bson:"expires_at" json:"expires_at"`
+}
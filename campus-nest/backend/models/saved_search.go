@@ -0,0 +1,31 @@
+﻿package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SavedSearch struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	StudentID     primitive.ObjectID `bson:"student_id" json:"student_id"`
+	Name          string             `bson:"name" json:"name"`
+	Keywords      string             `bson:"keywords,omitempty" json:"keywords"`
+	Locations     []string           `bson:"locations,omitempty" json:"locations"`
+	MinPackageLPA float64            `bson:"min_package_lpa,omitempty" json:"min_package_lpa"`
+	EligibleOnly  bool               `bson:"eligible_only" json:"eligible_only"`
+	AlertsEnabled bool               `bson:"alerts_enabled" json:"alerts_enabled"`
+	LastAlertedAt *time.Time         `bson:"last_alerted_at,omitempty" json:"last_alerted_at,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// SearchAlert records that a student was told about a drive, so a drive
+// matching several of their saved searches only notifies them once.
+type SearchAlert struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	StudentID primitive.ObjectID `bson:"student_id" json:"student_id"`
+	JobID     primitive.ObjectID `bson:"job_id" json:"job_id"`
+	SearchID  primitive.ObjectID `bson:"search_id" json:"search_id"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,22 @@
+﻿package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Skill is an entry of the managed skill taxonomy. Skills written anywhere
+// are stored under Name when they match it or one of the Aliases.
+type Skill struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name     string             `bson:"name" json:"name"`
+	Aliases  []string           `bson:"aliases" json:"aliases"`
+	Category string             `bson:"category" json:"category"`
+	// Keys are the normalised name and aliases. A unique index on them keeps
+	// two skills from claiming the same spelling.
+	Keys      []string           `bson:"keys" json:"-"`
+	CreatedBy primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
@@ -18,13 +18,24 @@ type User struct {
 	Gender          *string            `bson:"gender,omitempty"`
 	Department      *string            `bson:"department,omitempty"`
 	PlacementStatus *string            `bson:"placedStatus,omitempty" default:"Placed"`
+	PlacedCompany   *string            `bson:"company,omitempty"`
+	PlacedRole      *string            `bson:"placedRole,omitempty"`
+	Salary          *float64           `bson:"salary,omitempty"`
+	// PlacementSource records where a placement came from: derived from
+	// the student's outcomes, imported, or entered by hand.
+	PlacementSource string `bson:"placementSource,omitempty"`
 
 	RollNumber *string  `bson:"rollNumber,omitempty"`
 	CGPA       *float64 `bson:"cgpa,omitempty"`
+	// GraduationYear is the student's batch; 0 when it is not known.
+	GraduationYear int `bson:"graduationYear,omitempty"`
 	ActiveResumeID []primitive.ObjectID `bson:"activeResumeId,omitempty" json:"activeResumeId,omitempty"`
 	Skills         []string             `bson:"skills,omitempty"`
+	PreferredLocations []string         `bson:"preferredLocations,omitempty"`
 	Notifications  []Notification       `bson:"notifications,omitempty"`
 	Qualifications []Qualification `bson:"qualifications,omitempty" json:"qualifications,omitempty"`
+	Education      []Education     `bson:"education,omitempty" json:"education,omitempty"`
+	Projects       []Project       `bson:"projects,omitempty" json:"projects,omitempty"`
 	CompanyID *primitive.ObjectID `bson:"companyId,omitempty"`
 }
 type Notification struct {
@@ -38,3 +49,9 @@ type Qualification struct {
 	Title       string `bson:"title" json:"title"`
 	Description string `bson:"description" json:"description"`
 }
+type Project struct {
+	Title        string   `bson:"title" json:"title"`
+	Description  string   `bson:"description,omitempty" json:"description,omitempty"`
+	Technologies []string `bson:"technologies,omitempty" json:"technologies,omitempty"`
+	Link         string   `bson:"link,omitempty" json:"link,omitempty"`
+}
@@ -0,0 +1,177 @@
+﻿package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"backend/models"
+)
+
+// Client calls the ML service's /parse endpoint. Each attempt has its own
+// timeout; network errors and 429/5xx responses are retried with
+// exponential backoff and jitter, other 4xx responses are permanent.
+type Client struct {
+	baseURL string
+	http    *http.Client
+
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+func NewClient(baseURL string) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("parser: invalid ML_SERVICE_URL %q", baseURL)
+	}
+	return &Client{
+		baseURL:     u.String(),
+		http:        &http.Client{Timeout: 90 * time.Second},
+		MaxAttempts: 3,
+		BaseBackoff: time.Second,
+		MaxBackoff:  15 * time.Second,
+	}, nil
+}
+
+func (c *Client) Parse(ctx context.Context, fileName, contentType string, body []byte) (*Result, error) {
+	var lastErr error
+	for attempt := 1; attempt <= c.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(c.backoff(attempt - 1)):
+			}
+		}
+
+		result, err := c.parseOnce(ctx, fileName, contentType, body)
+		if err == nil {
+			return result, nil
+		}
+		if IsPermanent(err) || ctx.Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("parser: giving up after %d attempts: %w", c.MaxAttempts, lastErr)
+}
+
+// backoff returns a random delay up to BaseBackoff*2^(retry-1), capped at
+// MaxBackoff.
+func (c *Client) backoff(retry int) time.Duration {
+	limit := c.BaseBackoff << (retry - 1)
+	if limit <= 0 || limit > c.MaxBackoff {
+		limit = c.MaxBackoff
+	}
+	return limit/2 + time.Duration(rand.Int63n(int64(limit/2)+1))
+}
+
+func (c *Client) parseOnce(ctx context.Context, fileName, contentType string, body []byte) (*Result, error) {
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, err := writer.CreatePart(map[string][]string{
+		"Content-Disposition": {fmt.Sprintf(`form-data; name="file"; filename=%q`, fileName)},
+		"Content-Type":        {contentType},
+	})
+	if err != nil {
+		return nil, err
+	}
+	part.Write(body)
+	writer.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/parse", &form)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(detail, &failure) != nil || failure.Error == "" {
+			failure.Error = strings.TrimSpace(string(detail))
+		}
+		message := fmt.Sprintf("ML service returned %s: %s", resp.Status, failure.Error)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, &PermanentError{Message: message}
+		}
+		return nil, fmt.Errorf("parser: %s", message)
+	}
+
+	var parsed mlResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, &PermanentError{Message: "unreadable response from ML service: " + err.Error()}
+	}
+	return parsed.result(), nil
+}
+
+// mlResponse is the shape the ML service returns. The LLM behind it is
+// loose with types, so anything that is not plainly a string is formatted.
+type mlResponse struct {
+	Text       string `json:"text"`
+	ParsedData struct {
+		Email     string   `json:"email"`
+		Phone     string   `json:"phone"`
+		Skills    []string `json:"skills"`
+		Education []struct {
+			Degree      interface{} `json:"degree"`
+			Institution interface{} `json:"institution"`
+			Year        interface{} `json:"year"`
+			GPA         interface{} `json:"gpa"`
+		} `json:"education"`
+	} `json:"parsed_data"`
+}
+
+func (r *mlResponse) result() *Result {
+	result := &Result{Text: r.Text}
+
+	var contact []string
+	for _, value := range []string{r.ParsedData.Email, r.ParsedData.Phone} {
+		if value = strings.TrimSpace(value); value != "" {
+			contact = append(contact, value)
+		}
+	}
+	result.Parsed.Contact = strings.Join(contact, ", ")
+
+	for _, skill := range r.ParsedData.Skills {
+		if skill = strings.TrimSpace(skill); skill != "" {
+			result.Parsed.Skills = append(result.Parsed.Skills, skill)
+		}
+	}
+	for _, e := range r.ParsedData.Education {
+		result.Parsed.Education = append(result.Parsed.Education, models.Education{
+			Degree:      text(e.Degree),
+			Institution: text(e.Institution),
+			Year:        text(e.Year),
+			GPA:         e.GPA,
+		})
+	}
+	return result
+}
+
+func text(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return fmt.Sprint(v)
+}
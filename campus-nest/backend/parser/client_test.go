@@ -0,0 +1,231 @@
+﻿package parser
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const parsedBody = `{
+	"text": "Jane Doe jane@example.com",
+	"parsed_data": {
+		"email": "jane@example.com",
+		"phone": " +91 98765 43210 ",
+		"skills": ["Go", " ", "Docker "],
+		"education": [{"degree": "B.Tech", "institution": "NIT", "year": 2025, "gpa": 8.7}]
+	}
+}`
+
+// newTestClient points a client with millisecond backoff at a server that
+// answers with the given statuses in turn, repeating the last one.
+func newTestClient(t *testing.T, statuses ...int) (*Client, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		if r.URL.Path != "/parse" {
+			t.Errorf("request path = %q, want /parse", r.URL.Path)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("request has no file: %v", err)
+		} else {
+			body, _ := io.ReadAll(file)
+			if header.Filename != "resume.pdf" || string(body) != "%PDF" {
+				t.Errorf("uploaded %q with %q, want resume.pdf with %%PDF", header.Filename, body)
+			}
+		}
+
+		status := statuses[len(statuses)-1]
+		if n <= len(statuses) {
+			status = statuses[n-1]
+		}
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			io.WriteString(w, parsedBody)
+		} else {
+			io.WriteString(w, `{"error": "try again"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseBackoff = time.Millisecond
+	client.MaxBackoff = 2 * time.Millisecond
+	return client, &calls
+}
+
+func TestClientParse(t *testing.T) {
+	client, calls := newTestClient(t, http.StatusOK)
+
+	result, err := client.Parse(context.Background(), "resume.pdf", "application/pdf", []byte("%PDF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 1 {
+		t.Errorf("calls = %d, want 1", *calls)
+	}
+	if result.Text != "Jane Doe jane@example.com" {
+		t.Errorf("Text = %q", result.Text)
+	}
+	if got, want := result.Parsed.Contact, "jane@example.com, +91 98765 43210"; got != want {
+		t.Errorf("Contact = %q, want %q", got, want)
+	}
+	if got := strings.Join(result.Parsed.Skills, ","); got != "Go,Docker" {
+		t.Errorf("Skills = %q, want Go,Docker", got)
+	}
+	if len(result.Parsed.Education) != 1 || result.Parsed.Education[0].Year != "2025" || result.Parsed.Education[0].GPA != 8.7 {
+		t.Errorf("Education = %+v", result.Parsed.Education)
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantCalls int32
+		wantErr   bool
+	}{
+		{"server errors then success", []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}, 3, false},
+		{"rate limited then success", []int{http.StatusTooManyRequests, http.StatusOK}, 2, false},
+		{"server errors until giving up", []int{http.StatusInternalServerError}, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, calls := newTestClient(t, tt.statuses...)
+
+			_, err := client.Parse(context.Background(), "resume.pdf", "application/pdf", []byte("%PDF"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && IsPermanent(err) {
+				t.Errorf("err = %v, want a temporary error", err)
+			}
+			if *calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", *calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClientPermanentErrors(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			client, calls := newTestClient(t, status)
+
+			_, err := client.Parse(context.Background(), "resume.pdf", "application/pdf", []byte("%PDF"))
+			if !IsPermanent(err) {
+				t.Fatalf("err = %v, want a permanent error", err)
+			}
+			if !strings.Contains(err.Error(), "try again") {
+				t.Errorf("err = %v, want the service's message", err)
+			}
+			if *calls != 1 {
+				t.Errorf("calls = %d, want 1", *calls)
+			}
+		})
+	}
+}
+
+func TestClientUnreadableResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<html>")
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Parse(context.Background(), "resume.pdf", "application/pdf", []byte("%PDF")); !IsPermanent(err) {
+		t.Errorf("err = %v, want a permanent error", err)
+	}
+}
+
+func TestClientStopsWhenCancelled(t *testing.T) {
+	client, calls := newTestClient(t, http.StatusServiceUnavailable)
+	client.BaseBackoff = time.Hour
+	client.MaxBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.Parse(ctx, "resume.pdf", "application/pdf", []byte("%PDF"))
+	if err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if *calls != 1 {
+		t.Errorf("calls = %d, want 1", *calls)
+	}
+}
+
+func TestClientBackoff(t *testing.T) {
+	client := &Client{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	tests := []struct {
+		retry int
+		limit time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 5 * time.Second},
+		{40, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if d := client.backoff(tt.retry); d < tt.limit/2 || d > tt.limit {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", tt.retry, d, tt.limit/2, tt.limit)
+			}
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		parser  string
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{"nothing configured", "", "", "none", false},
+		{"ML service", "", "http://localhost:5000", "client", false},
+		{"explicit ML service", "ml", "http://localhost:5000", "client", false},
+		{"ML service without URL", "ml", "", "", true},
+		{"fake", "fake", "", "fake", false},
+		{"fake wins over URL", "FAKE", "http://localhost:5000", "fake", false},
+		{"unknown parser", "regex", "", "", true},
+		{"invalid URL", "", "not a url", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PARSER", tt.parser)
+			t.Setenv("ML_SERVICE_URL", tt.url)
+
+			p, err := FromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := "none"
+			switch p.(type) {
+			case *Client:
+				got = "client"
+			case *Fake:
+				got = "fake"
+			}
+			if got != tt.want {
+				t.Errorf("FromEnv() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
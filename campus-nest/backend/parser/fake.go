@@ -0,0 +1,79 @@
+﻿package parser
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	fakeEmail = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	fakePhone = regexp.MustCompile(`\+?\d[\d -]{8,}\d`)
+)
+
+// Fake parses resumes locally. It pulls the readable text out of the file
+// and looks for contact details and known skill names, which is enough for
+// development and tests without the ML service.
+type Fake struct {
+	Skills []string
+}
+
+func NewFake() *Fake {
+	return &Fake{Skills: []string{
+		"Go", "Java", "Python", "C++", "JavaScript", "TypeScript", "React", "Node.js",
+		"SQL", "MongoDB", "Docker", "Kubernetes", "AWS", "Machine Learning", "Flutter",
+	}}
+}
+
+func (f *Fake) Parse(ctx context.Context, fileName, contentType string, body []byte) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	text := readableText(body)
+	if text == "" {
+		return nil, &PermanentError{Message: "no readable text in " + fileName}
+	}
+
+	result := &Result{Text: text}
+	var contact []string
+	if email := fakeEmail.FindString(text); email != "" {
+		contact = append(contact, email)
+	}
+	if phone := fakePhone.FindString(text); phone != "" {
+		contact = append(contact, strings.TrimSpace(phone))
+	}
+	result.Parsed.Contact = strings.Join(contact, ", ")
+
+	// Skill names keep characters like "+" and "." (C++, Node.js), so split
+	// on everything else and drop sentence-ending dots.
+	var words strings.Builder
+	words.WriteString(" ")
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("+.#", r)
+	}) {
+		words.WriteString(strings.TrimRight(word, ".") + " ")
+	}
+	for _, skill := range f.Skills {
+		if strings.Contains(words.String(), " "+strings.ToLower(skill)+" ") {
+			result.Parsed.Skills = append(result.Parsed.Skills, skill)
+		}
+	}
+	return result, nil
+}
+
+// readableText keeps runs of at least four printable characters, the way
+// strings(1) does. It is crude but finds the text of uncompressed PDFs and
+// plain documents.
+func readableText(body []byte) string {
+	var runs []string
+	for _, run := range bytes.FieldsFunc(body, func(r rune) bool {
+		return r == unicode.ReplacementChar || !unicode.IsPrint(r)
+	}) {
+		if s := strings.TrimSpace(string(run)); len(s) >= 4 {
+			runs = append(runs, s)
+		}
+	}
+	return strings.Join(runs, "\n")
+}
@@ -0,0 +1,88 @@
+﻿package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"backend/models"
+)
+
+// Result is what a parser extracts from a resume file.
+type Result struct {
+	Parsed models.ParsedResume
+	Text   string
+}
+
+// Parser turns a resume file into structured data.
+type Parser interface {
+	Parse(ctx context.Context, fileName, contentType string, body []byte) (*Result, error)
+}
+
+// PermanentError is returned when retrying the same file cannot succeed,
+// e.g. the service rejected it as unreadable.
+type PermanentError struct {
+	Message string
+}
+
+func (e *PermanentError) Error() string {
+	return "parser: " + e.Message
+}
+
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+var (
+	defaultParser Parser
+	defaultMu     sync.Mutex
+)
+
+// Configure builds the parser selected by the environment and makes it the
+// one Default returns.
+func Configure() error {
+	p, err := FromEnv()
+	if err != nil {
+		return err
+	}
+	defaultMu.Lock()
+	defaultParser = p
+	defaultMu.Unlock()
+	return nil
+}
+
+// Default returns the configured parser, or nil when no parser was
+// configured. Resumes are then left pending until one is.
+func Default() Parser {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	return defaultParser
+}
+
+// FromEnv selects the parser with PARSER. "ml", the default, is a client for
+// the ML service at ML_SERVICE_URL; "fake" is the local fake parser, for
+// development only since it guesses at skills. With PARSER unset and no
+// ML_SERVICE_URL there is no parser and FromEnv returns nil.
+func FromEnv() (Parser, error) {
+	baseURL := strings.TrimSpace(os.Getenv("ML_SERVICE_URL"))
+	switch kind := strings.ToLower(strings.TrimSpace(os.Getenv("PARSER"))); kind {
+	case "fake":
+		return NewFake(), nil
+	case "":
+		if baseURL == "" {
+			return nil, nil
+		}
+		return NewClient(baseURL)
+	case "ml":
+		if baseURL == "" {
+			return nil, errors.New("parser: PARSER=ml needs ML_SERVICE_URL")
+		}
+		return NewClient(baseURL)
+	default:
+		return nil, fmt.Errorf("parser: unknown PARSER %q, use \"ml\" or \"fake\"", kind)
+	}
+}
@@ -0,0 +1,166 @@
+﻿// Package pdf writes simple text documents as PDF using the standard Type 1
+// fonts, which every viewer has, so nothing needs to be embedded.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+	TimesRoman
+	TimesBold
+)
+
+var fontNames = [...]string{"Helvetica", "Helvetica-Bold", "Times-Roman", "Times-Bold"}
+
+// Document collects pages of drawing operations. Coordinates are in points
+// from the bottom left corner of the page.
+type Document struct {
+	Title  string
+	Author string
+	pages  []*bytes.Buffer
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage starts a new page that later drawing goes to.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws s with its baseline starting at x, y.
+func (d *Document) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(d.page(), "BT /F%d %s Tf %s %s Td (%s) Tj ET\n", font+1, num(size), num(x), num(y), escape(s))
+}
+
+// Line draws a straight line in the current color.
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page(), "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// SetColor sets the color of later text and lines. Components are 0 to 1.
+func (d *Document) SetColor(r, g, b float64) {
+	fmt.Fprintf(d.page(), "%s %s %s rg %s %s %s RG\n", num(r), num(g), num(b), num(r), num(g), num(b))
+}
+
+// WriteTo writes the document as a PDF file.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 is the catalog, 2 the page tree, 3 the info dictionary and the
+	// fonts follow; each page is then a page object and its content stream.
+	firstPage := 4 + len(fontNames)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object(fmt.Sprintf("<< /Title (%s) /Author (%s) /Producer (Campus Nest) >>", escape(d.Title), escape(d.Author)))
+
+	var fonts strings.Builder
+	for i, name := range fontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		fmt.Fprintf(&fonts, "/F%d %d 0 R ", i+1, 4+i)
+	}
+
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s>> >> /Contents %d 0 R >>",
+			num(PageWidth), num(PageHeight), fonts.String(), firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	n, err := w.Write(out.Bytes())
+	return int64(n), err
+}
+
+// num formats a coordinate without trailing zeros.
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// escape encodes s as the body of a PDF literal string in WinAnsiEncoding.
+// Characters the encoding lacks become question marks.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		c := winAnsi(r)
+		switch c {
+		case '\\', '(', ')':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n', '\r', '\t':
+			b.WriteByte(' ')
+		default:
+			if c < 32 {
+				b.WriteByte('?')
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	return b.String()
+}
+
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+func winAnsi(r rune) byte {
+	if r < 0x80 || (r >= 0xa0 && r <= 0xff) {
+		return byte(r)
+	}
+	if c, ok := winAnsiExtras[r]; ok {
+		return c
+	}
+	return '?'
+}
@@ -0,0 +1,153 @@
+﻿package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var (
+	startxref   = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	trailerSize = regexp.MustCompile(`trailer\n<< /Size (\d+) `)
+	pageCount   = regexp.MustCompile(`/Type /Pages /Kids \[[^\]]*\] /Count (\d+)`)
+	stream      = regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)endstream`)
+)
+
+// checkStructure parses a written document far enough to check that the
+// cross-reference table points at every object, that the stream lengths are
+// right and that the page tree counts the pages. It returns the page count.
+func checkStructure(t *testing.T, data []byte) int {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) {
+		t.Fatalf("document starts with %q", data[:min(len(data), 16)])
+	}
+
+	m := startxref.FindSubmatch(data)
+	if m == nil {
+		t.Fatal("no startxref at the end of the document")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+
+	lines := strings.Split(string(data[xref:]), "\n")
+	var first, count int
+	if _, err := fmt.Sscanf(lines[1], "%d %d", &first, &count); err != nil || first != 0 {
+		t.Fatalf("xref subsection %q", lines[1])
+	}
+	if lines[2] != "0000000000 65535 f " {
+		t.Errorf("xref entry 0 = %q, want the free list head", lines[2])
+	}
+	for i := 1; i < count; i++ {
+		entry := lines[2+i]
+		if len(entry) != 19 || !strings.HasSuffix(entry, " 00000 n ") {
+			t.Fatalf("xref entry %d = %q, want a 20 byte in-use entry", i, entry)
+		}
+		offset, _ := strconv.Atoi(entry[:10])
+		if want := fmt.Sprintf("%d 0 obj\n", i); !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q, want %q", i, data[offset:min(len(data), offset+12)], want)
+		}
+	}
+	if m := trailerSize.FindSubmatch(data); m == nil || string(m[1]) != strconv.Itoa(count) {
+		t.Errorf("trailer /Size does not match the %d xref entries", count)
+	}
+
+	for _, m := range stream.FindAllSubmatch(data, -1) {
+		if length, _ := strconv.Atoi(string(m[1])); length != len(m[2]) {
+			t.Errorf("stream /Length %d, but the stream has %d bytes", length, len(m[2]))
+		}
+	}
+
+	m = pageCount.FindSubmatch(data)
+	if m == nil {
+		t.Fatal("no page tree")
+	}
+	pages, _ := strconv.Atoi(string(m[1]))
+	if objects := bytes.Count(data, []byte("<< /Type /Page /Parent 2 0 R")); objects != pages {
+		t.Errorf("page tree counts %d pages, but there are %d page objects", pages, objects)
+	}
+	return pages
+}
+
+func write(t *testing.T, d *Document) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	n, err := d.WriteTo(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(out.Len()) {
+		t.Errorf("WriteTo() = %d, but wrote %d bytes", n, out.Len())
+	}
+	return out.Bytes()
+}
+
+func TestEmptyDocumentHasOnePage(t *testing.T) {
+	if pages := checkStructure(t, write(t, New())); pages != 1 {
+		t.Errorf("pages = %d, want 1", pages)
+	}
+}
+
+func TestPages(t *testing.T) {
+	d := New()
+	d.Title = "Jane (Doe)"
+	for i := 1; i <= 3; i++ {
+		d.AddPage()
+		d.SetColor(0.12, 0.31, 0.55)
+		d.Text(50, 700, HelveticaBold, 12, fmt.Sprintf("Page %d", i))
+		d.Line(50, 690, 545.28, 690, 0.75)
+	}
+	if d.PageCount() != 3 {
+		t.Errorf("PageCount() = %d, want 3", d.PageCount())
+	}
+
+	data := write(t, d)
+	if pages := checkStructure(t, data); pages != 3 {
+		t.Errorf("pages = %d, want 3", pages)
+	}
+	for _, want := range []string{
+		"/Title (Jane \\(Doe\\))",
+		"BT /F2 12 Tf 50 700 Td (Page 3) Tj ET\n",
+		"0.12 0.31 0.55 rg 0.12 0.31 0.55 RG\n",
+		"0.75 w 50 690 m 545.28 690 l S\n",
+		"/MediaBox [0 0 595.28 841.89]",
+	} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("document does not contain %q", want)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{`C:\path (copy)`, `C:\\path \(copy\)`},
+		{"line\nbreak\ttab", "line break tab"},
+		{"bell\x07", "bell?"},
+		{"Café", "Caf\xe9"},
+		{"€5 – “quoted” •", "\x805 \x96 \x93quoted\x94 \x95"},
+		{"日本", "??"},
+		{"\u0085", "?"},
+	}
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTextIsEscaped(t *testing.T) {
+	d := New()
+	d.Text(10, 20, TimesRoman, 10.5, `Smith (Jr.) \ “CS”`)
+	data := write(t, d)
+	checkStructure(t, data)
+	if want := "BT /F3 10.5 Tf 10 20 Td (Smith \\(Jr.\\) \\\\ \x93CS\x94) Tj ET\n"; !bytes.Contains(data, []byte(want)) {
+		t.Errorf("document does not contain %q", want)
+	}
+}
@@ -1,6 +1,7 @@
 ﻿package routes
 
 import (
+	"backend/config"
 	"backend/controllers"
 	"backend/middleware"
 
@@ -9,7 +10,7 @@ import (
 )
 
 func SetupRoutes(router *gin.Engine, client *mongo.Client) {
-	db := client.Database("campusNestDB")
+	db := client.Database(config.DatabaseName)
 
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok", "message": "Server is running"})
@@ -22,6 +23,14 @@ func SetupRoutes(router *gin.Engine, client *mongo.Client) {
 	jobController := controllers.NewJobController(db)
 	adminController := controllers.NewAdminController(db)
 	companyController := controllers.NewCompanyController(db)
+	policyController := controllers.NewPolicyController(db)
+	offerController := controllers.NewOfferController(db)
+	waitlistController := controllers.NewWaitlistController(db)
+	templateController := controllers.NewTemplateController(db)
+	savedSearchController := controllers.NewSavedSearchController(db)
+	attachmentController := controllers.NewAttachmentController(db)
+	resumeController := controllers.NewResumeController(db)
+	skillController := controllers.NewSkillController(db)
 
 	api := router.Group("/api/v1")
 	{
@@ -29,15 +38,45 @@ func SetupRoutes(router *gin.Engine, client *mongo.Client) {
 		{
 			public.POST("/login", authController.Login)
 		}
+		api.GET("/resumes/:resumeId/download", resumeController.DownloadSignedResume)
+
 		studentRoutes := api.Group("/student")
 		studentRoutes.Use(middleware.AuthMiddleware("student"))
 		{
 			studentRoutes.GET("/jobs", jobController.GetAvailableJobs)
+			studentRoutes.GET("/jobs/search", jobController.SearchJobs)
+			studentRoutes.GET("/jobs/recommended", jobController.GetRecommendedJobs)
 			studentRoutes.GET("/jobs/:jobId", jobController.GetJobById)
 			studentRoutes.GET("/applications", studentController.GetMyApplications)
 			studentRoutes.GET("/applications/:applicationId", studentController.GetApplicationDetails)
 			studentRoutes.POST("/jobs/:jobId/apply", jobController.ApplyForJob)
+			studentRoutes.GET("/jobs/:jobId/policy-check", jobController.CheckApplicationPolicy)
+			studentRoutes.GET("/jobs/:jobId/attachments/:attachmentId", attachmentController.DownloadDriveAttachment)
+			studentRoutes.POST("/jobs/:jobId/bookmark", jobController.BookmarkJob)
+			studentRoutes.DELETE("/jobs/:jobId/bookmark", jobController.RemoveBookmark)
+			studentRoutes.GET("/bookmarks", jobController.GetBookmarks)
+			studentRoutes.POST("/resumes", resumeController.UploadResume)
+			studentRoutes.GET("/resumes", resumeController.GetMyResumes)
+			studentRoutes.GET("/resumes/downloads", resumeController.GetResumeDownloads)
+			studentRoutes.POST("/resumes/generate", resumeController.GenerateResume)
+			studentRoutes.GET("/resumes/templates", resumeController.GetResumeTemplates)
+			studentRoutes.PUT("/resumes/:resumeId", resumeController.RenameResume)
+			studentRoutes.DELETE("/resumes/:resumeId", resumeController.DeleteResume)
+			studentRoutes.PUT("/resumes/:resumeId/primary", resumeController.SetPrimaryResume)
+			studentRoutes.GET("/resumes/:resumeId/file", resumeController.DownloadResume)
+			studentRoutes.GET("/resumes/:resumeId/parse", resumeController.GetParseStatus)
+			studentRoutes.POST("/resumes/:resumeId/parse", resumeController.RetryParse)
+			studentRoutes.PUT("/profile", dashboardController.UpdateProfile)
 			studentRoutes.GET("/notifications", studentController.GetMyNotifications)
+			studentRoutes.GET("/offers", offerController.GetMyOffers)
+			studentRoutes.GET("/offers/:offerId", offerController.GetMyOffer)
+			studentRoutes.GET("/offers/:offerId/letter", offerController.DownloadOfferLetter)
+			studentRoutes.POST("/offers/:offerId/accept", offerController.AcceptOffer)
+			studentRoutes.POST("/offers/:offerId/decline", offerController.DeclineOffer)
+			studentRoutes.GET("/saved-searches", savedSearchController.GetSavedSearches)
+			studentRoutes.POST("/saved-searches", savedSearchController.CreateSavedSearch)
+			studentRoutes.PUT("/saved-searches/:searchId", savedSearchController.UpdateSavedSearch)
+			studentRoutes.DELETE("/saved-searches/:searchId", savedSearchController.DeleteSavedSearch)
 		}
 		tpoRoutes := api.Group("/tpo")
 		tpoRoutes.Use(middleware.AuthMiddleware("tpo"))
@@ -45,14 +84,29 @@ func SetupRoutes(router *gin.Engine, client *mongo.Client) {
 			tpoRoutes.GET("/analytics", dashboardController.GetTPOAnalyticsDashboard)
 			tpoRoutes.GET("/companies", companyController.GetAllCompanies)
 			tpoRoutes.POST("/drives", dashboardController.CreateDrive)
+			tpoRoutes.POST("/drives/import", dashboardController.ImportDrives)
 			tpoRoutes.POST("/reports", dashboardController.GenerateReport)
 			tpoRoutes.GET("/drives", dashboardController.GetAllDrives)
+			tpoRoutes.GET("/resumes/search", resumeController.SearchResumes)
 			tpoRoutes.GET("/drives/:driveId", dashboardController.GetDriveDetails)
+			tpoRoutes.PUT("/drives/:driveId", dashboardController.UpdateDrive)
+			tpoRoutes.POST("/drives/:driveId/attachments", attachmentController.UploadDriveAttachment)
+			tpoRoutes.GET("/drives/:driveId/attachments/:attachmentId", attachmentController.DownloadDriveAttachment)
+			tpoRoutes.DELETE("/drives/:driveId/attachments/:attachmentId", attachmentController.DeleteDriveAttachment)
+			tpoRoutes.GET("/drives/:driveId/versions", dashboardController.GetDriveVersions)
+			tpoRoutes.POST("/drives/:driveId/clone", templateController.CloneDrive)
+			tpoRoutes.POST("/drives/:driveId/template", templateController.SaveDriveAsTemplate)
+			tpoRoutes.GET("/drive-templates", templateController.GetTemplates)
+			tpoRoutes.GET("/drive-templates/:templateId", templateController.GetTemplate)
+			tpoRoutes.DELETE("/drive-templates/:templateId", templateController.DeleteTemplate)
+			tpoRoutes.POST("/drive-templates/:templateId/drives", templateController.CreateDriveFromTemplate)
 			tpoRoutes.GET("/drives/:driveId/applications", dashboardController.GetDriveApplications)
 			tpoRoutes.PUT("/drives/:driveId/status", dashboardController.UpdateDriveStatus)
 			tpoRoutes.GET("/analytics/company-placements", dashboardController.GetCompanyWisePlacements)
 			tpoRoutes.GET("/analytics/salary", dashboardController.GetSalaryAnalytics)
 			tpoRoutes.GET("/analytics/trends", dashboardController.GetPlacementTrends)
+			tpoRoutes.GET("/analytics/ppo", offerController.GetPPOAnalytics)
+			tpoRoutes.GET("/analytics/skill-gaps", skillController.GetSkillGaps)
 			tpoRoutes.GET("/reports/export", dashboardController.ExportReport)
 			tpoRoutes.POST("/notifications", dashboardController.SendNotification)
 			tpoRoutes.POST("/notifications/preview", dashboardController.PreviewNotification)
@@ -66,10 +120,25 @@ func SetupRoutes(router *gin.Engine, client *mongo.Client) {
 		{
 			recruiterRoutes.GET("/candidates", dashboardController.GetRecruiterCandidates)
 			recruiterRoutes.GET("/resumes/download-all", dashboardController.DownloadAllResumes)
+			recruiterRoutes.GET("/resumes/search", resumeController.SearchResumes)
+			recruiterRoutes.GET("/resumes/exports/:exportId", dashboardController.GetResumeExport)
+			recruiterRoutes.GET("/resumes/exports/:exportId/download", dashboardController.DownloadResumeExport)
 			recruiterRoutes.GET("/job-drives", dashboardController.GetCompanyJobDrives)
 			recruiterRoutes.GET("/job-drives/:jobId", dashboardController.GetJobDriveDetails)
 			recruiterRoutes.GET("/job-drives/:jobId/students/:studentId", dashboardController.GetStudentDetailsForJobDrive)
 			recruiterRoutes.PUT("/job-drives/:jobId/students/status", dashboardController.UpdateStudentApplicationStatus)
+			recruiterRoutes.PUT("/students/:studentId/status", dashboardController.UpdateStudentPlacementStatus)
+			recruiterRoutes.POST("/job-drives/:jobId/students/:studentId/offer", offerController.CreateOffer)
+			recruiterRoutes.PUT("/job-drives/:jobId/students/:studentId/ppo", offerController.RecordPPOOutcome)
+			recruiterRoutes.POST("/job-drives/:jobId/attachments", attachmentController.UploadDriveAttachment)
+			recruiterRoutes.GET("/job-drives/:jobId/attachments/:attachmentId", attachmentController.DownloadDriveAttachment)
+			recruiterRoutes.DELETE("/job-drives/:jobId/attachments/:attachmentId", attachmentController.DeleteDriveAttachment)
+			recruiterRoutes.GET("/offers", offerController.GetCompanyOffers)
+			recruiterRoutes.POST("/offers/:offerId/letter", offerController.UploadOfferLetter)
+			recruiterRoutes.GET("/offers/:offerId/letter", offerController.DownloadOfferLetter)
+			recruiterRoutes.GET("/job-drives/:jobId/waitlist", waitlistController.GetWaitlist)
+			recruiterRoutes.PUT("/job-drives/:jobId/waitlist", waitlistController.UpdateWaitlist)
+			recruiterRoutes.POST("/job-drives/:jobId/waitlist/promote", waitlistController.PromoteNext)
 			recruiterRoutes.GET("/notifications", dashboardController.GetRecruiterNotifications)
 			recruiterRoutes.GET("/stats", dashboardController.GetRecStats)
 		}
@@ -78,13 +147,16 @@ func SetupRoutes(router *gin.Engine, client *mongo.Client) {
 		{
 			protected.GET("/profile/:role", authController.GetProfileByRole)
 			protected.GET("/dashboard/:role", dashboardController.GetDashboardByRole)
-			protected.PUT("/profile/:role", dashboardController.UpdateSkills)
+			protected.PUT("/profile/:role", dashboardController.UpdateProfile)
+			protected.GET("/resumes/:resumeId/link", resumeController.GetResumeLink)
+			protected.GET("/skills/suggest", skillController.SuggestSkills)
 		}
 
 		adminRoutes := api.Group("/admin")
 		adminRoutes.Use(middleware.AuthMiddleware("admin"))
 		{
 			adminRoutes.GET("/students", adminController.GetStudents)
+			adminRoutes.GET("/resumes/search", resumeController.SearchResumes)
 			adminRoutes.POST("/student", adminController.AddStudent)
 			adminRoutes.POST("/students/upload-csv", adminController.AddStudentsBatch)
 			adminRoutes.GET("/tpos", adminController.GetAllTPOs)
@@ -95,11 +167,34 @@ func SetupRoutes(router *gin.Engine, client *mongo.Client) {
 			adminRoutes.POST("/announcements", adminController.SendAnnouncement)
 			adminRoutes.GET("/analytics/placements", adminController.GetPlacementStats)
 			adminRoutes.GET("/analytics/companies", adminController.GetCompanyAnalytics)
+			adminRoutes.GET("/analytics/ppo", offerController.GetPPOAnalytics)
+			adminRoutes.GET("/analytics/skill-gaps", skillController.GetSkillGaps)
+			adminRoutes.POST("/placements/reconcile", adminController.ReconcilePlacements)
 			adminRoutes.POST("/drives", adminController.CreateJobDrive)
 			adminRoutes.GET("/drives", adminController.GetAllDrives)
 			adminRoutes.GET("/drives/:driveId", adminController.GetDriveDetails)
 			adminRoutes.GET("/drives/:driveId/applications", adminController.GetDriveApplications)
+			adminRoutes.POST("/drives/:driveId/clone", templateController.CloneDrive)
+			adminRoutes.POST("/drives/:driveId/attachments", attachmentController.UploadDriveAttachment)
+			adminRoutes.GET("/drives/:driveId/attachments/:attachmentId", attachmentController.DownloadDriveAttachment)
+			adminRoutes.DELETE("/drives/:driveId/attachments/:attachmentId", attachmentController.DeleteDriveAttachment)
+			adminRoutes.POST("/drives/:driveId/template", templateController.SaveDriveAsTemplate)
+			adminRoutes.GET("/drive-templates", templateController.GetTemplates)
+			adminRoutes.GET("/drive-templates/:templateId", templateController.GetTemplate)
+			adminRoutes.DELETE("/drive-templates/:templateId", templateController.DeleteTemplate)
+			adminRoutes.POST("/drive-templates/:templateId/drives", templateController.CreateDriveFromTemplate)
 			adminRoutes.GET("/reports/export", adminController.ExportReport)
+			adminRoutes.GET("/policies", policyController.GetPolicies)
+			adminRoutes.POST("/policies", policyController.CreatePolicy)
+			adminRoutes.GET("/policies/:policyId", policyController.GetPolicy)
+			adminRoutes.PUT("/policies/:policyId", policyController.UpdatePolicy)
+			adminRoutes.DELETE("/policies/:policyId", policyController.DeletePolicy)
+			adminRoutes.PUT("/policies/:policyId/activate", policyController.ActivatePolicy)
+			adminRoutes.GET("/skills", skillController.GetSkills)
+			adminRoutes.POST("/skills", skillController.CreateSkill)
+			adminRoutes.PUT("/skills/:skillId", skillController.UpdateSkill)
+			adminRoutes.DELETE("/skills/:skillId", skillController.DeleteSkill)
+			adminRoutes.POST("/skills/normalize", skillController.NormalizeStoredSkills)
 		}
 
 	}
@@ -0,0 +1,52 @@
+﻿package scheduler
+
+import (
+	"log"
+	"time"
+
+	"backend/services"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func NewDefault(db *mongo.Database) *Scheduler {
+	s := New()
+
+	offerService := services.NewOfferService(db)
+	s.Every("lapse-expired-offers", 10*time.Minute, func() error {
+		lapsed, err := offerService.LapseExpiredOffers()
+		if lapsed > 0 {
+			log.Printf("Lapsed %d expired offer(s)", lapsed)
+		}
+		return err
+	})
+
+	bookmarkService := services.NewBookmarkService(db)
+	s.Every("bookmark-deadline-reminders", 15*time.Minute, func() error {
+		reminded, err := bookmarkService.SendDeadlineReminders()
+		if reminded > 0 {
+			log.Printf("Sent %d bookmark deadline reminder(s)", reminded)
+		}
+		return err
+	})
+
+	resumeParseService := services.NewResumeParseService(db)
+	s.Every("resume-parse-sweep", time.Minute, func() error {
+		queued, err := resumeParseService.QueuePendingParses()
+		if queued > 0 {
+			log.Printf("Queued %d pending resume(s) for parsing", queued)
+		}
+		return err
+	})
+
+	resumeExportService := services.NewResumeExportService(db)
+	s.Every("expire-resume-exports", time.Hour, func() error {
+		deleted, err := resumeExportService.DeleteExpiredResumeExports()
+		if deleted > 0 {
+			log.Printf("Deleted %d expired resume export(s)", deleted)
+		}
+		return err
+	})
+
+	return s
+}
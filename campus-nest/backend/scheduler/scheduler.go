@@ -0,0 +1,68 @@
+﻿package scheduler
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+type task struct {
+	name     string
+	interval time.Duration
+	run      func() error
+}
+
+type Scheduler struct {
+	tasks []task
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{stop: make(chan struct{})}
+}
+
+func (s *Scheduler) Every(name string, interval time.Duration, run func() error) {
+	s.tasks = append(s.tasks, task{name: name, interval: interval, run: run})
+}
+
+func (s *Scheduler) Start() {
+	for _, t := range s.tasks {
+		s.wg.Add(1)
+		go s.loop(t)
+	}
+	log.Printf("Scheduler started with %d task(s)", len(s.tasks))
+}
+
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(t task) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	s.runOnce(t)
+	for {
+		select {
+		case <-ticker.C:
+			s.runOnce(t)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(t task) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Scheduler task %s panicked: %v", t.name, r)
+		}
+	}()
+	if err := t.run(); err != nil {
+		log.Printf("Scheduler task %s failed: %v", t.name, err)
+	}
+}
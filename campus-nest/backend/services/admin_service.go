@@ -22,11 +22,11 @@ type AdminService interface {
 	SendAnnouncement(subject, message string, targets []string) error
 	GetPlacementStats() (map[string]interface{}, error)
 	GetCompanyAnalytics() (map[string]interface{}, error)
-	CreateJobDrive(jobData map[string]interface{}) error
 }
 
 type AdminServiceImpl struct {
 	userCollection *mongo.Collection
+	skills         *skillTaxonomy
 }
 
 
@@ -37,15 +37,43 @@ func (as *AdminServiceImpl) UserCollection() *mongo.Collection {
 func NewAdminService(db *mongo.Database) AdminService {
 	return &AdminServiceImpl{
 		userCollection: db.Collection("users"),
+		skills:         newSkillTaxonomy(db),
 	}
 }
 
+// spaceSeparatedSkills are skills from a cell that only had spaces between
+// them, so multi-word skills arrive split into words.
+type spaceSeparatedSkills []string
+
+// canonicalStudentSkills puts the skills of a student from normalizeStudent
+// into canonical form.
+func (as *AdminServiceImpl) canonicalStudentSkills(ctx context.Context, student map[string]interface{}) error {
+	var skills []string
+	var err error
+	switch t := student["skills"].(type) {
+	case spaceSeparatedSkills:
+		skills, err = as.skills.normalizeWords(ctx, t)
+	case []string:
+		skills, err = as.skills.normalize(ctx, t)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if skills == nil {
+		skills = []string{}
+	}
+	student["skills"] = skills
+	return nil
+}
+
 
 
 
 
 
-func normalizeStudent(raw map[string]interface{}, defaultPwdHash string) map[string]interface{} {
+func normalizeStudent(raw map[string]interface{}, defaultPwdHash, placementSource string) map[string]interface{} {
 
 	lower := make(map[string]interface{})
 	for k, v := range raw {
@@ -113,6 +141,18 @@ func normalizeStudent(raw map[string]interface{}, defaultPwdHash string) map[str
 	}
 
 
+	for _, key := range []string{"graduationyear", "graduation_year", "graduation year", "batch"} {
+		v, ok := lower[key]
+		if !ok {
+			continue
+		}
+		if year, err := strconv.Atoi(strings.TrimSpace(fmt.Sprintf("%v", v))); err == nil && validGraduationYear(year) {
+			out["graduationYear"] = year
+		}
+		break
+	}
+
+
 	if v, ok := lower["skills"]; ok {
 		switch t := v.(type) {
 		case string:
@@ -127,7 +167,8 @@ func normalizeStudent(raw map[string]interface{}, defaultPwdHash string) map[str
 				} else if strings.Contains(s, ",") {
 					parts = strings.Split(s, ",")
 				} else {
-					parts = strings.Fields(s)
+					out["skills"] = spaceSeparatedSkills(strings.Fields(s))
+					break
 				}
 				var skills []string
 				for _, p := range parts {
@@ -152,6 +193,16 @@ func normalizeStudent(raw map[string]interface{}, defaultPwdHash string) map[str
 
 
 	out["role"] = "student"
+	// An imported placement is kept; the known statuses get their usual
+	// spelling and a missing one means unplaced.
+	status, _ := out["placedStatus"].(string)
+	switch strings.ToLower(strings.Join(strings.Fields(status), " ")) {
+	case "placed":
+		out["placedStatus"] = PlacementStatusPlaced
+		out["placementSource"] = placementSource
+	case "", "unplaced", "not placed", "not-placed":
+		out["placedStatus"] = PlacementStatusUnplaced
+	}
 	if _, ok := lower["passwordhash"]; ok {
 
 		if v := lower["passwordhash"]; v != nil {
@@ -169,8 +220,11 @@ func (as *AdminServiceImpl) AddStudent(studentData map[string]interface{}) error
 	defaultPwd := "password123"
 	pwdHash, _ := bcrypt.GenerateFromPassword([]byte(defaultPwd), bcrypt.DefaultCost)
 
-	normalized := normalizeStudent(studentData, string(pwdHash))
+	normalized := normalizeStudent(studentData, string(pwdHash), PlacementSourceManual)
 	ctx := context.TODO()
+	if err := as.canonicalStudentSkills(ctx, normalized); err != nil {
+		return err
+	}
 	_, err := as.userCollection.InsertOne(ctx, normalized)
 	return err
 }
@@ -193,6 +247,7 @@ func (as *AdminServiceImpl) AddStudentsBatch(csvURL string) error {
 	}
 	header := records[0]
 	var docs []interface{}
+	ctx := context.TODO()
 
 	defaultPwd := "password123"
 	pwdHash, _ := bcrypt.GenerateFromPassword([]byte(defaultPwd), bcrypt.DefaultCost)
@@ -209,14 +264,16 @@ func (as *AdminServiceImpl) AddStudentsBatch(csvURL string) error {
 			}
 			raw[field] = strings.TrimSpace(row[i])
 		}
-		normalized := normalizeStudent(raw, string(pwdHash))
+		normalized := normalizeStudent(raw, string(pwdHash), PlacementSourceImported)
+		if err := as.canonicalStudentSkills(ctx, normalized); err != nil {
+			return err
+		}
 		docs = append(docs, normalized)
 	}
 	if len(docs) == 0 {
 		return fmt.Errorf("No students to insert")
 	}
 
-	ctx := context.TODO()
 	opts := options.InsertMany().SetOrdered(false)
 	_, err = as.userCollection.InsertMany(ctx, docs, opts)
 	return err
@@ -574,11 +631,3 @@ func (as *AdminServiceImpl) GetCompanyAnalyticsWithFilters(filters map[string]st
 	}
 	return resp, nil
 }
-
-func (as *AdminServiceImpl) CreateJobDrive(jobData map[string]interface{}) error {
-
-	jobData["createdAt"] = nil
-	ctx := context.TODO()
-	_, err := as.userCollection.Database().Collection("jobs").InsertOne(ctx, jobData)
-	return err
-}
@@ -0,0 +1,114 @@
+﻿package services
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"backend/models"
+)
+
+// NewApplicationSnapshot copies what a recruiter sees of the student and the
+// chosen resume at the moment they apply. resume may be nil.
+func NewApplicationSnapshot(student *models.User, resume *models.Resume) *models.ApplicationSnapshot {
+	snapshot := &models.ApplicationSnapshot{
+		FirstName:      student.FirstName,
+		LastName:       student.LastName,
+		Email:          student.Email,
+		RollNumber:     student.RollNumber,
+		Department:     student.Department,
+		Gender:         student.Gender,
+		CGPA:           student.CGPA,
+		Skills:         append([]string{}, student.Skills...),
+		Qualifications: student.Qualifications,
+		Education:      student.Education,
+		Projects:       student.Projects,
+		TakenAt:        time.Now(),
+	}
+	if resume != nil {
+		snapshot.Resume = &models.ResumeSnapshot{
+			ID:          resume.ID,
+			ResumeName:  resume.ResumeName,
+			ContentType: resume.ContentType,
+			Size:        resume.Size,
+			UploadedAt:  resume.UploadedAt,
+			ParseStatus: resume.ParseStatus,
+			ParsedData:  resume.ParsedData,
+		}
+		if snapshot.Resume.ParseStatus == "" {
+			snapshot.Resume.ParseStatus = models.ResumeParseDone
+		}
+	}
+	return snapshot
+}
+
+// SnapshotChange is a difference between a submitted snapshot and the
+// student's profile now. Skills report what was added and removed instead of
+// the whole lists.
+type SnapshotChange struct {
+	Field     string      `json:"field"`
+	Submitted interface{} `json:"submitted,omitempty"`
+	Current   interface{} `json:"current,omitempty"`
+	Added     []string    `json:"added,omitempty"`
+	Removed   []string    `json:"removed,omitempty"`
+}
+
+// SnapshotChanges lists how the student's profile and resume differ from
+// the snapshot. resume is the submitted resume as stored now, nil if it is
+// gone.
+func SnapshotChanges(snapshot *models.ApplicationSnapshot, student *models.User, resume *models.Resume) []SnapshotChange {
+	changes := []SnapshotChange{}
+	if snapshot == nil {
+		return changes
+	}
+
+	compare := func(field string, submitted, current interface{}) {
+		if !reflect.DeepEqual(submitted, current) {
+			changes = append(changes, SnapshotChange{Field: field, Submitted: submitted, Current: current})
+		}
+	}
+	compare("firstName", snapshot.FirstName, student.FirstName)
+	compare("lastName", snapshot.LastName, student.LastName)
+	compare("email", snapshot.Email, student.Email)
+	compare("rollNumber", derefString(snapshot.RollNumber), derefString(student.RollNumber))
+	compare("department", derefString(snapshot.Department), derefString(student.Department))
+	compare("cgpa", formatCGPA(snapshot.CGPA), formatCGPA(student.CGPA))
+
+	var added, removed []string
+	for _, skill := range student.Skills {
+		if !containsFold(snapshot.Skills, skill) {
+			added = append(added, skill)
+		}
+	}
+	for _, skill := range snapshot.Skills {
+		if !containsFold(student.Skills, skill) {
+			removed = append(removed, skill)
+		}
+	}
+	if len(added) > 0 || len(removed) > 0 {
+		changes = append(changes, SnapshotChange{Field: "skills", Added: added, Removed: removed})
+	}
+
+	// The longer sections are only flagged; the snapshot has the submitted
+	// version. Empty and missing lists count as the same.
+	section := func(field string, submitted, current interface{}, submittedLen, currentLen int) {
+		if submittedLen != currentLen || (currentLen > 0 && !reflect.DeepEqual(submitted, current)) {
+			changes = append(changes, SnapshotChange{Field: field})
+		}
+	}
+	section("qualifications", snapshot.Qualifications, student.Qualifications, len(snapshot.Qualifications), len(student.Qualifications))
+	section("education", snapshot.Education, student.Education, len(snapshot.Education), len(student.Education))
+	section("projects", snapshot.Projects, student.Projects, len(snapshot.Projects), len(student.Projects))
+
+	if snapshot.Resume != nil {
+		switch {
+		case resume == nil:
+			changes = append(changes, SnapshotChange{Field: "resume", Submitted: snapshot.Resume.ResumeName, Current: "deleted"})
+		case resume.ArchivedAt != nil:
+			changes = append(changes, SnapshotChange{Field: "resume", Submitted: snapshot.Resume.ResumeName, Current: "removed by the student"})
+		case strings.TrimSpace(resume.ResumeName) != strings.TrimSpace(snapshot.Resume.ResumeName):
+			changes = append(changes, SnapshotChange{Field: "resumeName", Submitted: snapshot.Resume.ResumeName, Current: resume.ResumeName})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,166 @@
+﻿package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"backend/models"
+	"backend/storage"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	MaxAttachmentSize    = 10 << 20
+	MaxAttachmentsPerJob = 5
+)
+
+// ErrForbidden is returned when the caller may not see a resource that
+// exists.
+var ErrForbidden = errors.New("forbidden")
+
+type AttachmentServiceImpl struct {
+	jobCollection *mongo.Collection
+	store         storage.Storage
+}
+
+func NewAttachmentService(db *mongo.Database) AttachmentService {
+	return &AttachmentServiceImpl{
+		jobCollection: db.Collection("jobs"),
+		store:         storage.Default(),
+	}
+}
+
+// jobFilter matches the drive, restricted to the company's drives when the
+// caller is a recruiter.
+func jobFilter(jobID primitive.ObjectID, companyID *primitive.ObjectID) bson.M {
+	filter := bson.M{"_id": jobID}
+	if companyID != nil {
+		filter["company_name.companyId"] = *companyID
+	}
+	return filter
+}
+
+func (as *AttachmentServiceImpl) AddJobAttachment(jobID primitive.ObjectID, companyID *primitive.ObjectID, fileName string, r io.Reader, size int64, uploadedBy primitive.ObjectID) (*models.Attachment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	if err := checkUploadSize(size, MaxAttachmentSize, "file"); err != nil {
+		return nil, err
+	}
+
+	var job models.Job
+	if err := as.jobCollection.FindOne(ctx, jobFilter(jobID, companyID)).Decode(&job); err != nil {
+		return nil, err
+	}
+	if len(job.Attachments) >= MaxAttachmentsPerJob {
+		return nil, newValidationError("file", fmt.Sprintf("A drive can have at most %d attachments", MaxAttachmentsPerJob))
+	}
+
+	contentType, ext, body, err := sniffUpload(r, documentContentTypes, "file", "PDF, DOC or DOCX")
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := models.Attachment{
+		ID:          primitive.NewObjectID(),
+		ContentType: contentType,
+		Size:        size,
+		UploadedBy:  uploadedBy,
+		UploadedAt:  time.Now(),
+	}
+	attachment.FileName = uploadFileName(fileName, "job-description", ext)
+	attachment.StorageKey = fmt.Sprintf("drives/%s/%s%s", jobID.Hex(), attachment.ID.Hex(), ext)
+
+	if err := as.store.Put(ctx, attachment.StorageKey, body, size, contentType); err != nil {
+		return nil, err
+	}
+
+	// The size guard is repeated in the update so concurrent uploads cannot
+	// go over the limit.
+	filter := jobFilter(jobID, companyID)
+	filter[fmt.Sprintf("attachments.%d", MaxAttachmentsPerJob-1)] = bson.M{"$exists": false}
+	result, err := as.jobCollection.UpdateOne(ctx, filter, bson.M{"$push": bson.M{"attachments": attachment}})
+	if err == nil && result.MatchedCount == 0 {
+		err = newValidationError("file", fmt.Sprintf("A drive can have at most %d attachments", MaxAttachmentsPerJob))
+	}
+	if err != nil {
+		as.store.Delete(ctx, attachment.StorageKey)
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (as *AttachmentServiceImpl) DeleteJobAttachment(jobID, attachmentID primitive.ObjectID, companyID *primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	attachment, _, err := as.findAttachment(ctx, jobFilter(jobID, companyID), attachmentID)
+	if err != nil {
+		return err
+	}
+
+	_, err = as.jobCollection.UpdateOne(ctx, jobFilter(jobID, companyID),
+		bson.M{"$pull": bson.M{"attachments": bson.M{"_id": attachmentID}}},
+	)
+	if err != nil {
+		return err
+	}
+	return as.store.Delete(ctx, attachment.StorageKey)
+}
+
+// OpenJobAttachment opens an attachment for viewer. Students may only
+// download attachments of published drives they are eligible for, and
+// recruiters only those of their company's drives.
+func (as *AttachmentServiceImpl) OpenJobAttachment(jobID, attachmentID primitive.ObjectID, viewer *models.User) (*models.Attachment, io.ReadCloser, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	attachment, job, err := as.findAttachment(ctx, bson.M{"_id": jobID}, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch viewer.Role {
+	case "student":
+		if job.Status == models.JobStatusDraft {
+			return nil, nil, mongo.ErrNoDocuments
+		}
+		if len(eligibilityFailures(viewer, job.Eligibility)) > 0 {
+			return nil, nil, ErrForbidden
+		}
+	case "rec":
+		if viewer.CompanyID == nil || *viewer.CompanyID != job.CompanyName.CompanyID {
+			return nil, nil, ErrForbidden
+		}
+	}
+
+	// The download streams after this call returns, so it must not use the
+	// lookup's context.
+	file, err := as.store.Open(context.Background(), attachment.StorageKey)
+	if err == storage.ErrNotFound {
+		return nil, nil, mongo.ErrNoDocuments
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, file, nil
+}
+
+func (as *AttachmentServiceImpl) findAttachment(ctx context.Context, filter bson.M, attachmentID primitive.ObjectID) (*models.Attachment, *models.Job, error) {
+	var job models.Job
+	if err := as.jobCollection.FindOne(ctx, filter).Decode(&job); err != nil {
+		return nil, nil, err
+	}
+	for i := range job.Attachments {
+		if job.Attachments[i].ID == attachmentID {
+			return &job.Attachments[i], &job, nil
+		}
+	}
+	return nil, nil, mongo.ErrNoDocuments
+}
@@ -0,0 +1,250 @@
+﻿package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DefaultBookmarkReminderHours = 24
+	MaxBookmarkReminderHours     = 7 * 24
+)
+
+var BookmarkListSpec = ListSpec{
+	Sorts: map[string]string{
+		"createdAt": "created_at",
+		"deadline":  "job.application_deadline",
+	},
+	DefaultSort: "deadline",
+}
+
+type BookmarkServiceImpl struct {
+	bookmarkCollection    *mongo.Collection
+	jobCollection         *mongo.Collection
+	applicationCollection *mongo.Collection
+	userCollection        *mongo.Collection
+}
+
+func NewBookmarkService(db *mongo.Database) BookmarkService {
+	return &BookmarkServiceImpl{
+		bookmarkCollection:    db.Collection("bookmarks"),
+		jobCollection:         db.Collection("jobs"),
+		applicationCollection: db.Collection("applications"),
+		userCollection:        db.Collection("users"),
+	}
+}
+
+func EnsureBookmarkIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := db.Collection("bookmarks").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "student_id", Value: 1}, {Key: "job_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "reminded_at", Value: 1}}},
+	})
+	return err
+}
+
+// BookmarkJob bookmarks a published drive. Bookmarking a drive again only
+// changes when the reminder goes out; created reports whether the bookmark
+// is new.
+func (bs *BookmarkServiceImpl) BookmarkJob(studentID, jobID primitive.ObjectID, remindBeforeHours int) (*models.Bookmark, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if remindBeforeHours == 0 {
+		remindBeforeHours = DefaultBookmarkReminderHours
+	}
+	if remindBeforeHours < 1 || remindBeforeHours > MaxBookmarkReminderHours {
+		return nil, false, newValidationError("remind_before_hours", fmt.Sprintf("Reminder must be between 1 and %d hours before the deadline", MaxBookmarkReminderHours))
+	}
+
+	published, err := bs.jobCollection.CountDocuments(ctx, bson.M{"_id": jobID, "status": bson.M{"$ne": models.JobStatusDraft}})
+	if err != nil {
+		return nil, false, err
+	}
+	if published == 0 {
+		return nil, false, mongo.ErrNoDocuments
+	}
+
+	var existing models.Bookmark
+	err = bs.bookmarkCollection.FindOneAndUpdate(ctx,
+		bson.M{"student_id": studentID, "job_id": jobID},
+		bson.M{
+			"$set":   bson.M{"remind_before_hours": remindBeforeHours},
+			"$unset": bson.M{"reminded_at": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&existing)
+	if err == nil {
+		return &existing, false, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, false, err
+	}
+
+	bookmark := &models.Bookmark{
+		ID:                primitive.NewObjectID(),
+		StudentID:         studentID,
+		JobID:             jobID,
+		RemindBeforeHours: remindBeforeHours,
+		CreatedAt:         time.Now(),
+	}
+	if _, err := bs.bookmarkCollection.InsertOne(ctx, bookmark); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			// A concurrent request bookmarked the drive first.
+			return bookmark, false, nil
+		}
+		return nil, false, err
+	}
+	return bookmark, true, nil
+}
+
+func (bs *BookmarkServiceImpl) RemoveBookmark(studentID, jobID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := bs.bookmarkCollection.DeleteOne(ctx, bson.M{"student_id": studentID, "job_id": jobID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// ListBookmarks pages through a student's bookmarks with the drive and
+// whether they have applied to it since. Bookmarks of deleted or unpublished
+// drives are left out.
+func (bs *BookmarkServiceImpl) ListBookmarks(studentID primitive.ObjectID, q *ListQuery) (*ListPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"student_id": studentID}},
+		{"$lookup": bson.M{
+			"from":         "jobs",
+			"localField":   "job_id",
+			"foreignField": "_id",
+			"as":           "job",
+		}},
+		{"$unwind": "$job"},
+		{"$match": bson.M{"job.status": bson.M{"$ne": models.JobStatusDraft}}},
+	}
+	shape := []bson.M{
+		{"$lookup": bson.M{
+			"from": "applications",
+			"let":  bson.M{"jobId": "$job_id"},
+			"pipeline": []bson.M{
+				{"$match": bson.M{
+					"student_id": studentID,
+					"$expr":      bson.M{"$eq": bson.A{"$job_id", "$$jobId"}},
+				}},
+				{"$limit": 1},
+			},
+			"as": "application",
+		}},
+		{"$addFields": bson.M{"has_applied": bson.M{"$gt": bson.A{bson.M{"$size": "$application"}, 0}}}},
+		{"$project": bson.M{"application": 0}},
+	}
+
+	page, _, err := AggregatePage[BookmarkedJob](ctx, bs.bookmarkCollection, pipeline, shape, q)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// SendDeadlineReminders notifies students about bookmarked drives they have
+// not applied to once the drive's deadline is within the bookmark's
+// reminder window. Each bookmark is reminded at most once.
+func (bs *BookmarkServiceImpl) SendDeadlineReminders() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	cursor, err := bs.bookmarkCollection.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"reminded_at": bson.M{"$exists": false}}},
+		{"$lookup": bson.M{
+			"from":         "jobs",
+			"localField":   "job_id",
+			"foreignField": "_id",
+			"as":           "job",
+		}},
+		{"$unwind": "$job"},
+		{"$match": bson.M{
+			"job.status":               models.JobStatusOpen,
+			"job.application_deadline": bson.M{"$gt": now},
+		}},
+		{"$match": bson.M{"$expr": bson.M{"$lte": bson.A{
+			bson.M{"$subtract": bson.A{
+				"$job.application_deadline",
+				bson.M{"$multiply": bson.A{"$remind_before_hours", int64(time.Hour / time.Millisecond)}},
+			}},
+			now,
+		}}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var due []BookmarkedJob
+	if err = cursor.All(ctx, &due); err != nil {
+		return 0, err
+	}
+
+	reminded := 0
+	for _, bookmark := range due {
+		applied, err := bs.applicationCollection.CountDocuments(ctx, bson.M{"student_id": bookmark.StudentID, "job_id": bookmark.JobID})
+		if err != nil {
+			return reminded, err
+		}
+
+		// Claim the bookmark first so overlapping runs do not remind twice.
+		result, err := bs.bookmarkCollection.UpdateOne(ctx,
+			bson.M{"_id": bookmark.ID, "reminded_at": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"reminded_at": now}},
+		)
+		if err != nil {
+			return reminded, err
+		}
+		if result.ModifiedCount == 0 || applied > 0 {
+			continue
+		}
+
+		subject := "Application deadline approaching"
+		message := fmt.Sprintf("Applications for %s at %s close on %s. You bookmarked this drive but have not applied yet.",
+			bookmark.Job.Position, bookmark.Job.CompanyName.Name, bookmark.Job.ApplicationDeadline.Format("02 Jan 2006, 15:04"))
+		if _, err := pushNotification(ctx, bs.userCollection, []primitive.ObjectID{bookmark.StudentID}, subject, message); err != nil {
+			return reminded, err
+		}
+		reminded++
+	}
+	return reminded, nil
+}
+
+// ResetReminders lets the bookmarks of a drive be reminded again, since the
+// reminders already sent were for its old deadline.
+func (bs *BookmarkServiceImpl) ResetReminders(jobID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := bs.bookmarkCollection.UpdateMany(ctx,
+		bson.M{"job_id": jobID, "reminded_at": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"reminded_at": ""}},
+	)
+	return err
+}
@@ -23,22 +23,21 @@ func NewCompanyService(db *mongo.Database) CompanyService {
 
 }
 
-func (cs *CompanyServiceImpl) GetAllCompanies() ([]*models.Company, error) {
+var CompanyListSpec = ListSpec{
+	Sorts:       map[string]string{"name": "name", "industry": "industry"},
+	DefaultSort: "name",
+}
+
+func (cs *CompanyServiceImpl) GetAllCompanies(q *ListQuery) (*ListPage, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := cs.companyCollection.Find(ctx, bson.M{})
+	page, _, err := FindPage[*models.Company](ctx, cs.companyCollection, bson.M{}, q)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
-
-	var companies []*models.Company
-	if err = cursor.All(ctx, &companies); err != nil {
-		return nil, err
-	}
 
-	return companies, nil
+	return page, nil
 }
 
 func (cs *CompanyServiceImpl) GetCompanyByID(companyID primitive.ObjectID) (*models.Company, error) {
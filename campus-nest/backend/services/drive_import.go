@@ -0,0 +1,313 @@
+﻿package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// driveImportColumns maps a Job field to the header spellings accepted for it.
+var driveImportColumns = map[string][]string{
+	"company":         {"company", "company_name", "company name"},
+	"position":        {"position", "role", "title", "job title"},
+	"description":     {"description", "job description"},
+	"location":        {"location", "job location"},
+	"salary":          {"salary", "salary_range", "salary range", "ctc", "package"},
+	"deadline":        {"deadline", "application_deadline", "application deadline", "last date"},
+	"min_cgpa":        {"min_cgpa", "min cgpa", "cgpa"},
+	"course":          {"course", "courses", "branch", "branches"},
+	"skills":          {"skills", "required skills"},
+	"batch":           {"batch", "batches"},
+	"graduation_year": {"graduation_year", "graduation year", "passing year"},
+	"max_backlogs":    {"max_backlogs", "max backlogs", "backlogs"},
+	"status":          {"status"},
+	"job_type":        {"job_type", "job type", "type", "employment type"},
+	"duration":        {"duration", "duration_months", "duration (months)", "internship duration"},
+	"stipend":         {"stipend", "stipend_per_month", "monthly stipend"},
+	"ppo":             {"ppo", "ppo_possible", "ppo possible", "pre-placement offer"},
+}
+
+// importDateLayouts are tried in order. Dates with the year last are read
+// day first, whether the year has two digits or four.
+var importDateLayouts = []string{
+	"2006-01-02",
+	"02-01-2006",
+	"02/01/2006",
+	"2/1/2006",
+	"2006/01/02",
+	"02-01-06",
+	"02/01/06",
+	"2006-01-02 15:04",
+	time.RFC3339,
+}
+
+// dayFirstDate matches dates read day first by importDateLayouts.
+var dayFirstDate = regexp.MustCompile(`^(\d{1,2})[-/](\d{1,2})[-/]\d{2}(\d{2})?$`)
+
+func (js *JobServiceImpl) ImportJobs(r io.Reader, filename string, opts DriveImportOptions, postedBy primitive.ObjectID) (*DriveImportReport, error) {
+	rows, err := readSpreadsheet(r, filename)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) < 2 {
+		return nil, newValidationError("file", "The file needs a header row and at least one drive")
+	}
+
+	columns := mapImportHeader(rows[0])
+	for _, required := range []string{"company", "position", "description", "location", "deadline"} {
+		if _, ok := columns[required]; !ok {
+			return nil, newValidationError("file", fmt.Sprintf("Missing required column %q", required))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	report := &DriveImportReport{DryRun: opts.DryRun, Rows: []DriveImportRow{}}
+	jobs := make(map[int]*models.Job)
+	seen := make(map[string]int)
+
+	for i, row := range rows[1:] {
+		rowNumber := i + 2
+		if isBlankRow(row) {
+			continue
+		}
+		report.TotalRows++
+
+		job, errs, warnings := parseImportRow(row, columns)
+		result := DriveImportRow{Row: rowNumber, Position: job.Position, Company: job.CompanyName.Name, Warnings: warnings}
+
+		if len(errs) == 0 {
+			if err := js.validateJob(ctx, job); err != nil {
+				if !IsValidationError(err) {
+					return nil, err
+				}
+				errs = append(errs, err.Error())
+			}
+		}
+		if len(errs) == 0 {
+			key := strings.ToLower(job.CompanyName.CompanyID.Hex() + "|" + job.Position + "|" + job.ApplicationDeadline.Format("2006-01-02"))
+			if first, ok := seen[key]; ok {
+				errs = append(errs, fmt.Sprintf("Duplicate of row %d", first))
+			} else {
+				seen[key] = rowNumber
+			}
+		}
+
+		if len(errs) > 0 {
+			result.Status = "invalid"
+			result.Errors = errs
+			report.InvalidRows++
+		} else {
+			result.Status = "valid"
+			result.Company = job.CompanyName.Name
+			report.ValidRows++
+			jobs[len(report.Rows)] = job
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	if opts.DryRun || (report.InvalidRows > 0 && !opts.SkipInvalid) {
+		return report, nil
+	}
+
+	now := time.Now()
+	for i := range report.Rows {
+		job, ok := jobs[i]
+		if !ok {
+			continue
+		}
+		job.ID = primitive.NewObjectID()
+		job.PostedBy = postedBy
+		job.CreatedAt = now
+		if _, err := js.jobCollection.InsertOne(ctx, job); err != nil {
+			report.Rows[i].Status = "failed"
+			report.Rows[i].Errors = []string{err.Error()}
+			continue
+		}
+		report.Rows[i].Status = "created"
+		report.Rows[i].DriveID = &job.ID
+		report.Created++
+		report.Drives = append(report.Drives, *job)
+	}
+	return report, nil
+}
+
+func mapImportHeader(header []string) map[string]int {
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for field, aliases := range driveImportColumns {
+			if _, taken := columns[field]; taken {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					columns[field] = i
+					break
+				}
+			}
+		}
+	}
+	return columns
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseImportRow reads a drive from a row. Warnings point out values that
+// were read but may not mean what the sheet intended.
+func parseImportRow(row []string, columns map[string]int) (*models.Job, []string, []string) {
+	cell := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var errs, warnings []string
+	job := &models.Job{
+		CompanyName: models.EmbeddedCompany{Name: cell("company")},
+		Position:    cell("position"),
+		Description: cell("description"),
+		Location:    cell("location"),
+		SalaryRange: cell("salary"),
+		Status:      strings.ToLower(cell("status")),
+		JobType:     models.ProgramType(cell("job_type")),
+	}
+
+	if v := cell("deadline"); v != "" {
+		deadline, err := parseImportDate(v)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Unrecognised deadline %q, use YYYY-MM-DD", v))
+		} else if ambiguousImportDate(v) {
+			warnings = append(warnings, fmt.Sprintf("Deadline %q was read day first as %s; use YYYY-MM-DD if that is wrong", v, deadline.Format("2 January 2006")))
+		}
+		job.ApplicationDeadline = deadline
+	}
+
+	if v := cell("min_cgpa"); v != "" {
+		cgpa, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", "."), 64)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Minimum CGPA %q is not a number", v))
+		}
+		job.Eligibility.MinCGPA = cgpa
+	}
+	if v := cell("graduation_year"); v != "" {
+		year, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Graduation year %q is not a year", v))
+		}
+		job.Eligibility.GraduationYear = year
+	}
+	if v := cell("max_backlogs"); v != "" {
+		backlogs, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Maximum backlogs %q is not a whole number", v))
+		}
+		job.Eligibility.MaxBacklogs = backlogs
+	}
+
+	job.Eligibility.Course = splitImportList(cell("course"))
+	job.Eligibility.Skills = splitImportList(cell("skills"))
+	for _, v := range splitImportList(cell("batch")) {
+		year, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Batch %q is not a year", v))
+			continue
+		}
+		job.Eligibility.Batch = append(job.Eligibility.Batch, year)
+	}
+
+	if duration, stipend, ppo := cell("duration"), cell("stipend"), cell("ppo"); duration != "" || stipend != "" || ppo != "" {
+		job.Internship = &models.InternshipDetails{}
+		if duration != "" {
+			months, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(duration), " months"))
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("Duration %q is not a number of months", duration))
+			}
+			job.Internship.DurationMonths = months
+		}
+		if stipend != "" {
+			amount, err := strconv.ParseFloat(strings.ReplaceAll(stipend, ",", ""), 64)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("Stipend %q is not a number", stipend))
+			}
+			job.Internship.StipendPerMonth = amount
+		}
+		switch strings.ToLower(ppo) {
+		case "", "no", "n", "false", "0":
+		case "yes", "y", "true", "1":
+			job.Internship.PPOPossible = true
+		default:
+			errs = append(errs, fmt.Sprintf("PPO %q should be yes or no", ppo))
+		}
+	}
+
+	return job, errs, warnings
+}
+
+func splitImportList(v string) []string {
+	if v == "" {
+		return nil
+	}
+	sep := ","
+	if strings.Contains(v, ";") {
+		sep = ";"
+	}
+	var out []string
+	for _, part := range strings.Split(v, sep) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// ambiguousImportDate reports whether a day-first date would also be a valid
+// different date read month first, as 05-03-2026 is.
+func ambiguousImportDate(v string) bool {
+	m := dayFirstDate.FindStringSubmatch(v)
+	if m == nil {
+		return false
+	}
+	day, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	return day != month && day <= 12 && month <= 12
+}
+
+// parseImportDate accepts the common typed layouts as well as raw Excel
+// serial dates. Dates without a time run to the end of that day.
+func parseImportDate(v string) (time.Time, error) {
+	for _, layout := range importDateLayouts {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			if !strings.Contains(layout, "15") {
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(v, 64); err == nil && serial > 0 {
+		t, err := excelize.ExcelDateToTime(serial, false)
+		if err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, time.Local), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", v)
+}
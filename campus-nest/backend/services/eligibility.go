@@ -0,0 +1,127 @@
+﻿package services
+
+import (
+	"fmt"
+	"strings"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// eligibilityFailures lists the reasons a student falls outside a drive's
+// eligibility. Criteria the profile has no data for are not held against them.
+func eligibilityFailures(student *models.User, e models.Eligibility) []string {
+	var reasons []string
+	if e.MinCGPA > 0 && student.CGPA != nil && *student.CGPA < e.MinCGPA {
+		reasons = append(reasons, fmt.Sprintf("CGPA %.2f is below the minimum of %.2f", *student.CGPA, e.MinCGPA))
+	}
+	if len(e.Course) > 0 && student.Department != nil && !containsFold(e.Course, *student.Department) {
+		reasons = append(reasons, fmt.Sprintf("%s is not an eligible course", *student.Department))
+	}
+	if !batchAllowed(e, student.GraduationYear) {
+		reasons = append(reasons, fmt.Sprintf("The %d batch is not eligible", student.GraduationYear))
+	}
+	return reasons
+}
+
+// eligibilityFilter matches the drives eligibilityFailures finds nothing
+// against for the student, so searches agree with what they may apply to.
+func eligibilityFilter(student *models.User) bson.M {
+	var and bson.A
+	unless := func(field string, match interface{}) {
+		and = append(and, bson.M{"$or": bson.A{
+			bson.M{field: bson.M{"$exists": false}},
+			bson.M{field: match},
+		}})
+	}
+	if student.CGPA != nil {
+		unless("eligibility.min_cgpa", bson.M{"$lte": *student.CGPA})
+	}
+	if student.Department != nil {
+		unless("eligibility.course", bson.M{"$in": caseInsensitive([]string{*student.Department})})
+	}
+	if student.GraduationYear != 0 {
+		unless("eligibility.batch", student.GraduationYear)
+		unless("eligibility.graduation_year", student.GraduationYear)
+	}
+	if len(and) == 0 {
+		return bson.M{}
+	}
+	return bson.M{"$and": and}
+}
+
+// eligibleBatches lists the graduation years a drive is open to, and whether
+// it restricts them at all. A drive with both a batch list and a graduation
+// year is open to the years in both.
+func eligibleBatches(e models.Eligibility) ([]int, bool) {
+	switch {
+	case len(e.Batch) > 0 && e.GraduationYear != 0:
+		if containsInt(e.Batch, e.GraduationYear) {
+			return []int{e.GraduationYear}, true
+		}
+		return nil, true
+	case len(e.Batch) > 0:
+		return e.Batch, true
+	case e.GraduationYear != 0:
+		return []int{e.GraduationYear}, true
+	}
+	return nil, false
+}
+
+// batchAllowed reports whether a student graduating in year may apply. An
+// unknown year (0) passes, since many students have none on record.
+func batchAllowed(e models.Eligibility, year int) bool {
+	years, restricted := eligibleBatches(e)
+	return year == 0 || !restricted || containsInt(years, year)
+}
+
+func eligibilityTightened(before, after models.Eligibility) bool {
+	if after.MinCGPA > before.MinCGPA {
+		return true
+	}
+	if after.MaxBacklogs > 0 && (before.MaxBacklogs == 0 || after.MaxBacklogs < before.MaxBacklogs) {
+		return true
+	}
+	if afterYears, restricted := eligibleBatches(after); restricted {
+		beforeYears, wasRestricted := eligibleBatches(before)
+		if !wasRestricted {
+			return true
+		}
+		for _, year := range beforeYears {
+			if !containsInt(afterYears, year) {
+				return true
+			}
+		}
+	}
+	if len(after.Course) == 0 {
+		return false
+	}
+	if len(before.Course) == 0 {
+		return true
+	}
+	for _, course := range before.Course {
+		if !containsFold(after.Course, course) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, v int) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, v string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(strings.TrimSpace(candidate), strings.TrimSpace(v)) {
+			return true
+		}
+	}
+	return false
+}
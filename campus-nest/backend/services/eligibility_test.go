@@ -0,0 +1,62 @@
+﻿package services
+
+import (
+	"testing"
+
+	"backend/models"
+)
+
+func TestEligibilityFailuresBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		criteria models.Eligibility
+		year     int
+		eligible bool
+	}{
+		{"no batch criteria", models.Eligibility{}, 2025, true},
+		{"in the batch list", models.Eligibility{Batch: []int{2025, 2026}}, 2026, true},
+		{"outside the batch list", models.Eligibility{Batch: []int{2025, 2026}}, 2024, false},
+		{"graduation year", models.Eligibility{GraduationYear: 2026}, 2026, true},
+		{"other graduation year", models.Eligibility{GraduationYear: 2026}, 2025, false},
+		{"both must hold", models.Eligibility{Batch: []int{2025, 2026}, GraduationYear: 2026}, 2025, false},
+		{"unknown year passes", models.Eligibility{Batch: []int{2025}, GraduationYear: 2025}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			student := &models.User{GraduationYear: tt.year}
+			failures := eligibilityFailures(student, tt.criteria)
+			if got := len(failures) == 0; got != tt.eligible {
+				t.Errorf("eligibilityFailures() = %q, want eligible %v", failures, tt.eligible)
+			}
+		})
+	}
+}
+
+func TestEligibilityTightened(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after models.Eligibility
+		tightened     bool
+	}{
+		{"unchanged", models.Eligibility{MinCGPA: 7, Batch: []int{2025}}, models.Eligibility{MinCGPA: 7, Batch: []int{2025}}, false},
+		{"higher CGPA", models.Eligibility{MinCGPA: 7}, models.Eligibility{MinCGPA: 7.5}, true},
+		{"course added", models.Eligibility{}, models.Eligibility{Course: []string{"CSE"}}, true},
+		{"course widened", models.Eligibility{Course: []string{"CSE"}}, models.Eligibility{Course: []string{"cse", "ECE"}}, false},
+		{"batch added", models.Eligibility{}, models.Eligibility{Batch: []int{2026}}, true},
+		{"batch dropped", models.Eligibility{Batch: []int{2025, 2026}}, models.Eligibility{Batch: []int{2026}}, true},
+		{"batch widened", models.Eligibility{Batch: []int{2026}}, models.Eligibility{Batch: []int{2025, 2026}}, false},
+		{"batch lifted", models.Eligibility{Batch: []int{2026}}, models.Eligibility{}, false},
+		{"graduation year set within batch", models.Eligibility{Batch: []int{2026}}, models.Eligibility{Batch: []int{2026}, GraduationYear: 2026}, false},
+		{"graduation year changed", models.Eligibility{GraduationYear: 2026}, models.Eligibility{GraduationYear: 2027}, true},
+		{"backlog limit added", models.Eligibility{}, models.Eligibility{MaxBacklogs: 2}, true},
+		{"backlog limit lowered", models.Eligibility{MaxBacklogs: 2}, models.Eligibility{MaxBacklogs: 1}, true},
+		{"backlog limit raised", models.Eligibility{MaxBacklogs: 1}, models.Eligibility{MaxBacklogs: 2}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eligibilityTightened(tt.before, tt.after); got != tt.tightened {
+				t.Errorf("eligibilityTightened() = %v, want %v", got, tt.tightened)
+			}
+		})
+	}
+}
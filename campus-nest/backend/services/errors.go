@@ -0,0 +1,21 @@
+﻿package services
+
+import "errors"
+
+type ValidationError struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func newValidationError(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
+
+func IsValidationError(err error) bool {
+	var ve *ValidationError
+	return errors.As(err, &ve)
+}
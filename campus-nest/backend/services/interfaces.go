@@ -1,6 +1,10 @@
 ﻿package services
 
 import (
+	"context"
+	"io"
+	"time"
+
 	"backend/models"
 
 	"github.com/gin-gonic/gin"
@@ -25,20 +29,56 @@ type StudentService interface {
 	GetStudentProfile(studentID primitive.ObjectID) (*StudentProfileResponse, error)
 	GetStudentApplications(studentID primitive.ObjectID) ([]*ApplicationResponse, error)
 	UpdateStudentSkills(studentID primitive.ObjectID, skills []string) error
+	UpdateStudentProfile(studentID primitive.ObjectID, update *ProfileUpdate) (*StudentProfileResponse, error)
 }
 
 
 type TPOService interface {
 	GetTPOProfile(tpoID primitive.ObjectID) (*TPOProfileResponse, error)
-	GetStudentsInDepartment(tpoID primitive.ObjectID, searchQuery string) (*StudentsResponse, error)
+	GetStudentsInDepartment(tpoID primitive.ObjectID, searchQuery string, q *ListQuery) (*ListPage, error)
 }
 
 
 type JobService interface {
-	GetAvailableJobs(page, limit int) ([]*JobResponse, error)
+	GetAvailableJobs(q *ListQuery) (*ListPage, error)
 	GetJobByID(jobID primitive.ObjectID) (*JobResponse, error)
 	ApplyForJob(studentID, jobID primitive.ObjectID, resumeID primitive.ObjectID) error
 	CreateJob(job *models.Job) (*primitive.ObjectID, error)
+	UpdateJob(jobID primitive.ObjectID, update *JobUpdate, editedBy primitive.ObjectID) (*JobUpdateResult, error)
+	GetJobVersions(jobID primitive.ObjectID, q *ListQuery) (*ListPage, error)
+	CloneJob(jobID primitive.ObjectID, cycle *DriveCycle, createdBy primitive.ObjectID) (*models.Job, error)
+	CreateDraft(job *models.Job, cycle *DriveCycle, createdBy primitive.ObjectID) error
+	ImportJobs(r io.Reader, filename string, opts DriveImportOptions, postedBy primitive.ObjectID) (*DriveImportReport, error)
+}
+
+
+type JobSearchService interface {
+	SearchJobs(params *JobSearchParams) (*JobSearchResult, error)
+}
+
+
+type TemplateService interface {
+	ListTemplates(companyID *primitive.ObjectID, q *ListQuery) (*ListPage, error)
+	GetTemplate(templateID primitive.ObjectID) (*models.DriveTemplate, error)
+	SaveJobAsTemplate(jobID primitive.ObjectID, name string, createdBy primitive.ObjectID) (*models.DriveTemplate, error)
+	DeleteTemplate(templateID primitive.ObjectID) error
+	CreateDriveFromTemplate(templateID primitive.ObjectID, cycle *DriveCycle, createdBy primitive.ObjectID) (*models.Job, error)
+}
+
+
+type SkillService interface {
+	ListSkills(search, category string, q *ListQuery) (*ListPage, error)
+	SuggestSkills(prefix string, limit int) ([]SkillSuggestion, error)
+	CreateSkill(input *SkillInput, createdBy primitive.ObjectID) (*models.Skill, error)
+	UpdateSkill(skillID primitive.ObjectID, input *SkillInput) (*models.Skill, error)
+	DeleteSkill(skillID primitive.ObjectID) error
+	NormalizeSkills(skills []string) ([]string, error)
+	NormalizeStoredSkills() (*SkillNormalizationReport, error)
+}
+
+
+type SkillGapService interface {
+	GetSkillGaps(viewerID primitive.ObjectID, params *SkillGapParams) (*SkillGapReport, error)
 }
 
 
@@ -50,13 +90,140 @@ type DashboardService interface {
 
 
 type CompanyService interface {
-	GetAllCompanies() ([]*models.Company, error)
+	GetAllCompanies(q *ListQuery) (*ListPage, error)
 	GetCompanyByID(companyID primitive.ObjectID) (*models.Company, error)
 	CreateCompany(company *models.Company) (*primitive.ObjectID, error)
 	AddRecruiterToCompany(companyID primitive.ObjectID, recruiter map[string]interface{}) error
 }
 
 
+type PolicyService interface {
+	ListPolicies(academicYear string, q *ListQuery) (*ListPage, error)
+	GetPolicyByID(policyID primitive.ObjectID) (*models.PlacementPolicy, error)
+	CreatePolicy(policy *models.PlacementPolicy) (*primitive.ObjectID, error)
+	UpdatePolicy(policyID primitive.ObjectID, policy *models.PlacementPolicy) error
+	DeletePolicy(policyID primitive.ObjectID) error
+	ActivatePolicy(policyID primitive.ObjectID) error
+	GetActivePolicy(academicYear string) (*models.PlacementPolicy, error)
+	EvaluateApplication(studentID, jobID primitive.ObjectID) (*PolicyDecision, error)
+}
+
+
+type OfferService interface {
+	CreateOffer(offer *models.Offer) (*models.Offer, error)
+	GetOfferByID(offerID primitive.ObjectID) (*models.Offer, error)
+	GetStudentOffers(studentID primitive.ObjectID, q *ListQuery) (*ListPage, error)
+	GetCompanyOffers(companyID primitive.ObjectID, status string, q *ListQuery) (*ListPage, error)
+	RespondToOffer(studentID, offerID primitive.ObjectID, accept bool, reason string) (*models.Offer, error)
+	LapseExpiredOffers() (int, error)
+	AttachOfferLetter(offerID, companyID primitive.ObjectID, fileName string, r io.Reader, size int64, uploadedBy primitive.ObjectID) (*models.Offer, error)
+	OpenOfferLetter(offerID primitive.ObjectID, viewer *models.User) (*models.Attachment, io.ReadCloser, error)
+}
+
+
+type PlacementService interface {
+	DerivePlacement(ctx context.Context, studentID primitive.ObjectID) (*PlacementRecord, error)
+	SyncStudentPlacement(studentID primitive.ObjectID) (*PlacementRecord, error)
+	ReconcilePlacements(dryRun bool) (*ReconcileReport, error)
+}
+
+
+type AttachmentService interface {
+	AddJobAttachment(jobID primitive.ObjectID, companyID *primitive.ObjectID, fileName string, r io.Reader, size int64, uploadedBy primitive.ObjectID) (*models.Attachment, error)
+	DeleteJobAttachment(jobID, attachmentID primitive.ObjectID, companyID *primitive.ObjectID) error
+	OpenJobAttachment(jobID, attachmentID primitive.ObjectID, viewer *models.User) (*models.Attachment, io.ReadCloser, error)
+}
+
+
+type ResumeService interface {
+	UploadResume(studentID primitive.ObjectID, fileName, resumeName string, r io.Reader, size int64) (*models.Resume, error)
+	ListResumes(studentID primitive.ObjectID) ([]*ResumeSummary, error)
+	RenameResume(studentID, resumeID primitive.ObjectID, name string) (*models.Resume, error)
+	DeleteResume(studentID, resumeID primitive.ObjectID) error
+	SetPrimaryResume(studentID, resumeID primitive.ObjectID) error
+	OpenResume(studentID, resumeID primitive.ObjectID) (*models.Resume, io.ReadCloser, error)
+	GenerateResume(studentID primitive.ObjectID, template, resumeName string) (*models.Resume, error)
+}
+
+
+type ResumeSearchService interface {
+	SearchResumes(viewerID primitive.ObjectID, params *ResumeSearchParams) (*ListPage, error)
+}
+
+
+type ResumeParseService interface {
+	ParseResume(resumeID primitive.ObjectID) error
+	QueuePendingParses() (int, error)
+	GetParseStatus(studentID, resumeID primitive.ObjectID) (*ResumeParseStatus, error)
+	RetryParse(studentID, resumeID primitive.ObjectID) error
+}
+
+
+type ResumeExportService interface {
+	ExportCandidates(companyID, jobID primitive.ObjectID, statuses []string) (*ResumeExportSelection, error)
+	WriteResumeZip(ctx context.Context, w io.Writer, selection *ResumeExportSelection) (int, error)
+	QueueResumeExport(recruiterID primitive.ObjectID, selection *ResumeExportSelection) (*models.ResumeExport, error)
+	GetResumeExport(recruiterID, exportID primitive.ObjectID) (*models.ResumeExport, error)
+	OpenResumeExport(recruiterID, exportID primitive.ObjectID) (*models.ResumeExport, io.ReadCloser, error)
+	LogExportDownloads(recruiterID primitive.ObjectID, resumeIDs []primitive.ObjectID, ipAddress, userAgent string) error
+	DeleteExpiredResumeExports() (int, error)
+}
+
+
+type ResumeAccessService interface {
+	CreateResumeLink(resumeID, viewerID primitive.ObjectID) (*ResumeLink, error)
+	OpenSignedResume(resumeID primitive.ObjectID, link SignedResumeLink) (*ResumeFile, error)
+	ListResumeDownloads(studentID primitive.ObjectID, q *ListQuery) (*ListPage, error)
+}
+
+
+type MatchService interface {
+	ScoreApplication(studentID, jobID, resumeID primitive.ObjectID) (*models.MatchScore, error)
+	ScoreUnscoredApplications(jobIDs []primitive.ObjectID) (int, error)
+}
+
+
+type PPOService interface {
+	RecordPPOOutcome(companyID, jobID, studentID primitive.ObjectID, status, remarks string, updatedBy primitive.ObjectID) (*models.Application, error)
+	GetPPOStats(companyID *primitive.ObjectID) (*PPOStats, error)
+}
+
+
+type BookmarkService interface {
+	BookmarkJob(studentID, jobID primitive.ObjectID, remindBeforeHours int) (*models.Bookmark, bool, error)
+	RemoveBookmark(studentID, jobID primitive.ObjectID) error
+	ListBookmarks(studentID primitive.ObjectID, q *ListQuery) (*ListPage, error)
+	SendDeadlineReminders() (int, error)
+	ResetReminders(jobID primitive.ObjectID) error
+}
+
+
+type SavedSearchService interface {
+	ListSavedSearches(studentID primitive.ObjectID, q *ListQuery) (*ListPage, error)
+	CreateSavedSearch(search *models.SavedSearch) error
+	UpdateSavedSearch(studentID, searchID primitive.ObjectID, search *models.SavedSearch) (*models.SavedSearch, error)
+	DeleteSavedSearch(studentID, searchID primitive.ObjectID) error
+	AlertNewDrive(job *models.Job) (int, error)
+}
+
+
+type RecommendationService interface {
+	RecommendJobs(studentID primitive.ObjectID, limit int) ([]*JobRecommendation, int64, error)
+}
+
+
+type WaitlistService interface {
+	GetWaitlist(jobID primitive.ObjectID) ([]*models.Application, error)
+	ListWaitlist(jobID primitive.ObjectID, q *ListQuery) (*ListPage, error)
+	AddToWaitlist(jobID, studentID primitive.ObjectID, remarks string) (int, error)
+	ReorderWaitlist(jobID primitive.ObjectID, studentIDs []primitive.ObjectID) ([]*models.Application, error)
+	CompactRanks(jobID primitive.ObjectID) error
+	PromoteNext(jobID primitive.ObjectID) (*models.Application, error)
+	HandleVacancy(jobID primitive.ObjectID) (*models.Application, error)
+	SetAutoPromote(jobID primitive.ObjectID, enabled bool) error
+}
+
+
 type LoginResponse struct {
 	Token string    `json:"token"`
 	User  *UserInfo `json:"user"`
@@ -86,6 +253,65 @@ type StudentProfileResponse struct {
 	Skills     []string           `json:"skills"`
 	RollNumber *string            `json:"rollNumber"`
 	ResumeLink string             `json:"resumeLink"`
+
+	GraduationYear     int                    `json:"graduationYear,omitempty"`
+	PreferredLocations []string               `json:"preferredLocations"`
+	Education          []models.Education     `json:"education"`
+	Projects           []models.Project       `json:"projects"`
+	Qualifications     []models.Qualification `json:"qualifications"`
+}
+
+// ProfileUpdate holds the profile fields a student edits. Fields left out are
+// kept; lists that are sent replace the stored ones.
+type ProfileUpdate struct {
+	Skills             *[]string               `json:"skills"`
+	PreferredLocations *[]string               `json:"preferredLocations"`
+	GraduationYear     *int                    `json:"graduationYear"`
+	Education          *[]models.Education     `json:"education"`
+	Projects           *[]models.Project       `json:"projects"`
+	Qualifications     *[]models.Qualification `json:"qualifications"`
+}
+
+// JobRecommendation is an open drive ranked for a student. Reasons explain the
+// score in the order the signals were weighed.
+type JobRecommendation struct {
+	Job           *models.Job `json:"job"`
+	Score         float64     `json:"score"`
+	Eligible      bool        `json:"eligible"`
+	Reasons       []string    `json:"reasons"`
+	MatchedSkills []string    `json:"matchedSkills,omitempty"`
+	MissingSkills []string    `json:"missingSkills,omitempty"`
+}
+
+// PPOCounts breaks interns selected on internship drives down by PPO
+// outcome. Rates are percentages.
+type PPOCounts struct {
+	Interns        int64   `bson:"interns" json:"interns"`
+	PPOPossible    int64   `bson:"ppoPossible" json:"ppoPossible"`
+	Pending        int64   `bson:"pending" json:"pending"`
+	Offered        int64   `bson:"offered" json:"offered"`
+	NotOffered     int64   `bson:"notOffered" json:"notOffered"`
+	Accepted       int64   `bson:"accepted" json:"accepted"`
+	Declined       int64   `bson:"declined" json:"declined"`
+	ConversionRate float64 `bson:"-" json:"conversionRate"`
+	AcceptanceRate float64 `bson:"-" json:"acceptanceRate"`
+}
+
+type CompanyPPOStats struct {
+	CompanyID   primitive.ObjectID `bson:"_id" json:"companyId"`
+	CompanyName string             `bson:"companyName" json:"companyName"`
+	PPOCounts   `bson:",inline"`
+}
+
+type PPOStats struct {
+	PPOCounts
+	ByCompany []*CompanyPPOStats `json:"byCompany"`
+}
+
+type BookmarkedJob struct {
+	models.Bookmark `bson:",inline"`
+	Job             models.Job `bson:"job" json:"job"`
+	HasApplied      bool       `bson:"has_applied" json:"has_applied"`
 }
 
 type ApplicationResponse struct {
@@ -117,11 +343,6 @@ type UserProfile struct {
 	UpdatedAt      string                 `json:"updatedAt"`
 }
 
-type StudentsResponse struct {
-	Students   []*StudentInfo  `json:"students"`
-	Statistics *PlacementStats `json:"statistics"`
-}
-
 type StudentInfo struct {
 	ID              primitive.ObjectID `json:"id"`
 	FirstName       string             `json:"firstName"`
@@ -149,7 +370,8 @@ type JobResponse struct {
 	Requirements        []string           `json:"requirements"`
 	Salary              *float64           `json:"salary"`
 	Location            string             `json:"location"`
-	JobType             string             `json:"jobType"`
+	JobType             models.ProgramType        `json:"jobType"`
+	Internship          *models.InternshipDetails `json:"internship,omitempty"`
 	ApplicationDeadline string             `json:"applicationDeadline"`
 	PostedBy            primitive.ObjectID `json:"postedBy"`
 	CreatedAt           string             `json:"createdAt"`
@@ -167,8 +389,299 @@ type TPODashboardResponse struct {
 	Stats          gin.H  `json:"stats"`
 }
 
+type PolicyDecision struct {
+	Allowed      bool                `json:"allowed"`
+	PolicyID     *primitive.ObjectID `json:"policyId,omitempty"`
+	PolicyName   string              `json:"policyName,omitempty"`
+	AcademicYear string              `json:"academicYear,omitempty"`
+	JobTier      int                 `json:"jobTier"`
+	PlacedTier   int                 `json:"placedTier"`
+	OfferCount   int                 `json:"offerCount"`
+	Violations   []string            `json:"violations"`
+}
+
+type JobUpdate struct {
+	Position            *string             `json:"position"`
+	Description         *string             `json:"description"`
+	Location            *string             `json:"location"`
+	SalaryRange         *string             `json:"salary_range"`
+	JobType             *string             `json:"job_type"`
+	Internship          *models.InternshipDetails `json:"internship"`
+	ApplicationDeadline *time.Time          `json:"application_deadline"`
+	Eligibility         *models.Eligibility `json:"eligibility"`
+	Status              *string             `json:"status"`
+}
+
+type DriveCycle struct {
+	ApplicationDeadline time.Time `json:"application_deadline" binding:"required"`
+	Batch               []int     `json:"batch"`
+	GraduationYear      int       `json:"graduation_year"`
+}
+
+type JobSearchParams struct {
+	Query        string
+	Industries   []string
+	Locations    []string
+	JobTypes     []string
+	PackageBands []string
+	List         *ListQuery
+	// EligibleFor limits the results to drives the student is eligible for.
+	EligibleFor *models.User
+}
+
+type FacetCount struct {
+	Value string `bson:"_id" json:"value"`
+	Count int    `bson:"count" json:"count"`
+}
+
+type JobSearchHit struct {
+	Job         models.Job `json:"job"`
+	Industry    string     `json:"industry"`
+	PackageBand string     `json:"packageBand"`
+	Score       float64    `json:"score,omitempty"`
+	HasApplied  bool       `json:"has_applied"`
+}
+
+type JobSearchResult struct {
+	Jobs       []JobSearchHit
+	NextCursor *string
+	Total      int64
+	Facets     map[string][]FacetCount
+}
+
+type DriveImportOptions struct {
+	DryRun      bool
+	SkipInvalid bool
+}
+
+type DriveImportRow struct {
+	Row      int                 `json:"row"`
+	Position string              `json:"position"`
+	Company  string              `json:"company"`
+	Status   string              `json:"status"`
+	Errors   []string            `json:"errors,omitempty"`
+	Warnings []string            `json:"warnings,omitempty"`
+	DriveID  *primitive.ObjectID `json:"driveId,omitempty"`
+}
+
+type DriveImportReport struct {
+	DryRun      bool             `json:"dryRun"`
+	TotalRows   int              `json:"totalRows"`
+	ValidRows   int              `json:"validRows"`
+	InvalidRows int              `json:"invalidRows"`
+	Created     int              `json:"created"`
+	Rows        []DriveImportRow `json:"rows"`
+
+	// Drives holds the drives that were created, for follow-up work such as
+	// saved search alerts.
+	Drives []models.Job `json:"-"`
+}
+
+type JobUpdateResult struct {
+	Job                    *models.Job          `json:"drive"`
+	Version                int                  `json:"version"`
+	ChangedFields          []string             `json:"changedFields"`
+	MaterialChange         bool                 `json:"materialChange"`
+	Published              bool                 `json:"published"`
+	Notified               int64                `json:"notified"`
+	IneligibleApplications []primitive.ObjectID `json:"ineligibleApplications,omitempty"`
+}
+
+type PlacementRecord struct {
+	StudentID primitive.ObjectID `json:"studentId"`
+	Status    string             `json:"placedStatus"`
+	Company   string             `json:"company,omitempty"`
+	Role      string             `json:"role,omitempty"`
+	Salary    float64            `json:"salary,omitempty"`
+	Source    string             `json:"source,omitempty"`
+}
+
+type PlacementChange struct {
+	StudentID  primitive.ObjectID `json:"studentId"`
+	Email      string             `json:"email"`
+	FromStatus string             `json:"fromStatus"`
+	ToStatus   string             `json:"toStatus"`
+	Company    string             `json:"company,omitempty"`
+	Role       string             `json:"role,omitempty"`
+	Salary     float64            `json:"salary,omitempty"`
+}
+
+// ReconcileReport lists the placements reconciliation changed. Unverified
+// are students whose recorded placement differs from what the system
+// derives but who never applied or got an offer here, such as imported
+// placements; they are left as they are.
+type ReconcileReport struct {
+	DryRun     bool              `json:"dryRun"`
+	Checked    int               `json:"checked"`
+	Updated    int               `json:"updated"`
+	Changes    []PlacementChange `json:"changes"`
+	Unverified []PlacementChange `json:"unverified"`
+}
+
 type RecruiterDashboardResponse struct {
 
 	WelcomeMessage string `json:"welcomeMessage"`
 	Stats          gin.H  `json:"stats"`
 }
+
+type ResumeSummary struct {
+	*models.Resume
+	Primary bool `json:"primary"`
+}
+
+type ResumeParseStatus struct {
+	ResumeID   primitive.ObjectID   `json:"resume_id"`
+	Status     string               `json:"status"`
+	Error      string               `json:"error,omitempty"`
+	ParsedAt   *time.Time           `json:"parsed_at,omitempty"`
+	ParsedData *models.ParsedResume `json:"parsed_data,omitempty"`
+}
+
+type ResumeExportCandidate struct {
+	Student     *models.User
+	Application models.Application
+	Resume      *models.Resume
+	// FileName is the resume's name in the archive, set by WriteResumeZip
+	// when the resume could be added.
+	FileName string
+
+	resumeID primitive.ObjectID
+}
+
+type ResumeExportSelection struct {
+	Job        *models.Job
+	Statuses   []string
+	Candidates []*ResumeExportCandidate
+}
+
+type ResumeLink struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// SignedResumeLink is the query of a link from CreateResumeLink plus the
+// request details recorded in the download log.
+type SignedResumeLink struct {
+	ViewerID  string
+	Expires   string
+	Signature string
+	IPAddress string
+	UserAgent string
+}
+
+type ResumeFile struct {
+	Body        io.ReadCloser
+	FileName    string
+	ContentType string
+	Size        int64
+}
+
+type ResumeTemplateInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type ResumeSearchParams struct {
+	Query       string
+	Departments []string
+	Batches     []int
+	MinCGPA     *float64
+	MaxCGPA     *float64
+	List        *ListQuery
+}
+
+// ResumeSearchHit is a resume matching a search. Snippets are HTML escaped
+// with the matched terms in <mark> tags.
+type ResumeSearchHit struct {
+	ID            primitive.ObjectID  `bson:"_id" json:"resumeId"`
+	ResumeName    string              `bson:"resume_name" json:"resumeName"`
+	UploadedAt    time.Time           `bson:"uploaded_at" json:"uploadedAt"`
+	Score         int                 `bson:"score" json:"score"`
+	Student       ResumeSearchStudent `bson:"student" json:"student"`
+	Skills        []string            `bson:"skills" json:"skills"`
+	MatchedSkills []string            `bson:"-" json:"matchedSkills"`
+	Snippets      []string            `bson:"-" json:"snippets"`
+	LinkURL       string              `bson:"-" json:"linkUrl"`
+	OCRText       string              `bson:"ocr_text" json:"-"`
+}
+
+type ResumeSearchStudent struct {
+	ID             primitive.ObjectID `bson:"_id" json:"id"`
+	FirstName      string             `bson:"firstName" json:"firstName"`
+	LastName       string             `bson:"lastName" json:"lastName"`
+	Email          string             `bson:"email" json:"email"`
+	Department     *string            `bson:"department,omitempty" json:"department"`
+	RollNumber     *string            `bson:"rollNumber,omitempty" json:"rollNumber"`
+	CGPA           *float64           `bson:"cgpa,omitempty" json:"cgpa"`
+	GraduationYear int                `bson:"graduationYear,omitempty" json:"graduationYear,omitempty"`
+}
+
+type ResumeSearchSummary struct {
+	Terms []string `json:"terms"`
+}
+
+type SkillInput struct {
+	Name     string   `json:"name" binding:"required"`
+	Aliases  []string `json:"aliases"`
+	Category string   `json:"category" binding:"required"`
+}
+
+// SkillSuggestion is an autocomplete match. MatchedAlias is set when the
+// prefix matched an alias rather than the name.
+type SkillSuggestion struct {
+	Name         string `json:"name"`
+	Category     string `json:"category"`
+	MatchedAlias string `json:"matchedAlias,omitempty"`
+}
+
+type SkillUsage struct {
+	Skill string `json:"skill" bson:"skill"`
+	Count int    `json:"count" bson:"count"`
+}
+
+// SkillNormalizationReport counts the documents rewritten per collection and
+// lists the most used skills the taxonomy does not know.
+type SkillNormalizationReport struct {
+	Users        int64        `json:"users" bson:"users"`
+	Jobs         int64        `json:"jobs" bson:"jobs"`
+	Templates    int64        `json:"templates" bson:"templates"`
+	Resumes      int64        `json:"resumes" bson:"resumes"`
+	Applications int64        `json:"applications" bson:"applications"`
+	Unmapped     []SkillUsage `json:"unmapped" bson:"unmapped"`
+}
+
+type SkillGapParams struct {
+	Departments []string
+	Batches     []int
+	Months      int
+	Limit       int
+}
+
+// SkillGap compares how many drives ask for a skill with how many unplaced
+// students have it. Rates are percentages and Gap is their difference.
+type SkillGap struct {
+	Skill             string  `json:"skill"`
+	Category          string  `json:"category,omitempty"`
+	DrivesRequesting  int     `json:"drivesRequesting"`
+	DemandRate        float64 `json:"demandRate"`
+	StudentsWithSkill int     `json:"studentsWithSkill"`
+	StudentsLacking   int     `json:"studentsLacking"`
+	SupplyRate        float64 `json:"supplyRate"`
+	Gap               float64 `json:"gap"`
+}
+
+type SkillGapGroup struct {
+	Department string     `json:"department"`
+	Batch      int        `json:"batch,omitempty"`
+	Drives     int        `json:"drives"`
+	Students   int        `json:"students"`
+	Gaps       []SkillGap `json:"gaps"`
+}
+
+type SkillGapReport struct {
+	Since    time.Time        `json:"since"`
+	Drives   int              `json:"drives"`
+	Students int              `json:"students"`
+	Overall  []SkillGap       `json:"overall"`
+	Groups   []*SkillGapGroup `json:"groups"`
+}
@@ -0,0 +1,283 @@
+﻿package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	PackageBandUndisclosed = "Not disclosed"
+	PackageBandUnder5      = "<5 LPA"
+	PackageBand5To10       = "5-10 LPA"
+	PackageBand10To20      = "10-20 LPA"
+	PackageBand20Plus      = "20+ LPA"
+)
+
+var packageBands = map[string]bson.M{
+	PackageBandUndisclosed: {"$lte": 0},
+	PackageBandUnder5:      {"$gt": 0, "$lt": 5},
+	PackageBand5To10:       {"$gte": 5, "$lt": 10},
+	PackageBand10To20:      {"$gte": 10, "$lt": 20},
+	PackageBand20Plus:      {"$gte": 20},
+}
+
+// JobSearchListSpec sorts search results. "relevance" is only available when
+// the search has a text query.
+var JobSearchListSpec = ListSpec{
+	Sorts: map[string]string{
+		"relevance": "score",
+		"createdAt": "created_at",
+		"deadline":  "application_deadline",
+		"package":   "packageLPA",
+	},
+	DefaultSort: "-createdAt",
+}
+
+type JobSearchServiceImpl struct {
+	jobCollection *mongo.Collection
+}
+
+func NewJobSearchService(db *mongo.Database) JobSearchService {
+	return &JobSearchServiceImpl{
+		jobCollection: db.Collection("jobs"),
+	}
+}
+
+// EnsureJobSearchIndexes creates the weighted text index used for relevance
+// ranking and fills in package_lpa on drives created before it existed.
+func EnsureJobSearchIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	jobs := db.Collection("jobs")
+	_, err := jobs.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "position", Value: "text"},
+			{Key: "company_name.name", Value: "text"},
+			{Key: "eligibility.skills", Value: "text"},
+			{Key: "description", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("job_search_text").
+			SetWeights(bson.D{
+				{Key: "position", Value: 10},
+				{Key: "company_name.name", Value: 5},
+				{Key: "eligibility.skills", Value: 5},
+				{Key: "description", Value: 1},
+			}),
+	})
+	if err != nil {
+		return err
+	}
+
+	cursor, err := jobs.Find(ctx, bson.M{
+		"package_lpa":  bson.M{"$exists": false},
+		"salary_range": bson.M{"$nin": bson.A{nil, ""}},
+	}, options.Find().SetProjection(bson.M{"salary_range": 1}))
+	if err != nil {
+		return err
+	}
+	var missing []models.Job
+	if err = cursor.All(ctx, &missing); err != nil {
+		return err
+	}
+	for _, job := range missing {
+		if lpa := ParseSalaryLPA(job.SalaryRange); lpa > 0 {
+			if _, err := jobs.UpdateByID(ctx, job.ID, bson.M{"$set": bson.M{"package_lpa": lpa}}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (jss *JobSearchServiceImpl) SearchJobs(params *JobSearchParams) (*JobSearchResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	base := bson.M{"status": models.JobStatusOpen}
+	query := strings.TrimSpace(params.Query)
+	if query != "" {
+		base["$text"] = bson.M{"$search": query}
+	} else if params.List.Field == "score" {
+		return nil, newValidationError("sort", "Sorting by relevance needs a search query")
+	}
+	if params.EligibleFor != nil {
+		for key, value := range eligibilityFilter(params.EligibleFor) {
+			base[key] = value
+		}
+	}
+
+	facetFilters := map[string]bson.M{}
+	if len(params.Industries) > 0 {
+		facetFilters["industry"] = bson.M{"industry": bson.M{"$in": caseInsensitive(params.Industries)}}
+	}
+	if len(params.Locations) > 0 {
+		facetFilters["location"] = bson.M{"locationFacet": bson.M{"$in": caseInsensitive(params.Locations)}}
+	}
+	if len(params.JobTypes) > 0 {
+		facetFilters["jobType"] = bson.M{"jobType": bson.M{"$in": lowerAll(params.JobTypes)}}
+	}
+	if len(params.PackageBands) > 0 {
+		var bands []bson.M
+		for _, band := range params.PackageBands {
+			if cond, ok := packageBands[band]; ok {
+				bands = append(bands, bson.M{"packageLPA": cond})
+			}
+		}
+		if len(bands) == 0 {
+			return nil, newValidationError("package", "Unknown package band")
+		}
+		facetFilters["packageBand"] = bson.M{"$or": bands}
+	}
+
+	// Every facet is counted with all filters except its own, so picking one
+	// location still shows how many results the other locations would give.
+	except := func(skip string) bson.M {
+		var and []bson.M
+		for name, filter := range facetFilters {
+			if name != skip {
+				and = append(and, filter)
+			}
+		}
+		if len(and) == 0 {
+			return bson.M{}
+		}
+		return bson.M{"$and": and}
+	}
+	facetGroup := func(name, field string) []bson.M {
+		return []bson.M{
+			{"$match": except(name)},
+			{"$group": bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}},
+			{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		}
+	}
+
+	addFields := bson.M{
+		"industry":      bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$company.industry", 0}}, "Unknown"}},
+		"locationFacet": bson.M{"$ifNull": bson.A{"$location", "Unknown"}},
+		"jobType":       bson.M{"$ifNull": bson.A{"$job_type", models.JobTypeFullTime}},
+		"packageLPA":    bson.M{"$ifNull": bson.A{"$package_lpa", 0}},
+	}
+	if query != "" {
+		addFields["score"] = bson.M{"$meta": "textScore"}
+	}
+
+	pipeline := []bson.M{
+		{"$match": base},
+		{"$lookup": bson.M{
+			"from":         "companies",
+			"localField":   "company_name.companyId",
+			"foreignField": "_id",
+			"as":           "company",
+		}},
+		{"$addFields": addFields},
+		{"$addFields": bson.M{"packageBand": bson.M{"$switch": bson.M{
+			"branches": bson.A{
+				bson.M{"case": bson.M{"$lte": bson.A{"$packageLPA", 0}}, "then": PackageBandUndisclosed},
+				bson.M{"case": bson.M{"$lt": bson.A{"$packageLPA", 5}}, "then": PackageBandUnder5},
+				bson.M{"case": bson.M{"$lt": bson.A{"$packageLPA", 10}}, "then": PackageBand5To10},
+				bson.M{"case": bson.M{"$lt": bson.A{"$packageLPA", 20}}, "then": PackageBand10To20},
+			},
+			"default": PackageBand20Plus,
+		}}}},
+		{"$project": bson.M{"company": 0}},
+		{"$facet": bson.M{
+			"results":     append([]bson.M{{"$match": except("")}}, params.List.Stages()...),
+			"total":       []bson.M{{"$match": except("")}, {"$count": "count"}},
+			"industry":    facetGroup("industry", "industry"),
+			"location":    facetGroup("location", "locationFacet"),
+			"jobType":     facetGroup("jobType", "jobType"),
+			"packageBand": facetGroup("packageBand", "packageBand"),
+		}},
+	}
+
+	cursor, err := jss.jobCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var raw []struct {
+		Results []searchHit `bson:"results"`
+		Total   []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+		Industry    []FacetCount `bson:"industry"`
+		Location    []FacetCount `bson:"location"`
+		JobType     []FacetCount `bson:"jobType"`
+		PackageBand []FacetCount `bson:"packageBand"`
+	}
+	if err = cursor.All(ctx, &raw); err != nil {
+		return nil, err
+	}
+
+	result := &JobSearchResult{
+		Jobs: []JobSearchHit{},
+		Facets: map[string][]FacetCount{
+			"industry":    {},
+			"location":    {},
+			"jobType":     {},
+			"packageBand": {},
+		},
+	}
+	if len(raw) == 0 {
+		return result, nil
+	}
+
+	facets := raw[0]
+	hits, next, err := Paginate(params.List, facets.Results)
+	if err != nil {
+		return nil, err
+	}
+	result.NextCursor = next
+	for _, hit := range hits {
+		result.Jobs = append(result.Jobs, JobSearchHit{
+			Job:         hit.Job,
+			Industry:    hit.Industry,
+			PackageBand: hit.PackageBand,
+			Score:       hit.Score,
+		})
+	}
+	if len(facets.Total) > 0 {
+		result.Total = facets.Total[0].Count
+	}
+	result.Facets["industry"] = append(result.Facets["industry"], facets.Industry...)
+	result.Facets["location"] = append(result.Facets["location"], facets.Location...)
+	result.Facets["jobType"] = append(result.Facets["jobType"], facets.JobType...)
+	result.Facets["packageBand"] = append(result.Facets["packageBand"], facets.PackageBand...)
+	return result, nil
+}
+
+type searchHit struct {
+	models.Job  `bson:",inline"`
+	Industry    string  `bson:"industry"`
+	PackageBand string  `bson:"packageBand"`
+	PackageLPA  float64 `bson:"packageLPA"`
+	Score       float64 `bson:"score"`
+}
+
+func caseInsensitive(values []string) bson.A {
+	out := bson.A{}
+	for _, v := range values {
+		out = append(out, primitive.Regex{Pattern: "^" + regexp.QuoteMeta(strings.TrimSpace(v)) + "$", Options: "i"})
+	}
+	return out
+}
+
+func lowerAll(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		out = append(out, strings.ToLower(strings.TrimSpace(v)))
+	}
+	return out
+}
@@ -3,18 +3,29 @@
 import (
 	"backend/models"
 	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type JobServiceImpl struct {
 	jobCollection         *mongo.Collection
 	userCollection        *mongo.Collection
 	applicationCollection *mongo.Collection
+	companyCollection     *mongo.Collection
+	versionCollection     *mongo.Collection
+
+	waitlistService WaitlistService
+	bookmarkService BookmarkService
+	skills          *skillTaxonomy
 }
 
 func NewJobService(db *mongo.Database) JobService {
@@ -22,35 +33,57 @@ func NewJobService(db *mongo.Database) JobService {
 		jobCollection:         db.Collection("jobs"),
 		userCollection:        db.Collection("users"),
 		applicationCollection: db.Collection("applications"),
+		companyCollection:     db.Collection("companies"),
+		versionCollection:     db.Collection("job_versions"),
+
+		waitlistService: NewWaitlistService(db),
+		bookmarkService: NewBookmarkService(db),
+		skills:          newSkillTaxonomy(db),
 	}
 }
 
-func (js *JobServiceImpl) GetAvailableJobs(page, limit int) ([]*JobResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+var JobListSpec = ListSpec{
+	Sorts: map[string]string{
+		"createdAt": "created_at",
+		"deadline":  "application_deadline",
+		"position":  "position",
+		"package":   "package_lpa",
+	},
+	DefaultSort: "-createdAt",
+}
 
-	skip := (page - 1) * limit
+// DriveListSpec sorts the TPO, admin and recruiter drive listings, which
+// carry an applicantCount next to the drive fields.
+var DriveListSpec = ListSpec{
+	Sorts: map[string]string{
+		"createdAt":  "created_at",
+		"deadline":   "application_deadline",
+		"position":   "position",
+		"company":    "company_name.name",
+		"applicants": "applicantCount",
+	},
+	DefaultSort: "-createdAt",
+}
 
+var ApplicationListSpec = ListSpec{
+	Sorts: map[string]string{
+		"appliedOn": "applied_on",
+		"status":    "status",
+	},
+	DefaultSort: "-appliedOn",
+}
 
-	findOptions := options.Find()
-	findOptions.SetSkip(int64(skip))
-	findOptions.SetLimit(int64(limit))
-	findOptions.SetSort(bson.D{{Key: "createdAt", Value: -1}})
+func (js *JobServiceImpl) GetAvailableJobs(q *ListQuery) (*ListPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	cursor, err := js.jobCollection.Find(ctx, bson.M{}, findOptions)
+	page, found, err := FindPage[models.Job](ctx, js.jobCollection, bson.M{"status": models.JobStatusOpen}, q)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
-
-	var jobs []*JobResponse
-	for cursor.Next(ctx) {
-		var job models.Job
-		if err := cursor.Decode(&job); err != nil {
-			continue
-		}
 
+	jobs := make([]*JobResponse, 0, len(found))
+	for _, job := range found {
 		jobs = append(jobs, &JobResponse{
 			ID:                  job.ID,
 			Position:            job.Position,
@@ -59,14 +92,16 @@ func (js *JobServiceImpl) GetAvailableJobs(page, limit int) ([]*JobResponse, err
 			Requirements:        job.Eligibility.Skills,
 			Salary:              nil,
 			Location:            job.Location,
-			JobType:             job.Status,
+			JobType:             job.JobType,
+			Internship:          job.Internship,
 			ApplicationDeadline: job.ApplicationDeadline.Format(time.RFC3339),
 			PostedBy:            job.PostedBy,
 			CreatedAt:           job.CreatedAt.Format(time.RFC3339),
 		})
 	}
 
-	return jobs, nil
+	page.Items = jobs
+	return page, nil
 }
 
 func (js *JobServiceImpl) GetJobByID(jobID primitive.ObjectID) (*JobResponse, error) {
@@ -87,7 +122,8 @@ func (js *JobServiceImpl) GetJobByID(jobID primitive.ObjectID) (*JobResponse, er
 		Requirements:        job.Eligibility.Skills,
 		Salary:              nil,
 		Location:            job.Location,
-		JobType:             job.Status,
+		JobType:             job.JobType,
+		Internship:          job.Internship,
 		ApplicationDeadline: job.ApplicationDeadline.Format(time.RFC3339),
 		PostedBy:            job.PostedBy,
 		CreatedAt:           job.CreatedAt.Format(time.RFC3339),
@@ -126,6 +162,10 @@ func (js *JobServiceImpl) CreateJob(job *models.Job) (*primitive.ObjectID, error
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if err := js.validateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
 	job.ID = primitive.NewObjectID()
 	job.CreatedAt = time.Now()
 
@@ -137,3 +177,513 @@ func (js *JobServiceImpl) CreateJob(job *models.Job) (*primitive.ObjectID, error
 	insertedID := result.InsertedID.(primitive.ObjectID)
 	return &insertedID, nil
 }
+
+func (js *JobServiceImpl) UpdateJob(jobID primitive.ObjectID, update *JobUpdate, editedBy primitive.ObjectID) (*JobUpdateResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var current models.Job
+	if err := js.jobCollection.FindOne(ctx, bson.M{"_id": jobID}).Decode(&current); err != nil {
+		return nil, err
+	}
+
+	edited := current
+	set := bson.M{}
+	var changed []string
+
+	setText := func(field string, value *string, target *string, label string) error {
+		if value == nil {
+			return nil
+		}
+		v := strings.TrimSpace(*value)
+		if v == "" && field != "salary_range" {
+			return newValidationError(field, label+" cannot be empty")
+		}
+		if v != *target {
+			*target = v
+			set[field] = v
+			changed = append(changed, field)
+		}
+		return nil
+	}
+	if err := setText("position", update.Position, &edited.Position, "Position"); err != nil {
+		return nil, err
+	}
+	if err := setText("description", update.Description, &edited.Description, "Job description"); err != nil {
+		return nil, err
+	}
+	if err := setText("location", update.Location, &edited.Location, "Job location"); err != nil {
+		return nil, err
+	}
+	if err := setText("salary_range", update.SalaryRange, &edited.SalaryRange, "Salary range"); err != nil {
+		return nil, err
+	}
+	if _, ok := set["salary_range"]; ok {
+		edited.PackageLPA = ParseSalaryLPA(edited.SalaryRange)
+		set["package_lpa"] = edited.PackageLPA
+	}
+
+	if update.JobType != nil {
+		jobType := models.ProgramType(strings.ToLower(strings.TrimSpace(*update.JobType)))
+		if !validJobType(jobType) {
+			return nil, newValidationError("job_type", "Job type must be one of 'full-time', 'internship', 'part-time' or 'contract'")
+		}
+		if jobType != current.JobType {
+			edited.JobType = jobType
+			set["job_type"] = jobType
+			changed = append(changed, "job_type")
+		}
+	}
+	if update.Internship != nil || edited.JobType != current.JobType {
+		if update.Internship != nil {
+			internship := *update.Internship
+			edited.Internship = &internship
+		}
+		if err := validateInternship(&edited); err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(edited.Internship, current.Internship) {
+			set["internship"] = edited.Internship
+			changed = append(changed, "internship")
+		}
+	}
+
+	if update.ApplicationDeadline != nil && !update.ApplicationDeadline.Equal(current.ApplicationDeadline) {
+		if update.ApplicationDeadline.Before(time.Now()) {
+			return nil, newValidationError("application_deadline", "Application deadline must be in the future")
+		}
+		edited.ApplicationDeadline = *update.ApplicationDeadline
+		set["application_deadline"] = edited.ApplicationDeadline
+		changed = append(changed, "application_deadline")
+	}
+
+	if update.Status != nil && *update.Status != current.Status {
+		if *update.Status != models.JobStatusOpen && *update.Status != models.JobStatusClosed {
+			return nil, newValidationError("status", "Status must be either 'open' or 'closed'")
+		}
+		edited.Status = *update.Status
+		set["status"] = edited.Status
+		changed = append(changed, "status")
+	}
+
+	if update.Eligibility != nil {
+		eligibility := *update.Eligibility
+		if err := normalizeEligibility(&eligibility); err != nil {
+			return nil, err
+		}
+		skills, err := js.skills.normalize(ctx, eligibility.Skills)
+		if err != nil {
+			return nil, err
+		}
+		eligibility.Skills = skills
+		if !reflect.DeepEqual(eligibility, current.Eligibility) {
+			edited.Eligibility = eligibility
+			set["eligibility"] = eligibility
+			changed = append(changed, "eligibility")
+		}
+	}
+
+	version := current.Version
+	if version == 0 {
+		version = 1
+	}
+	result := &JobUpdateResult{Job: &current, Version: version, ChangedFields: []string{}}
+	if len(changed) == 0 {
+		return result, nil
+	}
+
+	now := time.Now()
+	versionID := primitive.NewObjectID()
+	_, err := js.versionCollection.InsertOne(ctx, models.JobVersion{
+		ID:            versionID,
+		JobID:         current.ID,
+		Version:       version,
+		Snapshot:      current,
+		ChangedFields: changed,
+		EditedBy:      editedBy,
+		EditedAt:      now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	edited.Version = version + 1
+	edited.UpdatedAt = now
+	set["version"] = edited.Version
+	set["updated_at"] = now
+	updated, err := js.jobCollection.UpdateOne(ctx,
+		bson.M{"_id": current.ID, "version": bson.M{"$in": bson.A{current.Version, nil}}},
+		bson.M{"$set": set},
+	)
+	if err != nil || updated.MatchedCount == 0 {
+		// The version was recorded for an edit that did not happen.
+		if _, delErr := js.versionCollection.DeleteOne(ctx, bson.M{"_id": versionID}); delErr != nil {
+			log.Printf("Drive %s: could not remove version %d of a failed edit: %v", current.ID.Hex(), version, delErr)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, newValidationError("version", "The drive was modified by someone else, reload and try again")
+	}
+
+	if !edited.ApplicationDeadline.Equal(current.ApplicationDeadline) {
+		if err := js.bookmarkService.ResetReminders(current.ID); err != nil {
+			log.Printf("Drive %s: could not reset bookmark reminders after a deadline change: %v", current.ID.Hex(), err)
+		}
+	}
+
+	// Match scores were for the old skills; applicants without one are
+	// rescored when the drive's applicants are next listed.
+	if !reflect.DeepEqual(edited.Eligibility.Skills, current.Eligibility.Skills) {
+		if _, err := js.applicationCollection.UpdateMany(ctx,
+			bson.M{"job_id": current.ID, "match": bson.M{"$exists": true}},
+			bson.M{"$unset": bson.M{"match": ""}},
+		); err != nil {
+			log.Printf("Drive %s: could not clear match scores after a skills change: %v", current.ID.Hex(), err)
+		}
+	}
+
+	result.Job = &edited
+	result.Version = edited.Version
+	result.ChangedFields = changed
+	result.Published = current.Status == models.JobStatusDraft && edited.Status == models.JobStatusOpen
+
+	if eligibilityTightened(current.Eligibility, edited.Eligibility) {
+		ineligible, err := js.reevaluateApplications(ctx, &edited)
+		if err != nil {
+			return nil, err
+		}
+		result.IneligibleApplications = ineligible
+	}
+
+	var material []string
+	for _, field := range changed {
+		if label, ok := materialDriveFields[field]; ok {
+			material = append(material, label)
+		}
+	}
+	if len(material) > 0 {
+		result.MaterialChange = true
+		ids, err := js.applicationCollection.Distinct(ctx, "student_id", bson.M{
+			"job_id": edited.ID,
+			"status": bson.M{"$nin": []string{models.ApplicationStatusRejected, models.ApplicationStatusIneligible}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		studentIDs := make([]primitive.ObjectID, 0, len(ids))
+		for _, id := range ids {
+			if oid, ok := id.(primitive.ObjectID); ok {
+				studentIDs = append(studentIDs, oid)
+			}
+		}
+		result.Notified, _ = pushNotification(ctx, js.userCollection, studentIDs,
+			"Drive updated: "+edited.Position,
+			fmt.Sprintf("The %s drive at %s you applied to has been updated. Changed: %s. Please review the latest details.", edited.Position, edited.CompanyName.Name, strings.Join(material, ", ")),
+		)
+	}
+
+	return result, nil
+}
+
+var materialDriveFields = map[string]string{
+	"application_deadline": "application deadline",
+	"eligibility":          "eligibility criteria",
+	"internship":           "internship terms",
+	"salary_range":         "compensation",
+	"location":             "location",
+	"position":             "role",
+}
+
+// reevaluateApplications marks in-progress applications that no longer meet
+// the drive's eligibility as ineligible and tells the affected students why.
+// Selected candidates are left alone.
+func (js *JobServiceImpl) reevaluateApplications(ctx context.Context, job *models.Job) ([]primitive.ObjectID, error) {
+	cursor, err := js.applicationCollection.Find(ctx, bson.M{
+		"job_id": job.ID,
+		"status": bson.M{"$in": []string{
+			models.ApplicationStatusApplied,
+			models.ApplicationStatusShortlisted,
+			models.ApplicationStatusInterviewed,
+			models.ApplicationStatusWaitlisted,
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var applications []models.Application
+	if err = cursor.All(ctx, &applications); err != nil {
+		return nil, err
+	}
+
+	ineligible := []primitive.ObjectID{}
+	waitlistChanged := false
+	for _, app := range applications {
+		var student models.User
+		if err := js.userCollection.FindOne(ctx, bson.M{"_id": app.StudentID}).Decode(&student); err != nil {
+			continue
+		}
+		reasons := eligibilityFailures(&student, job.Eligibility)
+		if len(reasons) == 0 {
+			continue
+		}
+
+		_, err := js.applicationCollection.UpdateByID(ctx, app.ID, bson.M{
+			"$set": bson.M{
+				"status":     models.ApplicationStatusIneligible,
+				"remarks":    "No longer eligible: " + strings.Join(reasons, "; "),
+				"updated_on": time.Now(),
+			},
+			"$unset": bson.M{"waitlist_rank": ""},
+		})
+		if err != nil {
+			return ineligible, err
+		}
+		ineligible = append(ineligible, app.ID)
+		if app.Status == models.ApplicationStatusWaitlisted {
+			waitlistChanged = true
+		}
+
+		pushNotification(ctx, js.userCollection, []primitive.ObjectID{student.ID},
+			"Application no longer eligible",
+			fmt.Sprintf("The eligibility criteria for the %s drive at %s have changed and your application no longer qualifies: %s.", job.Position, job.CompanyName.Name, strings.Join(reasons, "; ")),
+		)
+	}
+
+	if waitlistChanged {
+		if err := js.waitlistService.CompactRanks(job.ID); err != nil {
+			return ineligible, err
+		}
+	}
+	return ineligible, nil
+}
+
+var JobVersionListSpec = ListSpec{
+	Sorts:       map[string]string{"version": "version"},
+	DefaultSort: "-version",
+}
+
+func (js *JobServiceImpl) GetJobVersions(jobID primitive.ObjectID, q *ListQuery) (*ListPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	page, _, err := FindPage[*models.JobVersion](ctx, js.versionCollection, bson.M{"job_id": jobID}, q)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// CloneJob copies a drive into a new draft for another recruitment cycle.
+// Applications, versions and waitlists are not carried over.
+func (js *JobServiceImpl) CloneJob(jobID primitive.ObjectID, cycle *DriveCycle, createdBy primitive.ObjectID) (*models.Job, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var source models.Job
+	if err := js.jobCollection.FindOne(ctx, bson.M{"_id": jobID}).Decode(&source); err != nil {
+		return nil, err
+	}
+
+	clone := &models.Job{
+		CompanyName:         source.CompanyName,
+		Position:            source.Position,
+		Description:         source.Description,
+		Eligibility:         source.Eligibility,
+		SalaryRange:         source.SalaryRange,
+		PackageLPA:          source.PackageLPA,
+		JobType:             source.JobType,
+		Internship:          source.Internship,
+		Location:            source.Location,
+		AutoPromoteWaitlist: source.AutoPromoteWaitlist,
+	}
+	if err := js.CreateDraft(clone, cycle, createdBy); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// CreateDraft applies the new cycle's deadline and batch to job and stores it
+// as a draft through the normal validation path.
+func (js *JobServiceImpl) CreateDraft(job *models.Job, cycle *DriveCycle, createdBy primitive.ObjectID) error {
+	job.ApplicationDeadline = cycle.ApplicationDeadline
+	if cycle.Batch != nil {
+		job.Eligibility.Batch = cycle.Batch
+	}
+	if cycle.GraduationYear != 0 {
+		job.Eligibility.GraduationYear = cycle.GraduationYear
+	}
+	job.Status = models.JobStatusDraft
+	job.PostedBy = createdBy
+
+	_, err := js.CreateJob(job)
+	return err
+}
+
+// validateJob is the single validation path for drives, whoever creates them.
+// It trims free text, links the drive to a registered company and normalises
+// the eligibility block in place.
+func (js *JobServiceImpl) validateJob(ctx context.Context, job *models.Job) error {
+	job.Position = strings.TrimSpace(job.Position)
+	job.Description = strings.TrimSpace(job.Description)
+	job.Location = strings.TrimSpace(job.Location)
+	job.SalaryRange = strings.TrimSpace(job.SalaryRange)
+	job.CompanyName.Name = strings.TrimSpace(job.CompanyName.Name)
+
+	if job.Position == "" {
+		return newValidationError("position", "Position is required")
+	}
+	if job.Description == "" {
+		return newValidationError("description", "Job description is required")
+	}
+	if job.Location == "" {
+		return newValidationError("location", "Job location is required")
+	}
+
+	if err := js.linkCompany(ctx, &job.CompanyName); err != nil {
+		return err
+	}
+
+	if job.ApplicationDeadline.IsZero() {
+		return newValidationError("application_deadline", "Application deadline is required")
+	}
+	if job.ApplicationDeadline.Before(time.Now()) {
+		return newValidationError("application_deadline", "Application deadline must be in the future")
+	}
+
+	switch job.Status {
+	case "":
+		job.Status = models.JobStatusOpen
+	case models.JobStatusDraft, models.JobStatusOpen, models.JobStatusClosed:
+	default:
+		return newValidationError("status", "Status must be one of 'draft', 'open' or 'closed'")
+	}
+
+	job.JobType = models.ProgramType(strings.ToLower(strings.TrimSpace(string(job.JobType))))
+	if job.JobType == "" {
+		job.JobType = models.JobTypeFullTime
+	}
+	if !validJobType(job.JobType) {
+		return newValidationError("job_type", "Job type must be one of 'full-time', 'internship', 'part-time' or 'contract'")
+	}
+	if err := validateInternship(job); err != nil {
+		return err
+	}
+	if job.PackageLPA < 0 {
+		return newValidationError("package_lpa", "Package cannot be negative")
+	}
+	if job.PackageLPA == 0 {
+		job.PackageLPA = ParseSalaryLPA(job.SalaryRange)
+	}
+
+	if err := normalizeEligibility(&job.Eligibility); err != nil {
+		return err
+	}
+	skills, err := js.skills.normalize(ctx, job.Eligibility.Skills)
+	if err != nil {
+		return err
+	}
+	job.Eligibility.Skills = skills
+	return nil
+}
+
+func validJobType(jobType models.ProgramType) bool {
+	switch jobType {
+	case models.JobTypeFullTime, models.JobTypeInternship, models.JobTypePartTime, models.JobTypeContract:
+		return true
+	}
+	return false
+}
+
+// validateInternship requires internship details on internship drives and
+// drops them from every other program type.
+func validateInternship(job *models.Job) error {
+	if job.JobType != models.JobTypeInternship {
+		job.Internship = nil
+		return nil
+	}
+	if job.Internship == nil || job.Internship.DurationMonths == 0 {
+		return newValidationError("internship.duration_months", "Internship drives need a duration in months")
+	}
+	if job.Internship.DurationMonths < 1 || job.Internship.DurationMonths > 24 {
+		return newValidationError("internship.duration_months", "Internship duration must be between 1 and 24 months")
+	}
+	if job.Internship.StipendPerMonth < 0 {
+		return newValidationError("internship.stipend_per_month", "Stipend cannot be negative")
+	}
+	return nil
+}
+
+func (js *JobServiceImpl) linkCompany(ctx context.Context, company *models.EmbeddedCompany) error {
+	var found models.Company
+	var err error
+	if !company.CompanyID.IsZero() {
+		err = js.companyCollection.FindOne(ctx, bson.M{"_id": company.CompanyID}).Decode(&found)
+	} else if company.Name != "" {
+		err = js.companyCollection.FindOne(ctx, bson.M{
+			"name": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(company.Name) + "$", Options: "i"},
+		}).Decode(&found)
+	} else {
+		return newValidationError("company_name", "Company is required")
+	}
+
+	if err == mongo.ErrNoDocuments {
+		return newValidationError("company_name", "Company not found - register the company before creating a drive")
+	}
+	if err != nil {
+		return err
+	}
+
+	company.CompanyID = found.ID
+	company.Name = found.Name
+	return nil
+}
+
+func normalizeEligibility(e *models.Eligibility) error {
+	if e.MinCGPA < 0 || e.MinCGPA > 10 {
+		return newValidationError("eligibility.min_cgpa", "Minimum CGPA must be between 0 and 10")
+	}
+	if e.MaxBacklogs < 0 {
+		return newValidationError("eligibility.max_backlogs", "Maximum backlogs cannot be negative")
+	}
+
+	currentYear := time.Now().Year()
+	if e.GraduationYear != 0 && (e.GraduationYear < currentYear || e.GraduationYear > currentYear+10) {
+		return newValidationError("eligibility.graduation_year", fmt.Sprintf("Graduation year must be between %d and %d", currentYear, currentYear+10))
+	}
+
+	e.Skills = dedupeStrings(e.Skills)
+	e.Course = dedupeStrings(e.Course)
+
+	seen := make(map[int]bool, len(e.Batch))
+	batch := make([]int, 0, len(e.Batch))
+	for _, year := range e.Batch {
+		if year < 2000 || year > currentYear+10 {
+			return newValidationError("eligibility.batch", fmt.Sprintf("Batch years must be between 2000 and %d", currentYear+10))
+		}
+		if !seen[year] {
+			seen[year] = true
+			batch = append(batch, year)
+		}
+	}
+	sort.Ints(batch)
+	e.Batch = batch
+	return nil
+}
+
+// dedupeStrings trims values and drops blanks and case-insensitive repeats,
+// keeping the first spelling seen.
+func dedupeStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		key := strings.ToLower(v)
+		if v == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, v)
+	}
+	return out
+}
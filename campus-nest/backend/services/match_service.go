@@ -0,0 +1,167 @@
+﻿package services
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// A required skill found on the resume the student applied with counts
+// fully; one only claimed on the profile counts for this much.
+const profileOnlySkillWeight = 0.5
+
+type MatchServiceImpl struct {
+	jobCollection         *mongo.Collection
+	userCollection        *mongo.Collection
+	resumeCollection      *mongo.Collection
+	applicationCollection *mongo.Collection
+}
+
+func NewMatchService(db *mongo.Database) MatchService {
+	return &MatchServiceImpl{
+		jobCollection:         db.Collection("jobs"),
+		userCollection:        db.Collection("users"),
+		resumeCollection:      db.Collection("resumes"),
+		applicationCollection: db.Collection("applications"),
+	}
+}
+
+// ScoreApplication scores a student applying to a drive with a resume.
+func (ms *MatchServiceImpl) ScoreApplication(studentID, jobID, resumeID primitive.ObjectID) (*models.MatchScore, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var job models.Job
+	if err := ms.jobCollection.FindOne(ctx, bson.M{"_id": jobID}).Decode(&job); err != nil {
+		return nil, err
+	}
+	var student models.User
+	if err := ms.userCollection.FindOne(ctx, bson.M{"_id": studentID}).Decode(&student); err != nil {
+		return nil, err
+	}
+	resumeSkills, err := ms.resumeSkills(ctx, resumeID)
+	if err != nil {
+		return nil, err
+	}
+	return computeMatch(job.Eligibility.Skills, resumeSkills, student.Skills), nil
+}
+
+func (ms *MatchServiceImpl) resumeSkills(ctx context.Context, resumeID primitive.ObjectID) ([]string, error) {
+	if resumeID.IsZero() {
+		return nil, nil
+	}
+	var resume models.Resume
+	err := ms.resumeCollection.FindOne(ctx, bson.M{"_id": resumeID},
+		options.FindOne().SetProjection(bson.M{"parsed_data.skills": 1})).Decode(&resume)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resume.ParsedData.Skills, nil
+}
+
+// ScoreUnscoredApplications scores applications to the drives that have no
+// match yet: those from before scoring existed, those whose resume was
+// parsed after they were submitted, and those cleared when the drive's
+// skills changed.
+func (ms *MatchServiceImpl) ScoreUnscoredApplications(jobIDs []primitive.ObjectID) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if len(jobIDs) == 0 {
+		return 0, nil
+	}
+	cursor, err := ms.applicationCollection.Find(ctx,
+		bson.M{"job_id": bson.M{"$in": jobIDs}, "match": bson.M{"$exists": false}},
+		options.Find().SetLimit(1000))
+	if err != nil {
+		return 0, err
+	}
+	var applications []models.Application
+	if err := cursor.All(ctx, &applications); err != nil {
+		return 0, err
+	}
+
+	jobs := map[primitive.ObjectID]*models.Job{}
+	students := map[primitive.ObjectID]*models.User{}
+	scored := 0
+	for _, application := range applications {
+		job, ok := jobs[application.JobID]
+		if !ok {
+			job = &models.Job{}
+			if err := ms.jobCollection.FindOne(ctx, bson.M{"_id": application.JobID}).Decode(job); err != nil {
+				return scored, err
+			}
+			jobs[application.JobID] = job
+		}
+		student, ok := students[application.StudentID]
+		if !ok {
+			student = &models.User{}
+			err := ms.userCollection.FindOne(ctx, bson.M{"_id": application.StudentID}).Decode(student)
+			if err == mongo.ErrNoDocuments {
+				continue
+			}
+			if err != nil {
+				return scored, err
+			}
+			students[application.StudentID] = student
+		}
+		resumeSkills, err := ms.resumeSkills(ctx, application.ResumeID)
+		if err != nil {
+			return scored, err
+		}
+
+		// Score what was submitted rather than skills added since.
+		profileSkills := student.Skills
+		if application.Snapshot != nil {
+			profileSkills = application.Snapshot.Skills
+		}
+		match := computeMatch(job.Eligibility.Skills, resumeSkills, profileSkills)
+		if _, err := ms.applicationCollection.UpdateByID(ctx, application.ID, bson.M{"$set": bson.M{"match": match}}); err != nil {
+			return scored, err
+		}
+		scored++
+	}
+	return scored, nil
+}
+
+// computeMatch scores how many of the required skills the applicant has, out
+// of 100. A drive without required skills matches everyone fully.
+func computeMatch(required, resumeSkills, profileSkills []string) *models.MatchScore {
+	match := &models.MatchScore{
+		Score:         100,
+		MatchedSkills: []string{},
+		MissingSkills: []string{},
+		ScoredAt:      time.Now(),
+	}
+	required = dedupeStrings(required)
+	if len(required) == 0 {
+		return match
+	}
+
+	var points float64
+	for _, skill := range required {
+		switch {
+		case containsFold(resumeSkills, skill):
+			match.MatchedSkills = append(match.MatchedSkills, skill)
+			points++
+		case containsFold(profileSkills, skill):
+			match.MatchedSkills = append(match.MatchedSkills, skill)
+			match.ProfileOnlySkills = append(match.ProfileOnlySkills, skill)
+			points += profileOnlySkillWeight
+		default:
+			match.MissingSkills = append(match.MissingSkills, skill)
+		}
+	}
+	match.Score = math.Round(points/float64(len(required))*1000) / 10
+	return match
+}
@@ -0,0 +1,69 @@
+﻿package services
+
+import (
+	"context"
+	"time"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var NotificationListSpec = ListSpec{
+	Sorts:       map[string]string{"createdAt": "createdAt"},
+	DefaultSort: "-createdAt",
+}
+
+// ListNotifications pages through the notifications embedded in the user
+// document matched by filter.
+func ListNotifications(ctx context.Context, userCollection *mongo.Collection, filter bson.M, q *ListQuery) (*ListPage, error) {
+	pipeline := []bson.M{
+		{"$match": filter},
+		{"$unwind": "$notifications"},
+		{"$replaceRoot": bson.M{"newRoot": "$notifications"}},
+	}
+	page, _, err := AggregatePage[models.Notification](ctx, userCollection, pipeline, nil, q)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func pushNotification(ctx context.Context, userCollection *mongo.Collection, userIDs []primitive.ObjectID, subject, message string) (int64, error) {
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+
+	notification := models.Notification{
+		ID:        primitive.NewObjectID(),
+		Subject:   subject,
+		Message:   message,
+		IsRead:    false,
+		CreatedAt: time.Now(),
+	}
+
+	result, err := userCollection.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": userIDs}},
+		bson.M{"$push": bson.M{"notifications": notification}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func notifyCompanyRecruiters(ctx context.Context, userCollection *mongo.Collection, companyID primitive.ObjectID, subject, message string) {
+	ids, err := userCollection.Distinct(ctx, "_id", bson.M{"role": "rec", "companyId": companyID})
+	if err != nil {
+		return
+	}
+	var recruiterIDs []primitive.ObjectID
+	for _, id := range ids {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			recruiterIDs = append(recruiterIDs, oid)
+		}
+	}
+	pushNotification(ctx, userCollection, recruiterIDs, subject, message)
+}
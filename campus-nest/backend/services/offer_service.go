@@ -0,0 +1,393 @@
+﻿package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"time"
+
+	"backend/models"
+	"backend/storage"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type OfferServiceImpl struct {
+	offerCollection       *mongo.Collection
+	jobCollection         *mongo.Collection
+	applicationCollection *mongo.Collection
+	userCollection        *mongo.Collection
+	store                 storage.Storage
+
+	placementService PlacementService
+	waitlistService  WaitlistService
+}
+
+func NewOfferService(db *mongo.Database) OfferService {
+	return &OfferServiceImpl{
+		offerCollection:       db.Collection("offers"),
+		jobCollection:         db.Collection("jobs"),
+		applicationCollection: db.Collection("applications"),
+		userCollection:        db.Collection("users"),
+		store:                 storage.Default(),
+
+		placementService: NewPlacementService(db),
+		waitlistService:  NewWaitlistService(db),
+	}
+}
+
+// OfferLetterURL is where the API serves an uploaded offer letter to the
+// student.
+func OfferLetterURL(offerID primitive.ObjectID) string {
+	return "/api/v1/student/offers/" + offerID.Hex() + "/letter"
+}
+
+func (ofs *OfferServiceImpl) CreateOffer(offer *models.Offer) (*models.Offer, error) {
+	if offer.CTC <= 0 {
+		return nil, newValidationError("ctc", "CTC must be greater than zero")
+	}
+	if offer.ResponseDeadline.Before(time.Now()) {
+		return nil, newValidationError("response_deadline", "Response deadline must be in the future")
+	}
+	if offer.JoiningDate.IsZero() {
+		return nil, newValidationError("joining_date", "Joining date is required")
+	}
+	if offer.OfferLetterURL != "" {
+		u, err := url.Parse(offer.OfferLetterURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, newValidationError("offer_letter_url", "Offer letter URL must be an http or https link; upload the letter as a file instead")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var job models.Job
+	err := ofs.jobCollection.FindOne(ctx, bson.M{
+		"_id":                    offer.JobID,
+		"company_name.companyId": offer.CompanyID,
+	}).Decode(&job)
+	if err != nil {
+		return nil, err
+	}
+
+	var application models.Application
+	err = ofs.applicationCollection.FindOne(ctx, bson.M{
+		"job_id":     offer.JobID,
+		"student_id": offer.StudentID,
+	}).Decode(&application)
+	if err != nil {
+		return nil, err
+	}
+	if application.Status != "selected" {
+		return nil, newValidationError("status", "Offers can only be issued to selected candidates")
+	}
+
+	existing, err := ofs.offerCollection.CountDocuments(ctx, bson.M{
+		"application_id": application.ID,
+		"status":         bson.M{"$in": []string{models.OfferStatusPending, models.OfferStatusAccepted}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, newValidationError("application_id", "An offer for this application is already pending or accepted")
+	}
+
+	now := time.Now()
+	offer.ID = primitive.NewObjectID()
+	offer.ApplicationID = application.ID
+	offer.CompanyName = job.CompanyName.Name
+	offer.Position = job.Position
+	if offer.Location == "" {
+		offer.Location = job.Location
+	}
+	offer.Status = models.OfferStatusPending
+	offer.CreatedAt = now
+	offer.UpdatedAt = now
+
+	if _, err := ofs.offerCollection.InsertOne(ctx, offer); err != nil {
+		return nil, err
+	}
+
+	pushNotification(ctx, ofs.userCollection, []primitive.ObjectID{offer.StudentID},
+		"You have received an offer",
+		fmt.Sprintf("%s has offered you the position of %s at %.2f LPA. Please accept or decline the offer before %s.", offer.CompanyName, offer.Position, offer.CTC, offer.ResponseDeadline.Format("02 Jan 2006 15:04")),
+	)
+
+	return offer, nil
+}
+
+func (ofs *OfferServiceImpl) GetOfferByID(offerID primitive.ObjectID) (*models.Offer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var offer models.Offer
+	if err := ofs.offerCollection.FindOne(ctx, bson.M{"_id": offerID}).Decode(&offer); err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+var OfferListSpec = ListSpec{
+	Sorts: map[string]string{
+		"createdAt":        "created_at",
+		"ctc":              "ctc",
+		"responseDeadline": "response_deadline",
+	},
+	DefaultSort: "-createdAt",
+}
+
+func (ofs *OfferServiceImpl) findOffers(filter bson.M, q *ListQuery) (*ListPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	page, _, err := FindPage[*models.Offer](ctx, ofs.offerCollection, filter, q)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (ofs *OfferServiceImpl) GetStudentOffers(studentID primitive.ObjectID, q *ListQuery) (*ListPage, error) {
+	return ofs.findOffers(bson.M{"student_id": studentID}, q)
+}
+
+func (ofs *OfferServiceImpl) GetCompanyOffers(companyID primitive.ObjectID, status string, q *ListQuery) (*ListPage, error) {
+	filter := bson.M{"company_id": companyID}
+	if status != "" {
+		filter["status"] = status
+	}
+	return ofs.findOffers(filter, q)
+}
+
+func (ofs *OfferServiceImpl) RespondToOffer(studentID, offerID primitive.ObjectID, accept bool, reason string) (*models.Offer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var offer models.Offer
+	if err := ofs.offerCollection.FindOne(ctx, bson.M{"_id": offerID, "student_id": studentID}).Decode(&offer); err != nil {
+		return nil, err
+	}
+	if offer.Status != models.OfferStatusPending {
+		return nil, newValidationError("status", "This offer has already been "+offer.Status)
+	}
+	now := time.Now()
+	if offer.ResponseDeadline.Before(now) {
+		return nil, newValidationError("response_deadline", "The response deadline for this offer has passed")
+	}
+
+	status := models.OfferStatusDeclined
+	if accept {
+		status = models.OfferStatusAccepted
+	}
+
+	set := bson.M{"status": status, "responded_at": now, "updated_at": now}
+	if !accept && reason != "" {
+		set["decline_reason"] = reason
+	}
+	result, err := ofs.offerCollection.UpdateOne(ctx,
+		bson.M{"_id": offer.ID, "status": models.OfferStatusPending},
+		bson.M{"$set": set},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result.ModifiedCount == 0 {
+		return nil, newValidationError("status", "This offer is no longer pending")
+	}
+
+	offer.Status = status
+	offer.RespondedAt = &now
+	offer.UpdatedAt = now
+	if !accept {
+		offer.DeclineReason = reason
+	}
+
+	// The response is already saved, so a failure past this point must not
+	// be reported as a failed response; it is logged, and the placement can
+	// be repaired with POST /admin/placements/reconcile.
+	if _, err := ofs.placementService.SyncStudentPlacement(studentID); err != nil {
+		log.Printf("Offer %s: could not sync placement of %s: %v", offer.ID.Hex(), studentID.Hex(), err)
+	}
+	if !accept {
+		if _, err := ofs.waitlistService.HandleVacancy(offer.JobID); err != nil {
+			log.Printf("Offer %s: could not fill the vacancy on drive %s: %v", offer.ID.Hex(), offer.JobID.Hex(), err)
+		}
+	}
+
+	verb := "declined"
+	if accept {
+		verb = "accepted"
+	}
+	notifyCompanyRecruiters(ctx, ofs.userCollection, offer.CompanyID,
+		"Offer "+verb,
+		fmt.Sprintf("A candidate has %s your offer for the position of %s.", verb, offer.Position),
+	)
+
+	return &offer, nil
+}
+
+func (ofs *OfferServiceImpl) LapseExpiredOffers() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	cursor, err := ofs.offerCollection.Find(ctx, bson.M{
+		"status":            models.OfferStatusPending,
+		"response_deadline": bson.M{"$lt": now},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var expired []models.Offer
+	if err = cursor.All(ctx, &expired); err != nil {
+		return 0, err
+	}
+
+	lapsed := 0
+	for _, offer := range expired {
+		result, err := ofs.offerCollection.UpdateOne(ctx,
+			bson.M{"_id": offer.ID, "status": models.OfferStatusPending},
+			bson.M{"$set": bson.M{"status": models.OfferStatusLapsed, "updated_at": now}},
+		)
+		if err != nil {
+			return lapsed, err
+		}
+		if result.ModifiedCount == 0 {
+			continue
+		}
+		lapsed++
+
+		if _, err := ofs.placementService.SyncStudentPlacement(offer.StudentID); err != nil {
+			log.Printf("Offer %s: could not sync placement of %s: %v", offer.ID.Hex(), offer.StudentID.Hex(), err)
+		}
+		if _, err := ofs.waitlistService.HandleVacancy(offer.JobID); err != nil {
+			log.Printf("Offer %s: could not fill the vacancy on drive %s: %v", offer.ID.Hex(), offer.JobID.Hex(), err)
+		}
+
+		pushNotification(ctx, ofs.userCollection, []primitive.ObjectID{offer.StudentID},
+			"Offer lapsed",
+			fmt.Sprintf("Your offer from %s for the position of %s has lapsed because it was not accepted before the deadline.", offer.CompanyName, offer.Position),
+		)
+		notifyCompanyRecruiters(ctx, ofs.userCollection, offer.CompanyID,
+			"Offer lapsed",
+			fmt.Sprintf("An offer for the position of %s lapsed without a response from the candidate.", offer.Position),
+		)
+	}
+	return lapsed, nil
+}
+
+// AttachOfferLetter stores the letter for one of the company's offers,
+// replacing any letter uploaded before, and tells the student it is there.
+func (ofs *OfferServiceImpl) AttachOfferLetter(offerID, companyID primitive.ObjectID, fileName string, r io.Reader, size int64, uploadedBy primitive.ObjectID) (*models.Offer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	if err := checkUploadSize(size, MaxAttachmentSize, "file"); err != nil {
+		return nil, err
+	}
+
+	var offer models.Offer
+	if err := ofs.offerCollection.FindOne(ctx, bson.M{"_id": offerID, "company_id": companyID}).Decode(&offer); err != nil {
+		return nil, err
+	}
+	if offer.Status == models.OfferStatusDeclined || offer.Status == models.OfferStatusLapsed {
+		return nil, newValidationError("status", fmt.Sprintf("The offer was %s, its letter can no longer be changed", offer.Status))
+	}
+
+	contentType, ext, body, err := sniffUpload(r, documentContentTypes, "file", "PDF, DOC or DOCX")
+	if err != nil {
+		return nil, err
+	}
+
+	letter := &models.Attachment{
+		ID:          primitive.NewObjectID(),
+		ContentType: contentType,
+		Size:        size,
+		UploadedBy:  uploadedBy,
+		UploadedAt:  time.Now(),
+	}
+	letter.FileName = uploadFileName(fileName, "offer-letter", ext)
+	letter.StorageKey = fmt.Sprintf("offers/%s/%s%s", offerID.Hex(), letter.ID.Hex(), ext)
+
+	if err := ofs.store.Put(ctx, letter.StorageKey, body, size, contentType); err != nil {
+		return nil, err
+	}
+
+	// The status guard is repeated so a letter is not attached to an offer
+	// that was declined meanwhile. The letter it replaces is read from the
+	// same update so concurrent uploads each remove the right file.
+	now := time.Now()
+	var before models.Offer
+	err = ofs.offerCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": offerID, "company_id": companyID, "status": bson.M{"$in": []string{models.OfferStatusPending, models.OfferStatusAccepted}}},
+		bson.M{"$set": bson.M{"offer_letter": letter, "offer_letter_url": OfferLetterURL(offerID), "updated_at": now}},
+	).Decode(&before)
+	if err == mongo.ErrNoDocuments {
+		err = newValidationError("status", "The offer can no longer be changed")
+	}
+	if err != nil {
+		ofs.store.Delete(ctx, letter.StorageKey)
+		return nil, err
+	}
+	if before.OfferLetter != nil {
+		if err := ofs.store.Delete(ctx, before.OfferLetter.StorageKey); err != nil {
+			log.Printf("Offer %s: could not remove the replaced letter: %v", offerID.Hex(), err)
+		}
+	}
+	offer = before
+	offer.OfferLetter = letter
+	offer.OfferLetterURL = OfferLetterURL(offerID)
+	offer.UpdatedAt = now
+
+	pushNotification(ctx, ofs.userCollection, []primitive.ObjectID{offer.StudentID},
+		"Your offer letter is available",
+		fmt.Sprintf("%s has uploaded the offer letter for the position of %s. You can download it from your offers.", offer.CompanyName, offer.Position),
+	)
+	return &offer, nil
+}
+
+// OpenOfferLetter opens an offer's uploaded letter for viewer. Only the
+// student the offer was made to and the company's recruiters may download
+// it.
+func (ofs *OfferServiceImpl) OpenOfferLetter(offerID primitive.ObjectID, viewer *models.User) (*models.Attachment, io.ReadCloser, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var offer models.Offer
+	if err := ofs.offerCollection.FindOne(ctx, bson.M{"_id": offerID}).Decode(&offer); err != nil {
+		return nil, nil, err
+	}
+
+	switch viewer.Role {
+	case "student":
+		// Other students' offers are not acknowledged to exist.
+		if offer.StudentID != viewer.ID {
+			return nil, nil, mongo.ErrNoDocuments
+		}
+	case "rec":
+		if viewer.CompanyID == nil || *viewer.CompanyID != offer.CompanyID {
+			return nil, nil, ErrForbidden
+		}
+	default:
+		return nil, nil, ErrForbidden
+	}
+	if offer.OfferLetter == nil {
+		return nil, nil, mongo.ErrNoDocuments
+	}
+
+	file, err := ofs.store.Open(ctx, offer.OfferLetter.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, mongo.ErrNoDocuments
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return offer.OfferLetter, file, nil
+}
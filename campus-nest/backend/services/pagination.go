@@ -0,0 +1,265 @@
+﻿package services
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// ListSpec whitelists the sort keys a list endpoint accepts. Sorts maps the
+// key used in ?sort= to the document field it orders by, and DefaultSort is
+// used when the request does not pick one ("-" prefix for descending).
+type ListSpec struct {
+	Sorts       map[string]string
+	DefaultSort string
+}
+
+// ListQuery is a parsed ?limit=&cursor=&sort= request. Pages are cut by
+// keyset on the sort field with _id as the tie breaker, so a cursor stays
+// valid while documents are inserted or removed ahead of it.
+type ListQuery struct {
+	Limit int
+	Sort  string
+	Field string
+	Order int
+
+	after *listCursor
+}
+
+// ListPage is the envelope every list endpoint responds with. NextCursor is
+// null on the last page. Summary carries endpoint specific aggregates that
+// describe the whole result set rather than the current page.
+type ListPage struct {
+	Items      interface{} `json:"items"`
+	NextCursor *string     `json:"nextCursor"`
+	Total      int64       `json:"total"`
+	Summary    interface{} `json:"summary,omitempty"`
+}
+
+type listCursor struct {
+	Sort  string        `bson:"s"`
+	Value bson.RawValue `bson:"v"`
+	ID    bson.RawValue `bson:"id"`
+}
+
+func NewListQuery(spec ListSpec, limit, cursor, sortBy string) (*ListQuery, error) {
+	q := &ListQuery{Limit: DefaultListLimit, Order: 1}
+
+	if limit = strings.TrimSpace(limit); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > MaxListLimit {
+			return nil, newValidationError("limit", fmt.Sprintf("Limit must be a number between 1 and %d", MaxListLimit))
+		}
+		q.Limit = n
+	}
+
+	q.Sort = strings.TrimSpace(sortBy)
+	if q.Sort == "" {
+		q.Sort = spec.DefaultSort
+	}
+	key := q.Sort
+	if strings.HasPrefix(key, "-") {
+		key = key[1:]
+		q.Order = -1
+	}
+	field, ok := spec.Sorts[key]
+	if !ok {
+		keys := make([]string, 0, len(spec.Sorts))
+		for k := range spec.Sorts {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return nil, newValidationError("sort", fmt.Sprintf("Cannot sort by %q, use one of: %s", key, strings.Join(keys, ", ")))
+	}
+	q.Field = field
+
+	if cursor = strings.TrimSpace(cursor); cursor != "" {
+		data, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return nil, newValidationError("cursor", "Invalid cursor")
+		}
+		var after listCursor
+		if err := bson.Unmarshal(data, &after); err != nil || after.ID.Type == 0 {
+			return nil, newValidationError("cursor", "Invalid cursor")
+		}
+		if after.Sort != q.Sort {
+			return nil, newValidationError("cursor", "The cursor was issued for a different sort order")
+		}
+		q.after = &after
+	}
+	return q, nil
+}
+
+// Filter narrows base to the documents that come after the cursor.
+func (q *ListQuery) Filter(base bson.M) bson.M {
+	if q.after == nil {
+		return base
+	}
+	keyset := q.keyset()
+	if len(base) == 0 {
+		return keyset
+	}
+	return bson.M{"$and": bson.A{base, keyset}}
+}
+
+func (q *ListQuery) keyset() bson.M {
+	op := "$gt"
+	if q.Order < 0 {
+		op = "$lt"
+	}
+	if q.Field == "_id" {
+		return bson.M{"_id": bson.M{op: q.after.ID}}
+	}
+
+	tie := bson.M{q.Field: q.after.Value, "_id": bson.M{op: q.after.ID}}
+	// Missing and null values sort before everything else ascending and
+	// after everything else descending.
+	if q.after.Value.Type == bsontype.Null {
+		if q.Order > 0 {
+			return bson.M{"$or": bson.A{bson.M{q.Field: bson.M{"$ne": nil}}, tie}}
+		}
+		return tie
+	}
+	past := bson.A{bson.M{q.Field: bson.M{op: q.after.Value}}, tie}
+	if q.Order < 0 {
+		past = append(past, bson.M{q.Field: nil})
+	}
+	return bson.M{"$or": past}
+}
+
+func (q *ListQuery) SortDoc() bson.D {
+	doc := bson.D{{Key: q.Field, Value: q.Order}}
+	if q.Field != "_id" {
+		doc = append(doc, bson.E{Key: "_id", Value: q.Order})
+	}
+	return doc
+}
+
+// FindOptions sorts and fetches one document more than the page size so
+// Paginate can tell whether another page follows.
+func (q *ListQuery) FindOptions() *options.FindOptions {
+	return options.Find().SetSort(q.SortDoc()).SetLimit(int64(q.Limit + 1))
+}
+
+// Stages is the aggregation equivalent of Filter and FindOptions. Place it
+// after the stages that compute the sort field.
+func (q *ListQuery) Stages() []bson.M {
+	var stages []bson.M
+	if q.after != nil {
+		stages = append(stages, bson.M{"$match": q.keyset()})
+	}
+	return append(stages,
+		bson.M{"$sort": q.SortDoc()},
+		bson.M{"$limit": int64(q.Limit + 1)},
+	)
+}
+
+// Paginate trims docs fetched with FindOptions or Stages to the page size and
+// returns the cursor for the next page, read from the last document kept.
+func Paginate[T any](q *ListQuery, docs []T) ([]T, *string, error) {
+	if len(docs) <= q.Limit {
+		return docs, nil, nil
+	}
+	docs = docs[:q.Limit]
+
+	raw, err := bson.Marshal(docs[len(docs)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	after := listCursor{Sort: q.Sort, Value: bson.RawValue{Type: bsontype.Null}}
+	if v, err := bson.Raw(raw).LookupErr(strings.Split(q.Field, ".")...); err == nil {
+		after.Value = v
+	}
+	id, err := bson.Raw(raw).LookupErr("_id")
+	if err != nil {
+		return nil, nil, fmt.Errorf("paginate: document has no _id")
+	}
+	after.ID = id
+
+	data, err := bson.Marshal(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	next := base64.RawURLEncoding.EncodeToString(data)
+	return docs, &next, nil
+}
+
+// FindPage runs a paginated Find on coll. Total counts every document that
+// matches filter, not just the ones on this page.
+func FindPage[T any](ctx context.Context, coll *mongo.Collection, filter bson.M, q *ListQuery, opts ...*options.FindOptions) (*ListPage, []T, error) {
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cursor, err := coll.Find(ctx, q.Filter(filter), append([]*options.FindOptions{q.FindOptions()}, opts...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	docs := []T{}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, nil, err
+	}
+	docs, next, err := Paginate(q, docs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ListPage{Items: docs, NextCursor: next, Total: total}, docs, nil
+}
+
+// AggregatePage pages through the documents produced by pipeline, which must
+// output the sort field. Total is counted over the whole pipeline output in
+// the same round trip. shape runs only on the documents of the page and has
+// to keep _id and the sort field.
+func AggregatePage[T any](ctx context.Context, coll *mongo.Collection, pipeline, shape []bson.M, q *ListQuery) (*ListPage, []T, error) {
+	items := append(q.Stages(), shape...)
+	pipeline = append(append([]bson.M{}, pipeline...), bson.M{"$facet": bson.M{
+		"items": items,
+		"total": []bson.M{{"$count": "count"}},
+	}})
+
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Items []T `bson:"items"`
+		Total []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+	}
+	if err = cursor.All(ctx, &result); err != nil {
+		return nil, nil, err
+	}
+
+	docs := []T{}
+	var total int64
+	if len(result) > 0 {
+		docs = append(docs, result[0].Items...)
+		if len(result[0].Total) > 0 {
+			total = result[0].Total[0].Count
+		}
+	}
+	docs, next, err := Paginate(q, docs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ListPage{Items: docs, NextCursor: next, Total: total}, docs, nil
+}
@@ -0,0 +1,343 @@
+﻿package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	PlacementStatusPlaced   = "Placed"
+	PlacementStatusUnplaced = "Unplaced"
+)
+
+// Where a student's placement came from. Only derived placements follow the
+// student's outcomes; imported and manual ones are kept until an outcome in
+// the system places the student.
+const (
+	PlacementSourceDerived  = "derived"
+	PlacementSourceImported = "imported"
+	PlacementSourceManual   = "manual"
+)
+
+const placementSourceMigration = "placement-source"
+
+type PlacementServiceImpl struct {
+	userCollection        *mongo.Collection
+	applicationCollection *mongo.Collection
+	jobCollection         *mongo.Collection
+	offerCollection       *mongo.Collection
+}
+
+func NewPlacementService(db *mongo.Database) PlacementService {
+	return &PlacementServiceImpl{
+		userCollection:        db.Collection("users"),
+		applicationCollection: db.Collection("applications"),
+		jobCollection:         db.Collection("jobs"),
+		offerCollection:       db.Collection("offers"),
+	}
+}
+
+// DerivePlacement works out a student's placement from their outcomes. An
+// accepted offer always wins; otherwise the best-paying selected application
+// whose offer was not declined or allowed to lapse is used.
+func (ps *PlacementServiceImpl) DerivePlacement(ctx context.Context, studentID primitive.ObjectID) (*PlacementRecord, error) {
+	record := &PlacementRecord{StudentID: studentID, Status: PlacementStatusUnplaced}
+
+	var accepted []models.Offer
+	cursor, err := ps.offerCollection.Find(ctx, bson.M{
+		"student_id": studentID,
+		"status":     models.OfferStatusAccepted,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &accepted); err != nil {
+		return nil, err
+	}
+	if len(accepted) > 0 {
+		best := accepted[0]
+		for _, offer := range accepted[1:] {
+			if offer.RespondedAt != nil && (best.RespondedAt == nil || offer.RespondedAt.After(*best.RespondedAt)) {
+				best = offer
+			}
+		}
+		record.Status = PlacementStatusPlaced
+		record.Company = best.CompanyName
+		record.Role = best.Position
+		record.Salary = best.CTC
+		record.Source = "offer"
+		return record, nil
+	}
+
+	held, err := heldOffers(ctx, ps.applicationCollection, ps.offerCollection, ps.jobCollection, studentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range held {
+		salary := ParseSalaryLPA(h.Job.SalaryRange)
+		if record.Source == "" || salary > record.Salary {
+			record.Status = PlacementStatusPlaced
+			record.Company = h.Job.CompanyName.Name
+			record.Role = h.Job.Position
+			record.Salary = salary
+			record.Source = "application"
+		}
+	}
+	return record, nil
+}
+
+// heldOffer is a selection the student still holds, with its drive.
+type heldOffer struct {
+	Application models.Application
+	Job         models.Job
+}
+
+// heldOffers lists the student's selected applications whose offer was not
+// declined or allowed to lapse. Internship selections are not offers unless
+// the student accepted a pre-placement offer from them. Placement and the
+// placement policy both count offers this way.
+func heldOffers(ctx context.Context, applications, offers, jobs *mongo.Collection, studentID primitive.ObjectID) ([]heldOffer, error) {
+	var selected []models.Application
+	cursor, err := applications.Find(ctx, bson.M{
+		"student_id": studentID,
+		"status":     bson.M{"$in": []string{"selected", "offered"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &selected); err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		return nil, nil
+	}
+
+	applicationIDs := make([]primitive.ObjectID, 0, len(selected))
+	jobIDs := make([]primitive.ObjectID, 0, len(selected))
+	for _, app := range selected {
+		applicationIDs = append(applicationIDs, app.ID)
+		jobIDs = append(jobIDs, app.JobID)
+	}
+
+	closed, err := offers.Distinct(ctx, "application_id", bson.M{
+		"application_id": bson.M{"$in": applicationIDs},
+		"status":         bson.M{"$in": []string{models.OfferStatusDeclined, models.OfferStatusLapsed}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	skip := make(map[primitive.ObjectID]bool, len(closed))
+	for _, id := range closed {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			skip[oid] = true
+		}
+	}
+
+	var drives []models.Job
+	cursor, err = jobs.Find(ctx, bson.M{"_id": bson.M{"$in": jobIDs}})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &drives); err != nil {
+		return nil, err
+	}
+	jobsByID := make(map[primitive.ObjectID]models.Job, len(drives))
+	for _, job := range drives {
+		jobsByID[job.ID] = job
+	}
+
+	var held []heldOffer
+	for _, app := range selected {
+		job, ok := jobsByID[app.JobID]
+		if !ok || skip[app.ID] {
+			continue
+		}
+		if job.JobType == models.JobTypeInternship && (app.PPO == nil || app.PPO.Status != models.PPOStatusAccepted) {
+			continue
+		}
+		held = append(held, heldOffer{Application: app, Job: job})
+	}
+	return held, nil
+}
+
+func (ps *PlacementServiceImpl) SyncStudentPlacement(studentID primitive.ObjectID) (*PlacementRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	record, err := ps.DerivePlacement(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ps.apply(ctx, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// apply writes a derived placement. When nothing places the student, a
+// placement that was not derived is left alone.
+func (ps *PlacementServiceImpl) apply(ctx context.Context, record *PlacementRecord) error {
+	filter := bson.M{"_id": record.StudentID, "role": "student"}
+	update := bson.M{"$set": bson.M{"placedStatus": record.Status, "updatedAt": time.Now()}}
+	if record.Status == PlacementStatusPlaced {
+		set := update["$set"].(bson.M)
+		set["company"] = record.Company
+		set["placedRole"] = record.Role
+		set["salary"] = record.Salary
+		set["placementSource"] = PlacementSourceDerived
+	} else {
+		filter["$or"] = bson.A{
+			bson.M{"placedStatus": bson.M{"$ne": PlacementStatusPlaced}},
+			bson.M{"placementSource": PlacementSourceDerived},
+		}
+		update["$unset"] = bson.M{"company": "", "placedRole": "", "salary": "", "placementSource": ""}
+	}
+	_, err := ps.userCollection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (ps *PlacementServiceImpl) ReconcilePlacements(dryRun bool) (*ReconcileReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	cursor, err := ps.userCollection.Find(ctx, bson.M{"role": "student"})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	report := &ReconcileReport{DryRun: dryRun, Changes: []PlacementChange{}, Unverified: []PlacementChange{}}
+	for cursor.Next(ctx) {
+		var student models.User
+		if err := cursor.Decode(&student); err != nil {
+			return report, err
+		}
+		report.Checked++
+
+		record, err := ps.DerivePlacement(ctx, student.ID)
+		if err != nil {
+			return report, err
+		}
+
+		change, drifted := placementDrift(&student, record)
+		if !drifted {
+			continue
+		}
+		// Placements imported or entered by hand have nothing in the system
+		// to derive them from; they are reported but kept.
+		if record.Source == "" && keptPlacement(&student) {
+			report.Unverified = append(report.Unverified, change)
+			continue
+		}
+		report.Changes = append(report.Changes, change)
+		if dryRun {
+			continue
+		}
+		if err := ps.apply(ctx, record); err != nil {
+			return report, err
+		}
+		report.Updated++
+	}
+	return report, cursor.Err()
+}
+
+// keptPlacement reports whether the student is placed by an import or a
+// manual entry rather than by their outcomes.
+func keptPlacement(student *models.User) bool {
+	return student.PlacementStatus != nil && *student.PlacementStatus == PlacementStatusPlaced &&
+		student.PlacementSource != PlacementSourceDerived
+}
+
+// EnsurePlacementSources runs once to record where the placements of
+// students placed before the source was tracked came from: derived when
+// their outcomes still place them, imported otherwise.
+func EnsurePlacementSources(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	migrations := db.Collection("migrations")
+	err := migrations.FindOne(ctx, bson.M{"_id": placementSourceMigration}).Err()
+	if err == nil {
+		return nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	ps := NewPlacementService(db).(*PlacementServiceImpl)
+	cursor, err := ps.userCollection.Find(ctx,
+		bson.M{"role": "student", "placedStatus": PlacementStatusPlaced, "placementSource": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	var students []models.User
+	if err := cursor.All(ctx, &students); err != nil {
+		return err
+	}
+
+	var writes []mongo.WriteModel
+	derived := 0
+	for _, student := range students {
+		record, err := ps.DerivePlacement(ctx, student.ID)
+		if err != nil {
+			return err
+		}
+		source := PlacementSourceImported
+		if record.Source != "" {
+			source = PlacementSourceDerived
+			derived++
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": student.ID, "placementSource": bson.M{"$exists": false}}).
+			SetUpdate(bson.M{"$set": bson.M{"placementSource": source}}))
+	}
+	if len(writes) > 0 {
+		if _, err := ps.userCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+			return err
+		}
+	}
+	log.Printf("Recorded the placement source of %d student(s), %d derived from their outcomes", len(writes), derived)
+
+	_, err = migrations.InsertOne(ctx, bson.M{"_id": placementSourceMigration, "applied_at": time.Now(), "updated": len(writes)})
+	return err
+}
+
+func placementDrift(student *models.User, record *PlacementRecord) (PlacementChange, bool) {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+
+	change := PlacementChange{
+		StudentID:  student.ID,
+		Email:      student.Email,
+		FromStatus: deref(student.PlacementStatus),
+		ToStatus:   record.Status,
+		Company:    record.Company,
+		Role:       record.Role,
+		Salary:     record.Salary,
+	}
+
+	if change.FromStatus != record.Status {
+		return change, true
+	}
+	if record.Status != PlacementStatusPlaced {
+		return change, student.PlacedCompany != nil || student.PlacedRole != nil || student.Salary != nil
+	}
+	if deref(student.PlacedCompany) != record.Company || deref(student.PlacedRole) != record.Role {
+		return change, true
+	}
+	return change, student.Salary == nil || *student.Salary != record.Salary
+}
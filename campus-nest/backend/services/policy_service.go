@@ -0,0 +1,370 @@
+﻿package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var academicYearPattern = regexp.MustCompile(`^\d{4}-\d{2}$`)
+
+type PolicyServiceImpl struct {
+	policyCollection      *mongo.Collection
+	jobCollection         *mongo.Collection
+	applicationCollection *mongo.Collection
+	offerCollection       *mongo.Collection
+}
+
+func NewPolicyService(db *mongo.Database) PolicyService {
+	return &PolicyServiceImpl{
+		policyCollection:      db.Collection("placement_policies"),
+		jobCollection:         db.Collection("jobs"),
+		applicationCollection: db.Collection("applications"),
+		offerCollection:       db.Collection("offers"),
+	}
+}
+
+// EnsurePolicyIndexes backs the one-active-policy-per-year rule with a
+// unique index over the active policies.
+func EnsurePolicyIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := db.Collection("placement_policies").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "academic_year", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"is_active": true}),
+	})
+	return err
+}
+
+// CurrentAcademicYear returns the academic year label ("2025-26") that t falls in.
+// Academic years start in June.
+func CurrentAcademicYear(t time.Time) string {
+	start := t.Year()
+	if t.Month() < time.June {
+		start--
+	}
+	return fmt.Sprintf("%d-%02d", start, (start+1)%100)
+}
+
+func validatePolicy(policy *models.PlacementPolicy) error {
+	if !academicYearPattern.MatchString(policy.AcademicYear) {
+		return newValidationError("academic_year", "Academic year must look like 2025-26")
+	}
+	if policy.Name == "" {
+		return newValidationError("name", "Policy name is required")
+	}
+	if policy.MaxOffers < 0 {
+		return newValidationError("max_offers", "Maximum offers cannot be negative")
+	}
+	if policy.DefaultTier < 0 {
+		return newValidationError("default_tier", "Default tier cannot be negative")
+	}
+	if policy.MinSalaryJumpPercent < 0 {
+		return newValidationError("min_salary_jump_percent", "Salary jump threshold cannot be negative")
+	}
+
+	seen := make(map[primitive.ObjectID]bool)
+	for _, ct := range policy.CompanyTiers {
+		if ct.CompanyID.IsZero() {
+			return newValidationError("company_tiers", "Every company tier needs a companyId")
+		}
+		if ct.Tier < 1 {
+			return newValidationError("company_tiers", "Company tiers must be 1 or higher")
+		}
+		if seen[ct.CompanyID] {
+			return newValidationError("company_tiers", "Company "+ct.CompanyID.Hex()+" is listed more than once")
+		}
+		seen[ct.CompanyID] = true
+	}
+
+	placedTiers := make(map[int]bool)
+	for _, rule := range policy.TierRules {
+		if rule.PlacedTier < 1 {
+			return newValidationError("tier_rules", "Tier rules must reference a tier of 1 or higher")
+		}
+		if placedTiers[rule.PlacedTier] {
+			return newValidationError("tier_rules", fmt.Sprintf("Tier %d has more than one rule", rule.PlacedTier))
+		}
+		placedTiers[rule.PlacedTier] = true
+		for _, t := range rule.AllowedTiers {
+			if t < 1 {
+				return newValidationError("tier_rules", "Allowed tiers must be 1 or higher")
+			}
+		}
+	}
+	return nil
+}
+
+var PolicyListSpec = ListSpec{
+	Sorts: map[string]string{
+		"academicYear": "academic_year",
+		"name":         "name",
+		"createdAt":    "created_at",
+	},
+	DefaultSort: "-academicYear",
+}
+
+func (ps *PolicyServiceImpl) ListPolicies(academicYear string, q *ListQuery) (*ListPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if academicYear != "" {
+		filter["academic_year"] = academicYear
+	}
+
+	page, _, err := FindPage[*models.PlacementPolicy](ctx, ps.policyCollection, filter, q)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (ps *PolicyServiceImpl) GetPolicyByID(policyID primitive.ObjectID) (*models.PlacementPolicy, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var policy models.PlacementPolicy
+	if err := ps.policyCollection.FindOne(ctx, bson.M{"_id": policyID}).Decode(&policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (ps *PolicyServiceImpl) CreatePolicy(policy *models.PlacementPolicy) (*primitive.ObjectID, error) {
+	if err := validatePolicy(policy); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	policy.ID = primitive.NewObjectID()
+	policy.CreatedAt = time.Now()
+	policy.UpdatedAt = policy.CreatedAt
+	policy.IsActive = false
+
+	if _, err := ps.policyCollection.InsertOne(ctx, policy); err != nil {
+		return nil, err
+	}
+	return &policy.ID, nil
+}
+
+func (ps *PolicyServiceImpl) UpdatePolicy(policyID primitive.ObjectID, policy *models.PlacementPolicy) error {
+	if err := validatePolicy(policy); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var current models.PlacementPolicy
+	if err := ps.policyCollection.FindOne(ctx, bson.M{"_id": policyID}).Decode(&current); err != nil {
+		return err
+	}
+
+	set := bson.M{
+		"academic_year":           policy.AcademicYear,
+		"name":                    policy.Name,
+		"description":             policy.Description,
+		"max_offers":              policy.MaxOffers,
+		"default_tier":            policy.DefaultTier,
+		"company_tiers":           policy.CompanyTiers,
+		"tier_rules":              policy.TierRules,
+		"min_salary_jump_percent": policy.MinSalaryJumpPercent,
+		"updated_at":              time.Now(),
+	}
+	// An active policy moved to another year is not active there; it has to
+	// be activated again, which deactivates that year's current policy.
+	filter := bson.M{"_id": policyID, "academic_year": current.AcademicYear}
+	if current.AcademicYear != policy.AcademicYear {
+		set["is_active"] = false
+	}
+
+	result, err := ps.policyCollection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (ps *PolicyServiceImpl) DeletePolicy(policyID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := ps.policyCollection.DeleteOne(ctx, bson.M{"_id": policyID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (ps *PolicyServiceImpl) ActivatePolicy(policyID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var policy models.PlacementPolicy
+	if err := ps.policyCollection.FindOne(ctx, bson.M{"_id": policyID}).Decode(&policy); err != nil {
+		return err
+	}
+
+	if policy.IsActive {
+		return nil
+	}
+
+	_, err := ps.policyCollection.UpdateMany(ctx,
+		bson.M{"academic_year": policy.AcademicYear, "is_active": true, "_id": bson.M{"$ne": policyID}},
+		bson.M{"$set": bson.M{"is_active": false, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+
+	// The unique index on active policies turns a concurrent activation in
+	// the same year into a duplicate key error instead of two active
+	// policies. The year must still match, in case the policy was moved.
+	result, err := ps.policyCollection.UpdateOne(ctx,
+		bson.M{"_id": policyID, "academic_year": policy.AcademicYear},
+		bson.M{"$set": bson.M{"is_active": true, "updated_at": time.Now()}},
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return newValidationError("is_active", "Another policy for "+policy.AcademicYear+" was activated at the same time; try again")
+	}
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return newValidationError("academic_year", "The policy was changed while activating it; try again")
+	}
+	return nil
+}
+
+func (ps *PolicyServiceImpl) GetActivePolicy(academicYear string) (*models.PlacementPolicy, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var policy models.PlacementPolicy
+	err := ps.policyCollection.FindOne(ctx, bson.M{"academic_year": academicYear, "is_active": true}).Decode(&policy)
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func tierFor(policy *models.PlacementPolicy, companyID primitive.ObjectID) int {
+	for _, ct := range policy.CompanyTiers {
+		if ct.CompanyID == companyID {
+			return ct.Tier
+		}
+	}
+	return policy.DefaultTier
+}
+
+func (ps *PolicyServiceImpl) EvaluateApplication(studentID, jobID primitive.ObjectID) (*PolicyDecision, error) {
+	policy, err := ps.GetActivePolicy(CurrentAcademicYear(time.Now()))
+	if err == mongo.ErrNoDocuments {
+		return &PolicyDecision{Allowed: true, Violations: []string{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var job models.Job
+	if err := ps.jobCollection.FindOne(ctx, bson.M{"_id": jobID}).Decode(&job); err != nil {
+		return nil, err
+	}
+
+	// The policy limits placement offers; internships are not one.
+	if job.JobType == models.JobTypeInternship {
+		return &PolicyDecision{
+			Allowed:      true,
+			PolicyID:     &policy.ID,
+			PolicyName:   policy.Name,
+			AcademicYear: policy.AcademicYear,
+			Violations:   []string{},
+		}, nil
+	}
+
+	// Declined and lapsed offers are not held, as in DerivePlacement.
+	offers, err := heldOffers(ctx, ps.applicationCollection, ps.offerCollection, ps.jobCollection, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := &PolicyDecision{
+		Allowed:      true,
+		PolicyID:     &policy.ID,
+		PolicyName:   policy.Name,
+		AcademicYear: policy.AcademicYear,
+		JobTier:      tierFor(policy, job.CompanyName.CompanyID),
+		OfferCount:   len(offers),
+		Violations:   []string{},
+	}
+	if len(offers) == 0 {
+		return decision, nil
+	}
+
+	if policy.MaxOffers > 0 && len(offers) >= policy.MaxOffers {
+		decision.Violations = append(decision.Violations, fmt.Sprintf("You already hold %d offer(s); the policy allows at most %d", len(offers), policy.MaxOffers))
+	}
+
+	bestTier := 0
+	bestPackage := 0.0
+	for _, offer := range offers {
+		oj := offer.Job
+		if t := tierFor(policy, oj.CompanyName.CompanyID); t > 0 && (bestTier == 0 || t < bestTier) {
+			bestTier = t
+		}
+		if p := ParseSalaryLPA(oj.SalaryRange); p > bestPackage {
+			bestPackage = p
+		}
+	}
+	decision.PlacedTier = bestTier
+
+	if bestTier > 0 {
+		for _, rule := range policy.TierRules {
+			if rule.PlacedTier != bestTier {
+				continue
+			}
+			allowed := false
+			for _, t := range rule.AllowedTiers {
+				if t == decision.JobTier {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				decision.Violations = append(decision.Violations, fmt.Sprintf("Students placed in a tier-%d company may only apply to tier %v drives; this drive is tier %d", bestTier, rule.AllowedTiers, decision.JobTier))
+			}
+		}
+	}
+
+	if policy.MinSalaryJumpPercent > 0 && bestPackage > 0 {
+		jobPackage := ParseSalaryLPA(job.SalaryRange)
+		required := bestPackage * (1 + policy.MinSalaryJumpPercent/100)
+		if jobPackage > 0 && jobPackage < required {
+			decision.Violations = append(decision.Violations, fmt.Sprintf("This drive offers %.2f LPA; the policy requires at least %.2f LPA (%.0f%% above your current %.2f LPA offer)", jobPackage, required, policy.MinSalaryJumpPercent, bestPackage))
+		}
+	}
+
+	decision.Allowed = len(decision.Violations) == 0
+	return decision, nil
+}
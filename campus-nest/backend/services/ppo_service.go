@@ -0,0 +1,213 @@
+﻿package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PPOServiceImpl struct {
+	jobCollection         *mongo.Collection
+	applicationCollection *mongo.Collection
+	userCollection        *mongo.Collection
+
+	placementService PlacementService
+}
+
+func NewPPOService(db *mongo.Database) PPOService {
+	return &PPOServiceImpl{
+		jobCollection:         db.Collection("jobs"),
+		applicationCollection: db.Collection("applications"),
+		userCollection:        db.Collection("users"),
+
+		placementService: NewPlacementService(db),
+	}
+}
+
+// ppoTransitions lists the outcomes each PPO status can move to. Interns
+// start out pending; accepted and declined are only reachable from offered.
+var ppoTransitions = map[string][]string{
+	models.PPOStatusPending:    {models.PPOStatusOffered, models.PPOStatusNotOffered},
+	models.PPOStatusOffered:    {models.PPOStatusAccepted, models.PPOStatusDeclined, models.PPOStatusNotOffered},
+	models.PPOStatusNotOffered: {models.PPOStatusOffered},
+	models.PPOStatusAccepted:   {models.PPOStatusDeclined},
+	models.PPOStatusDeclined:   {models.PPOStatusAccepted},
+}
+
+// RecordPPOOutcome sets the PPO outcome of an intern selected on one of the
+// company's internship drives.
+func (ps *PPOServiceImpl) RecordPPOOutcome(companyID, jobID, studentID primitive.ObjectID, status, remarks string, updatedBy primitive.ObjectID) (*models.Application, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, ok := ppoTransitions[status]; !ok || status == models.PPOStatusPending {
+		return nil, newValidationError("status", "PPO status must be one of 'offered', 'not_offered', 'accepted' or 'declined'")
+	}
+
+	var job models.Job
+	err := ps.jobCollection.FindOne(ctx, bson.M{
+		"_id":                    jobID,
+		"company_name.companyId": companyID,
+	}).Decode(&job)
+	if err != nil {
+		return nil, err
+	}
+	if job.JobType != models.JobTypeInternship {
+		return nil, newValidationError("job_type", "PPO outcomes can only be recorded for internship drives")
+	}
+
+	var application models.Application
+	err = ps.applicationCollection.FindOne(ctx, bson.M{"job_id": jobID, "student_id": studentID}).Decode(&application)
+	if err != nil {
+		return nil, err
+	}
+	if application.Status != models.ApplicationStatusSelected {
+		return nil, newValidationError("status", "PPO outcomes can only be recorded for selected interns")
+	}
+
+	current := models.PPOStatusPending
+	if application.PPO != nil {
+		current = application.PPO.Status
+	}
+	if current == status {
+		return &application, nil
+	}
+	if !containsString(ppoTransitions[current], status) {
+		return nil, newValidationError("status", "Cannot change the PPO outcome from '"+current+"' to '"+status+"'")
+	}
+
+	outcome := models.PPOOutcome{
+		Status:    status,
+		Remarks:   remarks,
+		UpdatedBy: updatedBy,
+		UpdatedAt: time.Now(),
+	}
+	filter := bson.M{"_id": application.ID, "ppo.status": current}
+	if application.PPO == nil {
+		filter = bson.M{"_id": application.ID, "ppo": bson.M{"$exists": false}}
+	}
+	var updated models.Application
+	err = ps.applicationCollection.FindOneAndUpdate(ctx,
+		filter,
+		bson.M{"$set": bson.M{"ppo": outcome, "updated_on": outcome.UpdatedAt}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return nil, newValidationError("status", "The PPO outcome was changed by someone else, reload and try again")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// An accepted PPO places the student; taking it back may unplace them.
+	if status == models.PPOStatusAccepted || current == models.PPOStatusAccepted {
+		if _, err := ps.placementService.SyncStudentPlacement(studentID); err != nil {
+			log.Printf("PPO on drive %s: could not sync placement of %s: %v", jobID.Hex(), studentID.Hex(), err)
+		}
+	}
+
+	if status == models.PPOStatusOffered {
+		pushNotification(ctx, ps.userCollection, []primitive.ObjectID{studentID},
+			"Pre-placement offer from "+job.CompanyName.Name,
+			"Congratulations! "+job.CompanyName.Name+" has extended a pre-placement offer following your "+job.Position+" internship.",
+		)
+	}
+	return &updated, nil
+}
+
+// GetPPOStats reports how interns selected on internship drives converted
+// into pre-placement offers, overall and per company.
+func (ps *PPOServiceImpl) GetPPOStats(companyID *primitive.ObjectID) (*PPOStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	jobMatch := bson.M{"job.job_type": models.JobTypeInternship}
+	if companyID != nil {
+		jobMatch["job.company_name.companyId"] = *companyID
+	}
+	countStatus := func(status string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$ppoStatus", status}}, 1, 0}}}
+	}
+
+	cursor, err := ps.applicationCollection.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"status": models.ApplicationStatusSelected}},
+		{"$lookup": bson.M{
+			"from":         "jobs",
+			"localField":   "job_id",
+			"foreignField": "_id",
+			"as":           "job",
+		}},
+		{"$unwind": "$job"},
+		{"$match": jobMatch},
+		{"$addFields": bson.M{"ppoStatus": bson.M{"$ifNull": bson.A{"$ppo.status", models.PPOStatusPending}}}},
+		{"$group": bson.M{
+			"_id":         "$job.company_name.companyId",
+			"companyName": bson.M{"$first": "$job.company_name.name"},
+			"interns":     bson.M{"$sum": 1},
+			"ppoPossible": bson.M{"$sum": bson.M{"$cond": bson.A{"$job.internship.ppo_possible", 1, 0}}},
+			"pending":     countStatus(models.PPOStatusPending),
+			"offered":     countStatus(models.PPOStatusOffered),
+			"notOffered":  countStatus(models.PPOStatusNotOffered),
+			"accepted":    countStatus(models.PPOStatusAccepted),
+			"declined":    countStatus(models.PPOStatusDeclined),
+		}},
+		{"$sort": bson.M{"interns": -1}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var companies []*CompanyPPOStats
+	if err = cursor.All(ctx, &companies); err != nil {
+		return nil, err
+	}
+
+	stats := &PPOStats{ByCompany: []*CompanyPPOStats{}}
+	for _, company := range companies {
+		company.computeRates()
+		stats.Interns += company.Interns
+		stats.PPOPossible += company.PPOPossible
+		stats.Pending += company.Pending
+		stats.Offered += company.Offered
+		stats.NotOffered += company.NotOffered
+		stats.Accepted += company.Accepted
+		stats.Declined += company.Declined
+		stats.ByCompany = append(stats.ByCompany, company)
+	}
+	stats.computeRates()
+	return stats, nil
+}
+
+// computeRates fills the conversion rate (interns offered a PPO, whatever
+// they answered) and the acceptance rate of the offers made.
+func (s *PPOCounts) computeRates() {
+	extended := s.Offered + s.Accepted + s.Declined
+	if s.Interns > 0 {
+		s.ConversionRate = roundPercent(extended, s.Interns)
+	}
+	if answered := s.Accepted + s.Declined; answered > 0 {
+		s.AcceptanceRate = roundPercent(s.Accepted, answered)
+	}
+}
+
+func roundPercent(part, whole int64) float64 {
+	return math.Round(float64(part)*10000/float64(whole)) / 100
+}
+
+func containsString(values []string, v string) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,256 @@
+﻿package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Weights of the recommendation signals. A drive that matches on everything
+// scores 100.
+const (
+	recommendEligibilityWeight = 40.0
+	recommendSkillWeight       = 35.0
+	recommendLocationWeight    = 15.0
+	recommendHistoryWeight     = 10.0
+)
+
+type RecommendationServiceImpl struct {
+	jobCollection         *mongo.Collection
+	userCollection        *mongo.Collection
+	resumeCollection      *mongo.Collection
+	applicationCollection *mongo.Collection
+}
+
+func NewRecommendationService(db *mongo.Database) RecommendationService {
+	return &RecommendationServiceImpl{
+		jobCollection:         db.Collection("jobs"),
+		userCollection:        db.Collection("users"),
+		resumeCollection:      db.Collection("resumes"),
+		applicationCollection: db.Collection("applications"),
+	}
+}
+
+// applicationHistory summarises the drives a student applied to before.
+type applicationHistory struct {
+	applied   map[primitive.ObjectID]bool
+	companies map[primitive.ObjectID]bool
+	jobTypes  map[string]int
+	locations map[string]int
+	total     int
+}
+
+// RecommendJobs ranks the open drives the student has not applied to yet.
+// Ineligible drives are kept at the bottom of the ranking so the student can
+// see what holds them back. The count is the number of drives ranked, before
+// the limit is applied.
+func (rs *RecommendationServiceImpl) RecommendJobs(studentID primitive.ObjectID, limit int) ([]*JobRecommendation, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if limit < 1 || limit > MaxListLimit {
+		return nil, 0, newValidationError("limit", fmt.Sprintf("Limit must be a number between 1 and %d", MaxListLimit))
+	}
+
+	var student models.User
+	if err := rs.userCollection.FindOne(ctx, bson.M{"_id": studentID, "role": "student"}).Decode(&student); err != nil {
+		return nil, 0, err
+	}
+
+	skills, err := rs.studentSkills(ctx, &student)
+	if err != nil {
+		return nil, 0, err
+	}
+	history, err := rs.applicationHistory(ctx, studentID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cursor, err := rs.jobCollection.Find(ctx, bson.M{
+		"status":               models.JobStatusOpen,
+		"application_deadline": bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []models.Job
+	if err = cursor.All(ctx, &jobs); err != nil {
+		return nil, 0, err
+	}
+
+	recommendations := make([]*JobRecommendation, 0, len(jobs))
+	for i := range jobs {
+		if history.applied[jobs[i].ID] {
+			continue
+		}
+		recommendations = append(recommendations, scoreJob(&jobs[i], &student, skills, history))
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		a, b := recommendations[i], recommendations[j]
+		if a.Eligible != b.Eligible {
+			return a.Eligible
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Job.ApplicationDeadline.Before(b.Job.ApplicationDeadline)
+	})
+	total := int64(len(recommendations))
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+	return recommendations, total, nil
+}
+
+// studentSkills merges the skills on the profile with the ones parsed from
+// the student's active resumes.
+func (rs *RecommendationServiceImpl) studentSkills(ctx context.Context, student *models.User) ([]string, error) {
+	skills := append([]string{}, student.Skills...)
+	if len(student.ActiveResumeID) == 0 {
+		return dedupeStrings(skills), nil
+	}
+
+	cursor, err := rs.resumeCollection.Find(ctx, bson.M{"_id": bson.M{"$in": student.ActiveResumeID}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var resumes []models.Resume
+	if err = cursor.All(ctx, &resumes); err != nil {
+		return nil, err
+	}
+	for _, resume := range resumes {
+		skills = append(skills, resume.ParsedData.Skills...)
+	}
+	return dedupeStrings(skills), nil
+}
+
+func (rs *RecommendationServiceImpl) applicationHistory(ctx context.Context, studentID primitive.ObjectID) (*applicationHistory, error) {
+	history := &applicationHistory{
+		applied:   make(map[primitive.ObjectID]bool),
+		companies: make(map[primitive.ObjectID]bool),
+		jobTypes:  make(map[string]int),
+		locations: make(map[string]int),
+	}
+
+	jobIDs, err := rs.applicationCollection.Distinct(ctx, "job_id", bson.M{"student_id": studentID})
+	if err != nil {
+		return nil, err
+	}
+	if len(jobIDs) == 0 {
+		return history, nil
+	}
+
+	cursor, err := rs.jobCollection.Find(ctx, bson.M{"_id": bson.M{"$in": jobIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []models.Job
+	if err = cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		history.applied[job.ID] = true
+		history.total++
+		if !job.CompanyName.CompanyID.IsZero() {
+			history.companies[job.CompanyName.CompanyID] = true
+		}
+		if jobType := normalizeKey(string(job.JobType)); jobType != "" {
+			history.jobTypes[jobType]++
+		}
+		if location := normalizeKey(job.Location); location != "" {
+			history.locations[location]++
+		}
+	}
+	return history, nil
+}
+
+func scoreJob(job *models.Job, student *models.User, skills []string, history *applicationHistory) *JobRecommendation {
+	rec := &JobRecommendation{Job: job, Eligible: true, Reasons: []string{}}
+	var score float64
+
+	if failures := eligibilityFailures(student, job.Eligibility); len(failures) > 0 {
+		rec.Eligible = false
+		for _, failure := range failures {
+			rec.Reasons = append(rec.Reasons, "Not eligible: "+failure)
+		}
+	} else {
+		score += recommendEligibilityWeight
+		rec.Reasons = append(rec.Reasons, "You meet the eligibility criteria")
+	}
+
+	if len(job.Eligibility.Skills) > 0 {
+		for _, skill := range job.Eligibility.Skills {
+			if containsFold(skills, skill) {
+				rec.MatchedSkills = append(rec.MatchedSkills, skill)
+			} else {
+				rec.MissingSkills = append(rec.MissingSkills, skill)
+			}
+		}
+		if len(rec.MatchedSkills) > 0 {
+			score += recommendSkillWeight * float64(len(rec.MatchedSkills)) / float64(len(job.Eligibility.Skills))
+			rec.Reasons = append(rec.Reasons, fmt.Sprintf("Matches %d of %d required skills: %s",
+				len(rec.MatchedSkills), len(job.Eligibility.Skills), strings.Join(rec.MatchedSkills, ", ")))
+		}
+	} else {
+		// A drive that asks for no particular skills is open to everyone, so
+		// it gets half of the skill weight.
+		score += recommendSkillWeight / 2
+	}
+
+	if job.Location != "" && locationPreferred(student.PreferredLocations, job.Location) {
+		score += recommendLocationWeight
+		rec.Reasons = append(rec.Reasons, fmt.Sprintf("Located in %s, one of your preferred locations", job.Location))
+	}
+
+	if history.total > 0 {
+		var affinity float64
+		if history.companies[job.CompanyName.CompanyID] {
+			affinity += 0.5
+			rec.Reasons = append(rec.Reasons, fmt.Sprintf("You applied to %s before", job.CompanyName.Name))
+		}
+		if n := history.jobTypes[normalizeKey(string(job.JobType))]; n > 0 {
+			affinity += 0.3 * float64(n) / float64(history.total)
+			rec.Reasons = append(rec.Reasons, fmt.Sprintf("Similar to the %s roles you applied for", job.JobType))
+		}
+		if n := history.locations[normalizeKey(job.Location)]; n > 0 {
+			affinity += 0.2 * float64(n) / float64(history.total)
+		}
+		score += recommendHistoryWeight * affinity
+	}
+
+	rec.Score = math.Round(score*10) / 10
+	return rec
+}
+
+// locationPreferred reports whether location matches one of the preferences.
+// Drive locations are free text ("Bengaluru / Remote"), so a preference
+// contained in the location also counts.
+func locationPreferred(preferred []string, location string) bool {
+	location = normalizeKey(location)
+	for _, p := range preferred {
+		if p = normalizeKey(p); p != "" && strings.Contains(location, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeKey(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
@@ -0,0 +1,349 @@
+﻿package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/storage"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ResumeLinkTTL = 15 * time.Minute
+
+var (
+	ErrInvalidLink = errors.New("invalid link")
+	ErrLinkExpired = errors.New("link expired")
+)
+
+var ResumeDownloadListSpec = ListSpec{
+	Sorts:       map[string]string{"downloadedAt": "downloaded_at"},
+	DefaultSort: "-downloadedAt",
+}
+
+type ResumeAccessServiceImpl struct {
+	resumeCollection      *mongo.Collection
+	userCollection        *mongo.Collection
+	jobCollection         *mongo.Collection
+	applicationCollection *mongo.Collection
+	companyCollection     *mongo.Collection
+	downloadCollection    *mongo.Collection
+	store                 storage.Storage
+	httpClient            *http.Client
+}
+
+func NewResumeAccessService(db *mongo.Database) ResumeAccessService {
+	return &ResumeAccessServiceImpl{
+		resumeCollection:      db.Collection("resumes"),
+		userCollection:        db.Collection("users"),
+		jobCollection:         db.Collection("jobs"),
+		applicationCollection: db.Collection("applications"),
+		companyCollection:     db.Collection("companies"),
+		downloadCollection:    db.Collection("resume_downloads"),
+		store:                 storage.Default(),
+		httpClient:            resumeFetchClient(),
+	}
+}
+
+func EnsureResumeDownloadIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := db.Collection("resume_downloads").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "student_id", Value: 1}, {Key: "downloaded_at", Value: -1}},
+	})
+	return err
+}
+
+// resumeLinkSecret signs download links. It defaults to the JWT secret so
+// no extra configuration is needed.
+func resumeLinkSecret() []byte {
+	if secret := os.Getenv("RESUME_LINK_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	return []byte(os.Getenv("JWT_SECRET"))
+}
+
+func signResumeLink(resumeID, viewerID primitive.ObjectID, expires int64) string {
+	mac := hmac.New(sha256.New, resumeLinkSecret())
+	fmt.Fprintf(mac, "%s|%s|%d", resumeID.Hex(), viewerID.Hex(), expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreateResumeLink checks that viewer may see the resume and returns a URL
+// that downloads it without further authentication until it expires. The
+// link is bound to the viewer so the download log names who opened it.
+func (as *ResumeAccessServiceImpl) CreateResumeLink(resumeID, viewerID primitive.ObjectID) (*ResumeLink, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resume, viewer, err := as.authorize(ctx, resumeID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(ResumeLinkTTL)
+	query := url.Values{}
+	query.Set("viewer", viewer.ID.Hex())
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("sig", signResumeLink(resume.ID, viewer.ID, expiresAt.Unix()))
+	return &ResumeLink{
+		URL:       "/api/v1/resumes/" + resume.ID.Hex() + "/download?" + query.Encode(),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// OpenSignedResume verifies a link from CreateResumeLink, checks access again
+// in case it was revoked since, and logs the download.
+func (as *ResumeAccessServiceImpl) OpenSignedResume(resumeID primitive.ObjectID, link SignedResumeLink) (*ResumeFile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	viewerID, err := primitive.ObjectIDFromHex(link.ViewerID)
+	if err != nil {
+		return nil, ErrInvalidLink
+	}
+	expires, err := strconv.ParseInt(link.Expires, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidLink
+	}
+	expected := signResumeLink(resumeID, viewerID, expires)
+	if !hmac.Equal([]byte(expected), []byte(link.Signature)) {
+		return nil, ErrInvalidLink
+	}
+	if time.Now().Unix() > expires {
+		return nil, ErrLinkExpired
+	}
+
+	resume, viewer, err := as.authorize(ctx, resumeID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The download streams after this call returns, so it must not use the
+	// lookup's context.
+	body, ext, contentType, err := openResumeFile(context.Background(), as.store, as.httpClient, resume)
+	if err != nil {
+		return nil, err
+	}
+
+	err = logResumeDownloads(ctx, as.companyCollection, as.downloadCollection, viewer, []models.Resume{*resume}, link.IPAddress, link.UserAgent)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+
+	name := resume.ResumeName
+	if name == "" {
+		name = "resume"
+	}
+	return &ResumeFile{
+		Body:        body,
+		FileName:    strings.TrimSuffix(name, ext) + ext,
+		ContentType: contentType,
+		Size:        resume.Size,
+	}, nil
+}
+
+// authorize lets the owner, a TPO of the student's department, an admin,
+// or a recruiter whose company has a drive the student applied to see the
+// resume.
+func (as *ResumeAccessServiceImpl) authorize(ctx context.Context, resumeID, viewerID primitive.ObjectID) (*models.Resume, *models.User, error) {
+	var resume models.Resume
+	err := as.resumeCollection.FindOne(ctx, bson.M{"_id": resumeID},
+		options.FindOne().SetProjection(bson.M{"ocr_text": 0, "parsed_data": 0})).Decode(&resume)
+	if err != nil {
+		return nil, nil, err
+	}
+	var viewer models.User
+	if err := as.userCollection.FindOne(ctx, bson.M{"_id": viewerID}).Decode(&viewer); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil, ErrForbidden
+		}
+		return nil, nil, err
+	}
+
+	switch viewer.Role {
+	case "student":
+		if resume.StudentID == viewer.ID {
+			return &resume, &viewer, nil
+		}
+	case "admin":
+		return &resume, &viewer, nil
+	case "tpo":
+		var student models.User
+		if err := as.userCollection.FindOne(ctx, bson.M{"_id": resume.StudentID}).Decode(&student); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, nil, ErrForbidden
+			}
+			return nil, nil, err
+		}
+		if viewer.Department != nil && student.Department != nil && strings.EqualFold(*viewer.Department, *student.Department) {
+			return &resume, &viewer, nil
+		}
+	case "rec":
+		if viewer.CompanyID == nil {
+			break
+		}
+		applied, err := as.appliedToCompany(ctx, resume.StudentID, *viewer.CompanyID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if applied {
+			return &resume, &viewer, nil
+		}
+	}
+	return nil, nil, ErrForbidden
+}
+
+func (as *ResumeAccessServiceImpl) appliedToCompany(ctx context.Context, studentID, companyID primitive.ObjectID) (bool, error) {
+	jobIDs, err := as.jobCollection.Distinct(ctx, "_id", bson.M{"company_name.companyId": companyID})
+	if err != nil {
+		return false, err
+	}
+	if len(jobIDs) == 0 {
+		return false, nil
+	}
+	count, err := as.applicationCollection.CountDocuments(ctx,
+		bson.M{"student_id": studentID, "job_id": bson.M{"$in": jobIDs}},
+		options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// logResumeDownloads records that the viewer downloaded each of the resumes.
+func logResumeDownloads(ctx context.Context, companies, downloads *mongo.Collection, viewer *models.User, resumes []models.Resume, ipAddress, userAgent string) error {
+	if len(resumes) == 0 {
+		return nil
+	}
+
+	companyName := ""
+	if viewer.Role == "rec" && viewer.CompanyID != nil {
+		var company models.Company
+		if err := companies.FindOne(ctx, bson.M{"_id": *viewer.CompanyID}).Decode(&company); err == nil {
+			companyName = company.Name
+		}
+	}
+
+	now := time.Now()
+	records := make([]interface{}, len(resumes))
+	for i, resume := range resumes {
+		records[i] = models.ResumeDownload{
+			ResumeID:     resume.ID,
+			ResumeName:   resume.ResumeName,
+			StudentID:    resume.StudentID,
+			ViewerID:     viewer.ID,
+			ViewerRole:   viewer.Role,
+			ViewerName:   strings.TrimSpace(viewer.FirstName + " " + viewer.LastName),
+			CompanyName:  companyName,
+			IPAddress:    ipAddress,
+			UserAgent:    userAgent,
+			DownloadedAt: now,
+		}
+	}
+	_, err := downloads.InsertMany(ctx, records)
+	return err
+}
+
+func (as *ResumeAccessServiceImpl) ListResumeDownloads(studentID primitive.ObjectID, q *ListQuery) (*ListPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	page, _, err := FindPage[*models.ResumeDownload](ctx, as.downloadCollection, bson.M{"student_id": studentID}, q)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// resumeFetchClient downloads resumes registered by URL. Redirects are only
+// followed within the hosts resumes may be fetched from.
+func resumeFetchClient() *http.Client {
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+			if !resumeURLAllowed(req.URL) {
+				return fmt.Errorf("redirect to %s is not allowed", req.URL.Host)
+			}
+			return nil
+		},
+	}
+}
+
+// resumeURLAllowed reports whether a resume registered by URL may be fetched
+// from u. Only Cloudinary, where resumes were uploaded before the file
+// store existed, is trusted; anything else could point the server at
+// internal addresses.
+func resumeURLAllowed(u *url.URL) bool {
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == "cloudinary.com" || strings.HasSuffix(host, ".cloudinary.com")
+}
+
+// openResumeFile opens an uploaded resume from the store, or downloads one
+// that was registered by a Cloudinary URL. It returns the body with the
+// file's extension and content type.
+func openResumeFile(ctx context.Context, store storage.Storage, client *http.Client, resume *models.Resume) (io.ReadCloser, string, string, error) {
+	if resume.StorageKey != "" {
+		body, err := store.Open(ctx, resume.StorageKey)
+		if err == storage.ErrNotFound {
+			return nil, "", "", mongo.ErrNoDocuments
+		}
+		if err != nil {
+			return nil, "", "", err
+		}
+		return body, path.Ext(resume.StorageKey), resume.ContentType, nil
+	}
+
+	if u, err := url.Parse(resume.FileURL); err != nil || !resumeURLAllowed(u) {
+		return nil, "", "", mongo.ErrNoDocuments
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resume.FileURL, nil)
+	if err != nil {
+		return nil, "", "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, "", "", mongo.ErrNoDocuments
+		}
+		return nil, "", "", fmt.Errorf("fetching resume returned %s", resp.Status)
+	}
+
+	ext := strings.ToLower(path.Ext(req.URL.Path))
+	contentType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		contentType = "application/octet-stream"
+	}
+	if ext == "" {
+		if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	return resp.Body, ext, contentType, nil
+}
@@ -0,0 +1,194 @@
+﻿package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"backend/models"
+	"backend/pdf"
+)
+
+const DefaultResumeTemplate = "classic"
+
+type resumeTemplate struct {
+	body, bold pdf.Font
+	// centered puts the header in the middle of the page.
+	centered bool
+	accent   [3]float64
+}
+
+var resumeTemplates = map[string]resumeTemplate{
+	"classic": {body: pdf.TimesRoman, bold: pdf.TimesBold, centered: true},
+	"modern":  {body: pdf.Helvetica, bold: pdf.HelveticaBold, accent: [3]float64{0.12, 0.31, 0.55}},
+}
+
+// ResumeTemplates lists the templates GenerateResume accepts.
+var ResumeTemplates = []ResumeTemplateInfo{
+	{Name: "classic", Description: "Serif type with a centred header, the college's standard format"},
+	{Name: "modern", Description: "Sans-serif type with coloured section headings"},
+}
+
+const (
+	resumeMargin   = 50.0
+	resumeBodySize = 10.5
+	resumeLeading  = 14.0
+)
+
+// resumeLayout places lines top to bottom, starting a new page when one is
+// full. It also keeps the plain text so the resume can be searched.
+type resumeLayout struct {
+	doc      *pdf.Document
+	tpl      resumeTemplate
+	y        float64
+	plain    strings.Builder
+	sections int
+}
+
+func newResumeLayout(tpl resumeTemplate, title string) *resumeLayout {
+	l := &resumeLayout{doc: pdf.New(), tpl: tpl}
+	l.doc.Title = title
+	l.doc.Author = title
+	l.newPage()
+	return l
+}
+
+func (l *resumeLayout) newPage() {
+	l.doc.AddPage()
+	l.y = pdf.PageHeight - resumeMargin
+}
+
+func (l *resumeLayout) width() float64 {
+	return pdf.PageWidth - 2*resumeMargin
+}
+
+// line draws text at the indent, moving down by leading first.
+func (l *resumeLayout) line(font pdf.Font, size, leading, indent float64, text string, centered bool) {
+	if l.y-leading < resumeMargin {
+		l.newPage()
+	}
+	l.y -= leading
+	x := resumeMargin + indent
+	if centered {
+		x = (pdf.PageWidth - pdf.Width(font, size, text)) / 2
+	}
+	l.doc.Text(x, l.y, font, size, text)
+	l.plain.WriteString(text)
+	l.plain.WriteByte('\n')
+}
+
+// paragraph wraps text to the page width less the indent.
+func (l *resumeLayout) paragraph(font pdf.Font, indent float64, text string) {
+	for _, line := range pdf.Wrap(font, resumeBodySize, text, l.width()-indent) {
+		l.line(font, resumeBodySize, resumeLeading, indent, line, false)
+	}
+}
+
+func (l *resumeLayout) heading(title string) {
+	// Keep a heading on the page of the first line below it.
+	if l.y-3*resumeLeading-12 < resumeMargin {
+		l.newPage()
+	} else if l.sections > 0 {
+		l.y -= 8
+	}
+	l.sections++
+
+	l.doc.SetColor(l.tpl.accent[0], l.tpl.accent[1], l.tpl.accent[2])
+	l.line(l.tpl.bold, 12, 16, 0, strings.ToUpper(title), false)
+	l.doc.Line(resumeMargin, l.y-4, pdf.PageWidth-resumeMargin, l.y-4, 0.75)
+	l.doc.SetColor(0, 0, 0)
+	l.y -= 4
+}
+
+// renderResume lays the student's profile out as a one or two page PDF and
+// returns it with its plain text.
+func renderResume(student *models.User, tpl resumeTemplate) ([]byte, string, error) {
+	name := strings.TrimSpace(student.FirstName + " " + student.LastName)
+	l := newResumeLayout(tpl, name)
+
+	l.doc.SetColor(l.tpl.accent[0], l.tpl.accent[1], l.tpl.accent[2])
+	l.line(tpl.bold, 20, 24, 0, name, tpl.centered)
+	l.doc.SetColor(0, 0, 0)
+
+	var details []string
+	if student.RollNumber != nil && *student.RollNumber != "" {
+		details = append(details, "Roll No. "+*student.RollNumber)
+	}
+	if student.Department != nil && *student.Department != "" {
+		details = append(details, *student.Department)
+	}
+	if student.CGPA != nil {
+		details = append(details, "CGPA "+formatCGPA(student.CGPA))
+	}
+	details = append(details, student.Email)
+	l.line(tpl.body, resumeBodySize, 16, 0, strings.Join(details, "  |  "), tpl.centered)
+	if !tpl.centered {
+		l.doc.Line(resumeMargin, l.y-8, pdf.PageWidth-resumeMargin, l.y-8, 1.5)
+	}
+	l.y -= 10
+
+	if len(student.Education) > 0 || student.CGPA != nil {
+		l.heading("Education")
+		for _, education := range student.Education {
+			title := strings.TrimSpace(education.Degree)
+			if education.Year != "" {
+				title += " (" + education.Year + ")"
+			}
+			l.paragraph(tpl.bold, 0, title)
+			var line []string
+			if education.Institution != "" {
+				line = append(line, education.Institution)
+			}
+			if education.GPA != nil && fmt.Sprint(education.GPA) != "" {
+				line = append(line, "Score: "+fmt.Sprint(education.GPA))
+			}
+			if len(line) > 0 {
+				l.paragraph(tpl.body, 0, strings.Join(line, ", "))
+			}
+		}
+		if len(student.Education) == 0 {
+			current := "Current programme"
+			if student.Department != nil && *student.Department != "" {
+				current = *student.Department
+			}
+			l.paragraph(tpl.body, 0, current+", CGPA "+formatCGPA(student.CGPA))
+		}
+	}
+
+	if len(student.Skills) > 0 {
+		l.heading("Skills")
+		l.paragraph(tpl.body, 0, strings.Join(dedupeStrings(student.Skills), ", "))
+	}
+
+	if len(student.Projects) > 0 {
+		l.heading("Projects")
+		for _, project := range student.Projects {
+			l.paragraph(tpl.bold, 0, project.Title)
+			if len(project.Technologies) > 0 {
+				l.paragraph(tpl.body, 12, "Technologies: "+strings.Join(project.Technologies, ", "))
+			}
+			if project.Description != "" {
+				l.paragraph(tpl.body, 12, project.Description)
+			}
+			if project.Link != "" {
+				l.paragraph(tpl.body, 12, project.Link)
+			}
+		}
+	}
+
+	if len(student.Qualifications) > 0 {
+		l.heading("Qualifications")
+		for _, qualification := range student.Qualifications {
+			l.paragraph(tpl.bold, 0, qualification.Title)
+			if qualification.Description != "" {
+				l.paragraph(tpl.body, 12, qualification.Description)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	if _, err := l.doc.WriteTo(&out); err != nil {
+		return nil, "", err
+	}
+	return out.Bytes(), l.plain.String(), nil
+}
@@ -0,0 +1,65 @@
+﻿package services
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"backend/models"
+)
+
+var resumePageCount = regexp.MustCompile(`/Type /Pages /Kids \[[^\]]*\] /Count (\d+)`)
+
+func TestRenderResume(t *testing.T) {
+	department, cgpa := "Computer Science", 8.7
+	student := &models.User{
+		FirstName:  "Jane",
+		LastName:   "D'Souza",
+		Email:      "jane@example.com",
+		Department: &department,
+		CGPA:       &cgpa,
+		Skills:     []string{"Go", "C++", "go"},
+		Education:  []models.Education{{Degree: "B.Tech (CSE)", Institution: "NIT", Year: "2026", GPA: 8.7}},
+	}
+
+	tests := []struct {
+		name     string
+		projects int
+		pages    string
+	}{
+		{"short profile", 1, "1"},
+		{"long profile", 30, "2"},
+	}
+	for _, tt := range tests {
+		for name, tpl := range resumeTemplates {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				student.Projects = nil
+				for i := 1; i <= tt.projects; i++ {
+					student.Projects = append(student.Projects, models.Project{
+						Title:        fmt.Sprintf("Project %d (capstone)", i),
+						Description:  "Built a placement portal with a REST API and a Flutter client.",
+						Technologies: []string{"Go", "MongoDB"},
+					})
+				}
+
+				data, text, err := renderResume(student, tpl)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if m := resumePageCount.FindSubmatch(data); m == nil || string(m[1]) != tt.pages {
+					t.Errorf("page count = %s, want %s", m, tt.pages)
+				}
+				if !bytes.Contains(data, []byte(`(Project 1 \(capstone\)) Tj`)) {
+					t.Error("the project title is not escaped in the PDF")
+				}
+				for _, want := range []string{"Jane D'Souza", "B.Tech (CSE) (2026)", "Go, C++", "Project 1 (capstone)"} {
+					if !strings.Contains(text, want) {
+						t.Errorf("text does not contain %q", want)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,489 @@
+﻿package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/storage"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// ResumeExportSyncLimit is the most candidates a ZIP is streamed for
+	// directly; larger exports are built in the background.
+	ResumeExportSyncLimit = 25
+	ResumeExportTTL       = 24 * time.Hour
+
+	// maxExportResumeSize caps a single resume in an archive. Resumes linked
+	// by URL are not size checked on upload.
+	maxExportResumeSize = 10 << 20
+)
+
+// ErrExportExpired is returned for exports whose archive was already
+// removed.
+var ErrExportExpired = errors.New("export expired")
+
+type ResumeExportServiceImpl struct {
+	jobCollection         *mongo.Collection
+	applicationCollection *mongo.Collection
+	userCollection        *mongo.Collection
+	resumeCollection      *mongo.Collection
+	exportCollection      *mongo.Collection
+	companyCollection     *mongo.Collection
+	downloadCollection    *mongo.Collection
+	store                 storage.Storage
+	httpClient            *http.Client
+}
+
+func NewResumeExportService(db *mongo.Database) ResumeExportService {
+	return &ResumeExportServiceImpl{
+		jobCollection:         db.Collection("jobs"),
+		applicationCollection: db.Collection("applications"),
+		userCollection:        db.Collection("users"),
+		resumeCollection:      db.Collection("resumes"),
+		exportCollection:      db.Collection("resume_exports"),
+		companyCollection:     db.Collection("companies"),
+		downloadCollection:    db.Collection("resume_downloads"),
+		store:                 storage.Default(),
+		httpClient:            resumeFetchClient(),
+	}
+}
+
+// ExportCandidates loads the company drive's applicants with one of the
+// statuses (all applicants when none are given) and the resume each one
+// applied with.
+func (es *ResumeExportServiceImpl) ExportCandidates(companyID, jobID primitive.ObjectID, statuses []string) (*ResumeExportSelection, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	statuses = dedupeStrings(statuses)
+	for _, status := range statuses {
+		if !validApplicationStatus(status) {
+			return nil, newValidationError("status", fmt.Sprintf("Unknown application status %q", status))
+		}
+	}
+
+	var job models.Job
+	if err := es.jobCollection.FindOne(ctx, jobFilter(jobID, &companyID)).Decode(&job); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"job_id": jobID}
+	if len(statuses) > 0 {
+		filter["status"] = bson.M{"$in": statuses}
+	}
+	cursor, err := es.applicationCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"applied_on": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var applications []models.Application
+	if err := cursor.All(ctx, &applications); err != nil {
+		return nil, err
+	}
+
+	studentIDs := make([]primitive.ObjectID, 0, len(applications))
+	for _, application := range applications {
+		studentIDs = append(studentIDs, application.StudentID)
+	}
+	students := make(map[primitive.ObjectID]*models.User, len(studentIDs))
+	if len(studentIDs) > 0 {
+		cursor, err := es.userCollection.Find(ctx, bson.M{"_id": bson.M{"$in": studentIDs}})
+		if err != nil {
+			return nil, err
+		}
+		var users []models.User
+		if err := cursor.All(ctx, &users); err != nil {
+			return nil, err
+		}
+		for i := range users {
+			students[users[i].ID] = &users[i]
+		}
+	}
+
+	// Older applications carry no resume; fall back to the primary one.
+	selection := &ResumeExportSelection{Job: &job, Statuses: statuses}
+	resumeIDs := []primitive.ObjectID{}
+	for _, application := range applications {
+		student, ok := students[application.StudentID]
+		if !ok {
+			continue
+		}
+		candidate := &ResumeExportCandidate{Student: student, Application: application}
+		if !application.ResumeID.IsZero() {
+			candidate.resumeID = application.ResumeID
+		} else if len(student.ActiveResumeID) > 0 {
+			candidate.resumeID = student.ActiveResumeID[0]
+		}
+		if !candidate.resumeID.IsZero() {
+			resumeIDs = append(resumeIDs, candidate.resumeID)
+		}
+		selection.Candidates = append(selection.Candidates, candidate)
+	}
+
+	if len(resumeIDs) > 0 {
+		cursor, err := es.resumeCollection.Find(ctx, bson.M{"_id": bson.M{"$in": resumeIDs}},
+			options.Find().SetProjection(bson.M{"ocr_text": 0, "parsed_data": 0}))
+		if err != nil {
+			return nil, err
+		}
+		var resumes []models.Resume
+		if err := cursor.All(ctx, &resumes); err != nil {
+			return nil, err
+		}
+		byID := make(map[primitive.ObjectID]*models.Resume, len(resumes))
+		for i := range resumes {
+			byID[resumes[i].ID] = &resumes[i]
+		}
+		for _, candidate := range selection.Candidates {
+			candidate.Resume = byID[candidate.resumeID]
+		}
+	}
+	return selection, nil
+}
+
+func validApplicationStatus(status string) bool {
+	switch status {
+	case models.ApplicationStatusApplied, models.ApplicationStatusShortlisted,
+		models.ApplicationStatusInterviewed, models.ApplicationStatusWaitlisted,
+		models.ApplicationStatusSelected, models.ApplicationStatusRejected,
+		models.ApplicationStatusIneligible:
+		return true
+	}
+	return false
+}
+
+// ExportFileName names the archive after the company and drive.
+func (s *ResumeExportSelection) ExportFileName() string {
+	name := exportNameSafe(s.Job.CompanyName.Name + "_" + s.Job.Position)
+	if len(s.Statuses) > 0 {
+		name += "_" + strings.Join(s.Statuses, "-")
+	}
+	return name + "_resumes.zip"
+}
+
+var exportUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func exportNameSafe(s string) string {
+	s = strings.Trim(exportUnsafe.ReplaceAllString(strings.TrimSpace(s), "_"), "_.")
+	if s == "" {
+		return "candidate"
+	}
+	return s
+}
+
+// WriteResumeZip writes one RollNo_Name file per candidate with a resume and
+// a manifest.csv describing every candidate. Resumes that cannot be read are
+// left out and marked missing in the manifest; it returns how many.
+func (es *ResumeExportServiceImpl) WriteResumeZip(ctx context.Context, w io.Writer, selection *ResumeExportSelection) (int, error) {
+	archive := zip.NewWriter(w)
+
+	manifest := [][]string{{"roll_number", "name", "email", "department", "cgpa", "status", "applied_on", "resume_file"}}
+	used := map[string]int{}
+	missing := 0
+	for _, candidate := range selection.Candidates {
+		if err := ctx.Err(); err != nil {
+			return missing, err
+		}
+		student := candidate.Student
+		name := strings.TrimSpace(student.FirstName + " " + student.LastName)
+
+		fileName, err := es.addResume(ctx, archive, candidate, used)
+		if err != nil {
+			if ctx.Err() != nil {
+				return missing, ctx.Err()
+			}
+			log.Printf("Resume export %s: skipping resume of %s: %v", selection.Job.ID.Hex(), student.ID.Hex(), err)
+			fileName = ""
+			missing++
+		}
+		candidate.FileName = fileName
+
+		manifest = append(manifest, []string{
+			derefString(student.RollNumber),
+			name,
+			student.Email,
+			derefString(student.Department),
+			formatCGPA(student.CGPA),
+			candidate.Application.Status,
+			candidate.Application.AppliedOn.Format(time.RFC3339),
+			fileName,
+		})
+	}
+
+	part, err := archive.Create("manifest.csv")
+	if err != nil {
+		return missing, err
+	}
+	writer := csv.NewWriter(part)
+	if err := writer.WriteAll(manifest); err != nil {
+		return missing, err
+	}
+	return missing, archive.Close()
+}
+
+func (es *ResumeExportServiceImpl) addResume(ctx context.Context, archive *zip.Writer, candidate *ResumeExportCandidate, used map[string]int) (string, error) {
+	resume := candidate.Resume
+	if resume == nil {
+		return "", errors.New("no resume")
+	}
+
+	body, ext, _, err := openResumeFile(ctx, es.store, es.httpClient, resume)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	if ext == "" {
+		ext = ".pdf"
+	}
+
+	// Read the whole file before adding an entry so a failed download does
+	// not leave a truncated resume in the archive.
+	data, err := io.ReadAll(io.LimitReader(body, maxExportResumeSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxExportResumeSize {
+		return "", errors.New("resume is too large")
+	}
+
+	student := candidate.Student
+	base := exportNameSafe(derefString(student.RollNumber) + "_" + student.FirstName + "_" + student.LastName)
+	used[base]++
+	if used[base] > 1 {
+		base = fmt.Sprintf("%s_%d", base, used[base])
+	}
+	fileName := base + ext
+
+	part, err := archive.Create(fileName)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", err
+	}
+	return fileName, nil
+}
+
+// ExportedResumeIDs lists the resumes WriteResumeZip put in the archive.
+func (s *ResumeExportSelection) ExportedResumeIDs() []primitive.ObjectID {
+	var ids []primitive.ObjectID
+	for _, candidate := range s.Candidates {
+		if candidate.FileName != "" {
+			ids = append(ids, candidate.Resume.ID)
+		}
+	}
+	return ids
+}
+
+// LogExportDownloads records a download by the recruiter of every resume in
+// an archive they were served.
+func (es *ResumeExportServiceImpl) LogExportDownloads(recruiterID primitive.ObjectID, resumeIDs []primitive.ObjectID, ipAddress, userAgent string) error {
+	if len(resumeIDs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var recruiter models.User
+	if err := es.userCollection.FindOne(ctx, bson.M{"_id": recruiterID}).Decode(&recruiter); err != nil {
+		return err
+	}
+	cursor, err := es.resumeCollection.Find(ctx,
+		bson.M{"_id": bson.M{"$in": resumeIDs}},
+		options.Find().SetProjection(bson.M{"resume_name": 1, "student_id": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	var resumes []models.Resume
+	if err := cursor.All(ctx, &resumes); err != nil {
+		return err
+	}
+	return logResumeDownloads(ctx, es.companyCollection, es.downloadCollection, &recruiter, resumes, ipAddress, userAgent)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatCGPA(cgpa *float64) string {
+	if cgpa == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*cgpa, 'f', -1, 64)
+}
+
+// QueueResumeExport records an export and builds it in the background. The
+// recruiter polls GetResumeExport and downloads it once it is ready.
+func (es *ResumeExportServiceImpl) QueueResumeExport(recruiterID primitive.ObjectID, selection *ResumeExportSelection) (*models.ResumeExport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	export := &models.ResumeExport{
+		ID:             primitive.NewObjectID(),
+		RecruiterID:    recruiterID,
+		JobID:          selection.Job.ID,
+		Statuses:       selection.Statuses,
+		Status:         models.ResumeExportPending,
+		CandidateCount: len(selection.Candidates),
+		FileName:       selection.ExportFileName(),
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(ResumeExportTTL),
+	}
+	if _, err := es.exportCollection.InsertOne(ctx, export); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		if err := es.buildExport(export, selection); err != nil {
+			log.Printf("Resume export %s failed: %v", export.ID.Hex(), err)
+		}
+	}()
+	return export, nil
+}
+
+func (es *ResumeExportServiceImpl) buildExport(export *models.ResumeExport, selection *ResumeExportSelection) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	key := fmt.Sprintf("exports/%s/%s.zip", export.RecruiterID.Hex(), export.ID.Hex())
+	missing, size, err := es.storeZip(ctx, key, selection)
+	if err != nil {
+		es.exportCollection.UpdateByID(context.Background(), export.ID, bson.M{"$set": bson.M{
+			"status": models.ResumeExportFailed,
+			"error":  "Could not build the archive, please try again",
+		}})
+		return err
+	}
+
+	_, err = es.exportCollection.UpdateByID(ctx, export.ID, bson.M{"$set": bson.M{
+		"status":        models.ResumeExportReady,
+		"storage_key":   key,
+		"size":          size,
+		"missing_count": missing,
+		"resume_ids":    selection.ExportedResumeIDs(),
+		"completed_at":  time.Now(),
+	}})
+	return err
+}
+
+// storeZip builds the archive in a temporary file so its size is known
+// before it is handed to the store.
+func (es *ResumeExportServiceImpl) storeZip(ctx context.Context, key string, selection *ResumeExportSelection) (int, int64, error) {
+	tmp, err := os.CreateTemp("", "resume-export-*.zip")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	missing, err := es.WriteResumeZip(ctx, tmp, selection)
+	if err != nil {
+		return 0, 0, err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+	if err := es.store.Put(ctx, key, tmp, size, "application/zip"); err != nil {
+		return 0, 0, err
+	}
+	return missing, size, nil
+}
+
+func (es *ResumeExportServiceImpl) GetResumeExport(recruiterID, exportID primitive.ObjectID) (*models.ResumeExport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var export models.ResumeExport
+	err := es.exportCollection.FindOne(ctx, bson.M{"_id": exportID, "recruiter_id": recruiterID}).Decode(&export)
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// OpenResumeExport opens a ready archive until it expires.
+func (es *ResumeExportServiceImpl) OpenResumeExport(recruiterID, exportID primitive.ObjectID) (*models.ResumeExport, io.ReadCloser, error) {
+	export, err := es.GetResumeExport(recruiterID, exportID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if time.Now().After(export.ExpiresAt) {
+		return nil, nil, ErrExportExpired
+	}
+	if export.Status != models.ResumeExportReady {
+		return nil, nil, newValidationError("export", "The export is not ready yet")
+	}
+
+	file, err := es.store.Open(context.Background(), export.StorageKey)
+	if err == storage.ErrNotFound {
+		return nil, nil, ErrExportExpired
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return export, file, nil
+}
+
+// DeleteExpiredResumeExports removes expired archives. Exports still pending
+// after an hour lost their builder to a restart and are marked failed.
+func (es *ResumeExportServiceImpl) DeleteExpiredResumeExports() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	now := time.Now()
+	if _, err := es.exportCollection.UpdateMany(ctx,
+		bson.M{"status": models.ResumeExportPending, "created_at": bson.M{"$lt": now.Add(-time.Hour)}},
+		bson.M{"$set": bson.M{"status": models.ResumeExportFailed, "error": "The export was interrupted, please try again"}},
+	); err != nil {
+		return 0, err
+	}
+
+	cursor, err := es.exportCollection.Find(ctx, bson.M{"expires_at": bson.M{"$lt": now}})
+	if err != nil {
+		return 0, err
+	}
+	var expired []models.ResumeExport
+	if err := cursor.All(ctx, &expired); err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, export := range expired {
+		if export.StorageKey != "" {
+			if err := es.store.Delete(ctx, export.StorageKey); err != nil {
+				return deleted, err
+			}
+		}
+		if _, err := es.exportCollection.DeleteOne(ctx, bson.M{"_id": export.ID}); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
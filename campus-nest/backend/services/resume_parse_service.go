@@ -0,0 +1,328 @@
+﻿package services
+
+import (
+	"context"
+	"io"
+	"log"
+	"path"
+	"sync"
+	"time"
+
+	"backend/models"
+	"backend/parser"
+	"backend/storage"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	MaxResumeParseAttempts = 3
+
+	// resumeParseLease is how long a worker owns a resume it is parsing.
+	// A resume whose worker died becomes pending again after it.
+	resumeParseLease = 5 * time.Minute
+)
+
+// resumeParseQueue hands freshly uploaded resumes to the parse workers. It
+// only speeds things up: the pending status in the database is the real
+// queue and the scheduler sweeps it, so nothing is lost if a send is dropped
+// or the process restarts.
+var (
+	resumeParseQueue   chan primitive.ObjectID
+	resumeParseQueueMu sync.Mutex
+)
+
+type ResumeParseServiceImpl struct {
+	resumeCollection      *mongo.Collection
+	applicationCollection *mongo.Collection
+	store                 storage.Storage
+	parser                parser.Parser
+	skills                *skillTaxonomy
+}
+
+func NewResumeParseService(db *mongo.Database) ResumeParseService {
+	return &ResumeParseServiceImpl{
+		resumeCollection:      db.Collection("resumes"),
+		applicationCollection: db.Collection("applications"),
+		store:                 storage.Default(),
+		parser:                parser.Default(),
+		skills:                newSkillTaxonomy(db),
+	}
+}
+
+func EnsureResumeParseIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := db.Collection("resumes").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "parse_status", Value: 1}, {Key: "parse_after", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{"parse_status": models.ResumeParsePending}),
+	})
+	return err
+}
+
+// StartResumeParseWorkers starts workers that parse queued resumes until
+// the returned stop function is called.
+func StartResumeParseWorkers(db *mongo.Database, workers int) (stop func()) {
+	queue := make(chan primitive.ObjectID, 100)
+	resumeParseQueueMu.Lock()
+	resumeParseQueue = queue
+	resumeParseQueueMu.Unlock()
+
+	service := NewResumeParseService(db)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for resumeID := range queue {
+				if err := service.ParseResume(resumeID); err != nil {
+					log.Printf("Resume %s: parse failed: %v", resumeID.Hex(), err)
+				}
+			}
+		}()
+	}
+
+	return func() {
+		resumeParseQueueMu.Lock()
+		resumeParseQueue = nil
+		resumeParseQueueMu.Unlock()
+		close(queue)
+		wg.Wait()
+	}
+}
+
+// enqueueResumeParse tells a worker about a pending resume. It never
+// blocks; when the queue is full or no workers run, the next sweep picks
+// the resume up.
+func enqueueResumeParse(resumeID primitive.ObjectID) bool {
+	resumeParseQueueMu.Lock()
+	defer resumeParseQueueMu.Unlock()
+	if resumeParseQueue == nil {
+		return false
+	}
+	select {
+	case resumeParseQueue <- resumeID:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseResume parses one pending resume. Concurrent calls for the same
+// resume, from this or another instance, are harmless: only the one that
+// claims the resume does any work. Without a parser the resume is left
+// pending.
+func (ps *ResumeParseServiceImpl) ParseResume(resumeID primitive.ObjectID) error {
+	if ps.parser == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resumeParseLease)
+	defer cancel()
+
+	now := time.Now()
+	var resume models.Resume
+	err := ps.resumeCollection.FindOneAndUpdate(ctx,
+		bson.M{
+			"_id":          resumeID,
+			"parse_status": models.ResumeParsePending,
+			"$or": []bson.M{
+				{"parse_after": bson.M{"$exists": false}},
+				{"parse_after": bson.M{"$lte": now}},
+			},
+		},
+		bson.M{
+			"$set": bson.M{"parse_after": now.Add(resumeParseLease)},
+			"$inc": bson.M{"parse_attempts": 1},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&resume)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := ps.parse(ctx, &resume)
+	if err != nil {
+		return ps.recordFailure(&resume, err)
+	}
+
+	saveCtx, saveCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer saveCancel()
+	result.Parsed.Skills, err = ps.skills.normalize(saveCtx, result.Parsed.Skills)
+	if err != nil {
+		return err
+	}
+	_, err = ps.resumeCollection.UpdateByID(saveCtx, resumeID, bson.M{
+		"$set": bson.M{
+			"parse_status": models.ResumeParseDone,
+			"parsed_data":  result.Parsed,
+			"ocr_text":     result.Text,
+			"parsed_at":    time.Now(),
+		},
+		"$unset": bson.M{"parse_after": "", "parse_error": ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Applications sent before parsing finished were scored without the
+	// resume's skills; clearing the score gets them rescored.
+	_, err = ps.applicationCollection.UpdateMany(saveCtx, bson.M{"resume_id": resumeID}, bson.M{"$unset": bson.M{"match": ""}})
+	if err != nil {
+		return err
+	}
+	// Their snapshots get the parsed data too; it describes the file that
+	// was submitted, so it is not a later change.
+	_, err = ps.applicationCollection.UpdateMany(saveCtx,
+		bson.M{"resume_id": resumeID, "snapshot.resume._id": resumeID, "snapshot.resume.parse_status": bson.M{"$ne": models.ResumeParseDone}},
+		bson.M{"$set": bson.M{
+			"snapshot.resume.parse_status": models.ResumeParseDone,
+			"snapshot.resume.parsed_data":  result.Parsed,
+		}},
+	)
+	return err
+}
+
+func (ps *ResumeParseServiceImpl) parse(ctx context.Context, resume *models.Resume) (*parser.Result, error) {
+	file, err := ps.store.Open(ctx, resume.StorageKey)
+	if err == storage.ErrNotFound {
+		return nil, &parser.PermanentError{Message: "the uploaded file is missing"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	body, err := io.ReadAll(io.LimitReader(file, MaxResumeSize+1))
+	if err != nil {
+		return nil, err
+	}
+	return ps.parser.Parse(ctx, path.Base(resume.StorageKey), resume.ContentType, body)
+}
+
+// recordFailure marks the resume failed once retrying cannot help, and
+// otherwise leaves it pending with a growing delay before the next sweep
+// retries it.
+func (ps *ResumeParseServiceImpl) recordFailure(resume *models.Resume, cause error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{"parse_error": cause.Error()}
+	unset := bson.M{}
+	if parser.IsPermanent(cause) || resume.ParseAttempts >= MaxResumeParseAttempts {
+		update["parse_status"] = models.ResumeParseFailed
+		unset["parse_after"] = ""
+	} else {
+		update["parse_after"] = time.Now().Add(time.Duration(resume.ParseAttempts) * 2 * time.Minute)
+	}
+
+	change := bson.M{"$set": update}
+	if len(unset) > 0 {
+		change["$unset"] = unset
+	}
+	if _, err := ps.resumeCollection.UpdateByID(ctx, resume.ID, change); err != nil {
+		return err
+	}
+	return cause
+}
+
+// QueuePendingParses hands every pending resume that is due to the workers,
+// or parses them inline when no workers run in this process.
+func (ps *ResumeParseServiceImpl) QueuePendingParses() (int, error) {
+	if ps.parser == nil {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := ps.resumeCollection.Find(ctx,
+		bson.M{
+			"parse_status": models.ResumeParsePending,
+			"$or": []bson.M{
+				{"parse_after": bson.M{"$exists": false}},
+				{"parse_after": bson.M{"$lte": time.Now()}},
+			},
+		},
+		options.Find().SetProjection(bson.M{"_id": 1}).SetSort(bson.M{"uploaded_at": 1}).SetLimit(100),
+	)
+	if err != nil {
+		return 0, err
+	}
+	var due []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &due); err != nil {
+		return 0, err
+	}
+
+	for _, resume := range due {
+		if !enqueueResumeParse(resume.ID) {
+			if err := ps.ParseResume(resume.ID); err != nil {
+				log.Printf("Resume %s: parse failed: %v", resume.ID.Hex(), err)
+			}
+		}
+	}
+	return len(due), nil
+}
+
+func (ps *ResumeParseServiceImpl) GetParseStatus(studentID, resumeID primitive.ObjectID) (*ResumeParseStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var resume models.Resume
+	err := ps.resumeCollection.FindOne(ctx, bson.M{"_id": resumeID, "student_id": studentID}).Decode(&resume)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ResumeParseStatus{
+		ResumeID: resume.ID,
+		Status:   resume.ParseStatus,
+		Error:    resume.ParseError,
+		ParsedAt: resume.ParsedAt,
+	}
+	if status.Status == "" {
+		status.Status = models.ResumeParseDone
+	}
+	if status.Status == models.ResumeParseDone {
+		status.ParsedData = &resume.ParsedData
+	}
+	return status, nil
+}
+
+// RetryParse queues a failed resume for another round of attempts.
+func (ps *ResumeParseServiceImpl) RetryParse(studentID, resumeID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := ps.resumeCollection.UpdateOne(ctx,
+		bson.M{"_id": resumeID, "student_id": studentID, "parse_status": models.ResumeParseFailed},
+		bson.M{
+			"$set":   bson.M{"parse_status": models.ResumeParsePending, "parse_attempts": 0},
+			"$unset": bson.M{"parse_after": "", "parse_error": ""},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		count, err := ps.resumeCollection.CountDocuments(ctx, bson.M{"_id": resumeID, "student_id": studentID})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return mongo.ErrNoDocuments
+		}
+		return newValidationError("resume", "Only resumes whose parsing failed can be retried")
+	}
+	enqueueResumeParse(resumeID)
+	return nil
+}
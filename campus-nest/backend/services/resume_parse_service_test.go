@@ -0,0 +1,83 @@
+﻿package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"backend/models"
+	"backend/parser"
+	"backend/storage"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestParseService(t *testing.T, p parser.Parser) (*ResumeParseServiceImpl, storage.Storage) {
+	t.Helper()
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ResumeParseServiceImpl{store: store, parser: p}, store
+}
+
+func putResume(t *testing.T, store storage.Storage, key, body string) *models.Resume {
+	t.Helper()
+	err := store.Put(context.Background(), key, strings.NewReader(body), int64(len(body)), "application/pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &models.Resume{ID: primitive.NewObjectID(), StorageKey: key, ContentType: "application/pdf"}
+}
+
+func TestResumeParseWithFake(t *testing.T) {
+	ps, store := newTestParseService(t, parser.NewFake())
+	resume := putResume(t, store, "resumes/student/cv.pdf",
+		"Jane Doe\njane@example.com +91 98765 43210\nSkills: Go, C++, Node.js and Docker.\nInterned at Google.")
+
+	result, err := ps.parse(context.Background(), resume)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := result.Parsed.Contact, "jane@example.com, +91 98765 43210"; got != want {
+		t.Errorf("Contact = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(result.Parsed.Skills, ","), "Go,C++,Node.js,Docker"; got != want {
+		t.Errorf("Skills = %q, want %q", got, want)
+	}
+	if !strings.Contains(result.Text, "Interned at Google.") {
+		t.Errorf("Text = %q, want the resume text", result.Text)
+	}
+}
+
+func TestResumeParseFailuresArePermanent(t *testing.T) {
+	ps, store := newTestParseService(t, parser.NewFake())
+	tests := []struct {
+		name   string
+		resume *models.Resume
+	}{
+		{"missing file", &models.Resume{ID: primitive.NewObjectID(), StorageKey: "resumes/student/gone.pdf"}},
+		{"no readable text", putResume(t, store, "resumes/student/scan.pdf", "\x00\x01\x02\xff\xfe")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ps.parse(context.Background(), tt.resume)
+			if !parser.IsPermanent(err) {
+				t.Errorf("err = %v, want a permanent error", err)
+			}
+		})
+	}
+}
+
+// Without a parser nothing is claimed, so the service must not reach for
+// the database.
+func TestResumeParseWithoutParserLeavesResumesPending(t *testing.T) {
+	ps, _ := newTestParseService(t, nil)
+
+	if err := ps.ParseResume(primitive.NewObjectID()); err != nil {
+		t.Errorf("ParseResume() = %v, want nil", err)
+	}
+	if queued, err := ps.QueuePendingParses(); queued != 0 || err != nil {
+		t.Errorf("QueuePendingParses() = %d, %v, want 0, nil", queued, err)
+	}
+}
@@ -0,0 +1,269 @@
+﻿package services
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxResumeQueryLength = 300
+	maxResumeQueryTerms  = 20
+)
+
+// searchNode is a parsed boolean resume query.
+type searchNode interface {
+	filter() bson.M
+}
+
+type termNode struct {
+	text string
+}
+
+type andNode []searchNode
+
+type orNode []searchNode
+
+type notNode struct {
+	node searchNode
+}
+
+// termPattern matches the term as a whole word, so "Go" does not match
+// "Google". Word characters are ASCII letters and digits because the pattern
+// runs in MongoDB as well; terms like "C++" or ".NET" still match. Whitespace
+// inside a phrase matches any run of whitespace, as OCR text wraps lines.
+func termPattern(term string) string {
+	words := strings.Fields(term)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	return `(?:^|[^A-Za-z0-9])(` + strings.Join(words, `\s+`) + `)(?:$|[^A-Za-z0-9])`
+}
+
+// filter matches a term in the resume text or as one of the parsed skills.
+func (t termNode) filter() bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"ocr_text": primitive.Regex{Pattern: termPattern(t.text), Options: "i"}},
+		bson.M{"parsed_data.skills": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(t.text) + "$", Options: "i"}},
+	}}
+}
+
+func (a andNode) filter() bson.M {
+	filters := make(bson.A, len(a))
+	for i, node := range a {
+		filters[i] = node.filter()
+	}
+	return bson.M{"$and": filters}
+}
+
+func (o orNode) filter() bson.M {
+	filters := make(bson.A, len(o))
+	for i, node := range o {
+		filters[i] = node.filter()
+	}
+	return bson.M{"$or": filters}
+}
+
+func (n notNode) filter() bson.M {
+	return bson.M{"$nor": bson.A{n.node.filter()}}
+}
+
+// positiveTerms lists the terms that a matching resume contains, that is
+// those not under a NOT. They are the ones worth highlighting.
+func positiveTerms(node searchNode, negated bool, terms []string) []string {
+	switch n := node.(type) {
+	case termNode:
+		if !negated && !containsFold(terms, n.text) {
+			terms = append(terms, n.text)
+		}
+	case andNode:
+		for _, child := range n {
+			terms = positiveTerms(child, negated, terms)
+		}
+	case orNode:
+		for _, child := range n {
+			terms = positiveTerms(child, negated, terms)
+		}
+	case notNode:
+		terms = positiveTerms(n.node, !negated, terms)
+	}
+	return terms
+}
+
+type queryToken struct {
+	text string
+	// op is set for AND, OR, NOT and parentheses; quoted phrases and other
+	// words are terms.
+	op bool
+}
+
+// tokenizeResumeQuery splits a query into words, quoted phrases, operators
+// and parentheses. Operators are only recognised in capitals so "and" or
+// "not" can still be searched for; a leading "-" is short for NOT.
+func tokenizeResumeQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, queryToken{text: string(r), op: true})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, newValidationError("q", "A quoted phrase is missing its closing quote")
+			}
+			if phrase := strings.Join(strings.Fields(string(runes[i+1:end])), " "); phrase != "" {
+				tokens = append(tokens, queryToken{text: phrase})
+			}
+			i = end + 1
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && (i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '('):
+			tokens = append(tokens, queryToken{text: "NOT", op: true})
+			i++
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' && runes[end] != '"' {
+				end++
+			}
+			word := string(runes[i:end])
+			switch word {
+			case "AND", "OR", "NOT":
+				tokens = append(tokens, queryToken{text: word, op: true})
+			default:
+				tokens = append(tokens, queryToken{text: word})
+			}
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+	terms  int
+}
+
+// parseResumeQuery parses queries such as `Kubernetes AND Go NOT intern` or
+// `(React OR Angular) "machine learning"`. Terms next to each other are
+// ANDed, NOT binds tightest and AND binds tighter than OR.
+func parseResumeQuery(query string) (searchNode, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, newValidationError("q", "Enter something to search for")
+	}
+	if len(query) > maxResumeQueryLength {
+		return nil, newValidationError("q", "The query is too long")
+	}
+	tokens, err := tokenizeResumeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, newValidationError("q", "Unexpected "+p.tokens[p.pos].text+" in the query")
+	}
+	if p.terms > maxResumeQueryTerms {
+		return nil, newValidationError("q", "The query has too many terms")
+	}
+	if len(positiveTerms(node, false, nil)) == 0 {
+		return nil, newValidationError("q", "The query needs at least one term that is not excluded with NOT")
+	}
+	return node, nil
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *queryParser) parseOr() (searchNode, error) {
+	var nodes orNode
+	for {
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		if tok, ok := p.peek(); !ok || !tok.op || tok.text != "OR" {
+			break
+		}
+		p.pos++
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *queryParser) parseAnd() (searchNode, error) {
+	var nodes andNode
+	for {
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+
+		tok, ok := p.peek()
+		if !ok || (tok.op && (tok.text == "OR" || tok.text == ")")) {
+			break
+		}
+		if tok.op && tok.text == "AND" {
+			p.pos++
+		}
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *queryParser) parseNot() (searchNode, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, newValidationError("q", "The query ends where a term was expected")
+	}
+	if tok.op && tok.text == "NOT" {
+		p.pos++
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node: node}, nil
+	}
+	if tok.op && tok.text == "(" {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok, ok := p.peek(); !ok || tok.text != ")" || !tok.op {
+			return nil, newValidationError("q", "A parenthesis is not closed")
+		}
+		p.pos++
+		return node, nil
+	}
+	if tok.op {
+		return nil, newValidationError("q", "Expected a term before "+tok.text)
+	}
+	p.pos++
+	p.terms++
+	return termNode{text: tok.text}, nil
+}
@@ -0,0 +1,176 @@
+﻿package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// describeQuery renders a parsed query with explicit grouping so tests can
+// check precedence.
+func describeQuery(node searchNode) string {
+	join := func(nodes []searchNode, op string) string {
+		parts := make([]string, len(nodes))
+		for i, child := range nodes {
+			parts[i] = describeQuery(child)
+		}
+		return "(" + strings.Join(parts, " "+op+" ") + ")"
+	}
+	switch n := node.(type) {
+	case termNode:
+		if strings.Contains(n.text, " ") {
+			return fmt.Sprintf("%q", n.text)
+		}
+		return n.text
+	case andNode:
+		return join(n, "AND")
+	case orNode:
+		return join(n, "OR")
+	case notNode:
+		return "NOT " + describeQuery(n.node)
+	}
+	return fmt.Sprintf("%T", node)
+}
+
+func TestParseResumeQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+		terms []string
+	}{
+		{`Go`, `Go`, []string{"Go"}},
+		{`Kubernetes Go`, `(Kubernetes AND Go)`, []string{"Kubernetes", "Go"}},
+		{`Kubernetes AND Go NOT intern`, `(Kubernetes AND Go AND NOT intern)`, []string{"Kubernetes", "Go"}},
+		{`React OR Angular Go`, `(React OR (Angular AND Go))`, []string{"React", "Angular", "Go"}},
+		{`React AND Angular OR Vue`, `((React AND Angular) OR Vue)`, []string{"React", "Angular", "Vue"}},
+		{`(React OR Angular) "machine learning"`, `((React OR Angular) AND "machine learning")`, []string{"React", "Angular", "machine learning"}},
+		{`NOT intern OR Go`, `(NOT intern OR Go)`, []string{"Go"}},
+		{`NOT (intern OR trainee) Go`, `(NOT (intern OR trainee) AND Go)`, []string{"Go"}},
+		{`-intern Go`, `(NOT intern AND Go)`, []string{"Go"}},
+		{`Go -"part time"`, `(Go AND NOT "part time")`, []string{"Go"}},
+		{`full-stack`, `full-stack`, []string{"full-stack"}},
+		{`NOT NOT Go`, `NOT NOT Go`, []string{"Go"}},
+		{`C++ OR .NET`, `(C++ OR .NET)`, []string{"C++", ".NET"}},
+		{`C# Node.js`, `(C# AND Node.js)`, []string{"C#", "Node.js"}},
+		{`"  machine   learning "`, `"machine learning"`, []string{"machine learning"}},
+		{`java and not python`, `(java AND and AND not AND python)`, []string{"java", "and", "not", "python"}},
+		{`Go OR go`, `(Go OR go)`, []string{"Go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := parseResumeQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parseResumeQuery(%q) = %v", tt.query, err)
+			}
+			if got := describeQuery(node); got != tt.want {
+				t.Errorf("parseResumeQuery(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+			if got := positiveTerms(node, false, nil); strings.Join(got, "|") != strings.Join(tt.terms, "|") {
+				t.Errorf("positiveTerms(%q) = %q, want %q", tt.query, got, tt.terms)
+			}
+		})
+	}
+}
+
+func TestParseResumeQueryErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"empty", "   "},
+		{"unclosed quote", `"machine learning`},
+		{"unclosed parenthesis", `(React OR Angular`},
+		{"stray parenthesis", `React )`},
+		{"trailing operator", `Go AND`},
+		{"leading operator", `OR Go`},
+		{"empty group", `()`},
+		{"only exclusions", `NOT intern -trainee`},
+		{"too long", strings.Repeat("a", maxResumeQueryLength+1)},
+		{"too many terms", strings.Repeat("go ", maxResumeQueryTerms+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parseResumeQuery(tt.query)
+			if err == nil {
+				t.Fatalf("parseResumeQuery(%q) = %s, want an error", tt.query, describeQuery(node))
+			}
+			if !IsValidationError(err) {
+				t.Errorf("parseResumeQuery(%q) = %v, want a validation error", tt.query, err)
+			}
+		})
+	}
+}
+
+func TestTermPattern(t *testing.T) {
+	tests := []struct {
+		term  string
+		text  string
+		match bool
+	}{
+		{"Go", "Backend in Go and Python", true},
+		{"Go", "GO, Rust", true},
+		{"Go", "Languages (Go)", true},
+		{"Go", "Interned at Google", false},
+		{"Go", "golang", false},
+		{"Go", "Cargo", false},
+		{"Java", "JavaScript", false},
+		{"Java", "Java/Spring", true},
+		{"C++", "C++ and Java", true},
+		{"C++", "c++", true},
+		{"C++", "C and Java", false},
+		{"C++", "C++11", false},
+		{".NET", "Worked with .NET Core", true},
+		{".NET", "Worked on a network", false},
+		{"Node.js", "Node.js, Express", true},
+		{"Node.js", "Nodexjs", false},
+		{"machine learning", "machine\n  learning", true},
+		{"machine learning", "machine-learning", false},
+	}
+	for _, tt := range tests {
+		re := regexp.MustCompile("(?i)" + termPattern(tt.term))
+		if got := re.MatchString(tt.text); got != tt.match {
+			t.Errorf("termPattern(%q) on %q = %v, want %v", tt.term, tt.text, got, tt.match)
+		}
+	}
+}
+
+func TestResumeSnippets(t *testing.T) {
+	long := strings.Repeat("filler ", 40)
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  []string
+	}{
+		{"no text", "", []string{"Go"}, []string{}},
+		{"no match", "Interned at Google", []string{"Go"}, []string{}},
+		{"whole words only", "Interned at Google, wrote Go", []string{"Go"}, []string{"Interned at Google, wrote <mark>Go</mark>"}},
+		{"escaped", "<b>C++</b> & Rust", []string{"C++"}, []string{"&lt;b&gt;<mark>C++</mark>&lt;/b&gt; &amp; Rust"}},
+		{"line breaks collapsed", "Skills:\n\nmachine\nlearning,  Go", []string{"machine learning", "Go"}, []string{"Skills: <mark>machine learning</mark>, <mark>Go</mark>"}},
+		{"cut around the match", long + "Kubernetes " + long, []string{"Kubernetes"}, []string{"… " + strings.TrimSpace(strings.Repeat("filler ", 6)) + " <mark>Kubernetes</mark> " + strings.TrimSpace(strings.Repeat("filler ", 6)) + "…"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resumeSnippets(tt.text, tt.terms)
+			if strings.Join(got, "\n---\n") != strings.Join(tt.want, "\n---\n") || len(got) != len(tt.want) {
+				t.Errorf("resumeSnippets() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResumeSnippetsLimit(t *testing.T) {
+	gap := strings.Repeat("filler ", 40)
+	text := strings.Repeat("Go "+gap, maxResumeSnippets+2)
+
+	got := resumeSnippets(text, []string{"Go"})
+	if len(got) != maxResumeSnippets {
+		t.Fatalf("resumeSnippets() returned %d snippets, want %d", len(got), maxResumeSnippets)
+	}
+	for _, snippet := range got {
+		if strings.Count(snippet, "<mark>Go</mark>") != 1 {
+			t.Errorf("snippet %q should mark Go once", snippet)
+		}
+	}
+}
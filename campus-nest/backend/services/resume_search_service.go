@@ -0,0 +1,307 @@
+﻿package services
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	maxResumeSnippets  = 3
+	resumeSnippetWidth = 80
+	// A term listed among the parsed skills outweighs a few mentions in the
+	// text.
+	resumeSkillHitScore = 3
+)
+
+var ResumeSearchListSpec = ListSpec{
+	Sorts: map[string]string{
+		"relevance":  "score",
+		"uploadedAt": "uploaded_at",
+		"cgpa":       "student.cgpa",
+	},
+	DefaultSort: "-relevance",
+}
+
+type ResumeSearchServiceImpl struct {
+	resumeCollection      *mongo.Collection
+	userCollection        *mongo.Collection
+	jobCollection         *mongo.Collection
+	applicationCollection *mongo.Collection
+}
+
+func NewResumeSearchService(db *mongo.Database) ResumeSearchService {
+	return &ResumeSearchServiceImpl{
+		resumeCollection:      db.Collection("resumes"),
+		userCollection:        db.Collection("users"),
+		jobCollection:         db.Collection("jobs"),
+		applicationCollection: db.Collection("applications"),
+	}
+}
+
+// SearchResumes runs a boolean keyword query over the text and parsed skills
+// of students' current resumes. TPOs only search their department and
+// recruiters only students who applied to one of their company's drives.
+func (rs *ResumeSearchServiceImpl) SearchResumes(viewerID primitive.ObjectID, params *ResumeSearchParams) (*ListPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	query, err := parseResumeQuery(params.Query)
+	if err != nil {
+		return nil, err
+	}
+	terms := positiveTerms(query, false, nil)
+
+	var viewer models.User
+	if err := rs.userCollection.FindOne(ctx, bson.M{"_id": viewerID}).Decode(&viewer); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrForbidden
+		}
+		return nil, err
+	}
+
+	match := bson.M{"$and": bson.A{
+		bson.M{"archived_at": bson.M{"$exists": false}},
+		query.filter(),
+	}}
+	studentMatch := bson.M{"student.role": "student"}
+	departments := params.Departments
+
+	switch viewer.Role {
+	case "admin":
+	case "tpo":
+		if viewer.Department == nil || *viewer.Department == "" {
+			return nil, ErrForbidden
+		}
+		departments = []string{*viewer.Department}
+	case "rec":
+		if viewer.CompanyID == nil {
+			return nil, ErrForbidden
+		}
+		applicants, err := rs.companyApplicants(ctx, *viewer.CompanyID)
+		if err != nil {
+			return nil, err
+		}
+		match["student_id"] = bson.M{"$in": applicants}
+	default:
+		return nil, ErrForbidden
+	}
+
+	if len(departments) > 0 {
+		studentMatch["student.department"] = bson.M{"$in": caseInsensitive(departments)}
+	}
+	// Students whose batch is not on record are left out of a batch filter.
+	if len(params.Batches) > 0 {
+		studentMatch["student.graduationYear"] = bson.M{"$in": params.Batches}
+	}
+	cgpa := bson.M{}
+	if params.MinCGPA != nil {
+		cgpa["$gte"] = *params.MinCGPA
+	}
+	if params.MaxCGPA != nil {
+		cgpa["$lte"] = *params.MaxCGPA
+	}
+	if len(cgpa) > 0 {
+		studentMatch["student.cgpa"] = cgpa
+	}
+
+	// Only the student's active resumes are searched; deleted ones that
+	// applications still reference are archived and skipped above, and a
+	// replaced resume that is no longer in the list is skipped here.
+	studentMatch["$expr"] = bson.M{"$in": bson.A{"$_id", bson.M{"$ifNull": bson.A{"$student.activeResumeId", bson.A{}}}}}
+
+	score := bson.A{}
+	for _, term := range terms {
+		score = append(score,
+			bson.M{"$size": bson.M{"$regexFindAll": bson.M{
+				"input":   bson.M{"$ifNull": bson.A{"$ocr_text", ""}},
+				"regex":   termPattern(term),
+				"options": "i",
+			}}},
+			bson.M{"$multiply": bson.A{resumeSkillHitScore, bson.M{"$size": bson.M{"$filter": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$parsed_data.skills", bson.A{}}},
+				"cond": bson.M{"$regexMatch": bson.M{
+					"input":   "$$this",
+					"regex":   "^" + regexp.QuoteMeta(term) + "$",
+					"options": "i",
+				}},
+			}}}}},
+		)
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$lookup": bson.M{
+			"from":         "users",
+			"localField":   "student_id",
+			"foreignField": "_id",
+			"as":           "student",
+		}},
+		{"$unwind": "$student"},
+		{"$match": studentMatch},
+		{"$addFields": bson.M{"score": bson.M{"$add": score}}},
+	}
+	shape := []bson.M{
+		{"$project": bson.M{
+			"resume_name":            1,
+			"uploaded_at":            1,
+			"score":                  1,
+			"ocr_text":               1,
+			"skills":                 "$parsed_data.skills",
+			"student._id":            1,
+			"student.firstName":      1,
+			"student.lastName":       1,
+			"student.email":          1,
+			"student.department":     1,
+			"student.rollNumber":     1,
+			"student.cgpa":           1,
+			"student.graduationYear": 1,
+		}},
+	}
+
+	page, hits, err := AggregatePage[*ResumeSearchHit](ctx, rs.resumeCollection, pipeline, shape, params.List)
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range hits {
+		hit.LinkURL = "/api/v1/resumes/" + hit.ID.Hex() + "/link"
+		hit.MatchedSkills = []string{}
+		for _, skill := range hit.Skills {
+			if containsFold(terms, skill) {
+				hit.MatchedSkills = append(hit.MatchedSkills, skill)
+			}
+		}
+		hit.Snippets = resumeSnippets(hit.OCRText, terms)
+	}
+	page.Summary = &ResumeSearchSummary{Terms: terms}
+	return page, nil
+}
+
+// companyApplicants lists the students who applied to any drive of the
+// company.
+func (rs *ResumeSearchServiceImpl) companyApplicants(ctx context.Context, companyID primitive.ObjectID) (bson.A, error) {
+	jobIDs, err := rs.jobCollection.Distinct(ctx, "_id", bson.M{"company_name.companyId": companyID})
+	if err != nil {
+		return nil, err
+	}
+	if len(jobIDs) == 0 {
+		return bson.A{}, nil
+	}
+	students, err := rs.applicationCollection.Distinct(ctx, "student_id", bson.M{"job_id": bson.M{"$in": jobIDs}})
+	if err != nil {
+		return nil, err
+	}
+	return bson.A(students), nil
+}
+
+type textSpan struct {
+	start, end int
+}
+
+// resumeSnippets cuts up to maxResumeSnippets passages around the terms out
+// of text. The passages are HTML escaped with the terms wrapped in <mark>.
+func resumeSnippets(text string, terms []string) []string {
+	snippets := []string{}
+	if text == "" {
+		return snippets
+	}
+
+	var spans []textSpan
+	for _, term := range terms {
+		re, err := regexp.Compile("(?i)" + termPattern(term))
+		if err != nil {
+			continue
+		}
+		for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+			spans = append(spans, textSpan{m[2], m[3]})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	covered := 0
+	for i := 0; i < len(spans) && len(snippets) < maxResumeSnippets; i++ {
+		if spans[i].start < covered {
+			continue
+		}
+		from := snippetEdge(text, spans[i].start-resumeSnippetWidth/2, false)
+		if from < covered {
+			from = covered
+		}
+		to := snippetEdge(text, spans[i].end+resumeSnippetWidth/2, true)
+
+		var b strings.Builder
+		if from > 0 {
+			b.WriteString("…")
+		}
+		pos := from
+		for j := i; j < len(spans) && spans[j].end <= to; j++ {
+			if spans[j].start < pos {
+				continue
+			}
+			b.WriteString(snippetText(text[pos:spans[j].start]))
+			b.WriteString("<mark>" + snippetText(text[spans[j].start:spans[j].end]) + "</mark>")
+			pos = spans[j].end
+		}
+		b.WriteString(snippetText(text[pos:to]))
+		if to < len(text) {
+			b.WriteString("…")
+		}
+		snippets = append(snippets, strings.TrimSpace(b.String()))
+		covered = to
+	}
+	return snippets
+}
+
+// snippetEdge moves i to the nearest word break inside text, looking
+// outwards from the match so words are not cut in half.
+func snippetEdge(text string, i int, forward bool) int {
+	if i <= 0 {
+		return 0
+	}
+	if i >= len(text) {
+		return len(text)
+	}
+	for j := 0; j < 20; j++ {
+		k := i - j
+		if forward {
+			k = i + j
+		}
+		if k <= 0 || k >= len(text) {
+			break
+		}
+		if text[k] == ' ' || text[k] == '\n' {
+			return k
+		}
+	}
+	for i > 0 && !utf8.RuneStart(text[i]) {
+		i--
+	}
+	return i
+}
+
+// snippetText escapes a piece of resume text and collapses the line breaks
+// OCR leaves in it.
+func snippetText(s string) string {
+	collapsed := strings.Join(strings.Fields(s), " ")
+	if collapsed != "" {
+		if strings.TrimLeft(s, " \t\r\n") != s {
+			collapsed = " " + collapsed
+		}
+		if strings.TrimRight(s, " \t\r\n") != s {
+			collapsed += " "
+		}
+	} else if s != "" {
+		collapsed = " "
+	}
+	return html.EscapeString(collapsed)
+}
@@ -0,0 +1,354 @@
+﻿package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/storage"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	MaxResumeSize        = 5 << 20
+	MaxResumesPerStudent = 5
+)
+
+type ResumeServiceImpl struct {
+	resumeCollection      *mongo.Collection
+	userCollection        *mongo.Collection
+	applicationCollection *mongo.Collection
+	store                 storage.Storage
+}
+
+func NewResumeService(db *mongo.Database) ResumeService {
+	return &ResumeServiceImpl{
+		resumeCollection:      db.Collection("resumes"),
+		userCollection:        db.Collection("users"),
+		applicationCollection: db.Collection("applications"),
+		store:                 storage.Default(),
+	}
+}
+
+// ResumeFileURL is where the API serves an uploaded resume to its owner.
+func ResumeFileURL(resumeID primitive.ObjectID) string {
+	return "/api/v1/student/resumes/" + resumeID.Hex() + "/file"
+}
+
+// UploadResume stores the file and adds the resume to the end of the
+// student's ActiveResumeID list. The first resume becomes the primary one.
+// Parsing happens in the background; the resume starts out pending.
+func (rs *ResumeServiceImpl) UploadResume(studentID primitive.ObjectID, fileName, resumeName string, r io.Reader, size int64) (*models.Resume, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	if err := checkUploadSize(size, MaxResumeSize, "file"); err != nil {
+		return nil, err
+	}
+
+	var student models.User
+	if err := rs.userCollection.FindOne(ctx, bson.M{"_id": studentID, "role": "student"}).Decode(&student); err != nil {
+		return nil, err
+	}
+	if len(student.ActiveResumeID) >= MaxResumesPerStudent {
+		return nil, resumeLimitError("file")
+	}
+
+	contentType, ext, body, err := sniffUpload(r, resumeContentTypes, "file", "PDF")
+	if err != nil {
+		return nil, err
+	}
+
+	resume := &models.Resume{
+		ID:          primitive.NewObjectID(),
+		StudentID:   studentID,
+		ContentType: contentType,
+		Size:        size,
+		UploadedAt:  time.Now(),
+		ParseStatus: models.ResumeParsePending,
+	}
+	fileName = uploadFileName(fileName, "resume", ext)
+	resume.ResumeName = strings.TrimSpace(resumeName)
+	if resume.ResumeName == "" {
+		resume.ResumeName = strings.TrimSuffix(fileName, ext)
+	}
+	resume.FileURL = ResumeFileURL(resume.ID)
+	resume.StorageKey = fmt.Sprintf("resumes/%s/%s%s", studentID.Hex(), resume.ID.Hex(), ext)
+
+	if err := rs.saveResume(ctx, resume, body, "file"); err != nil {
+		return nil, err
+	}
+	enqueueResumeParse(resume.ID)
+	return resume, nil
+}
+
+// GenerateResume renders the student's profile as a PDF with the template and
+// adds it to their resumes like an upload, so it can be applied with right
+// away. The parsed data comes straight from the profile.
+func (rs *ResumeServiceImpl) GenerateResume(studentID primitive.ObjectID, template, resumeName string) (*models.Resume, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if template == "" {
+		template = DefaultResumeTemplate
+	}
+	tpl, ok := resumeTemplates[template]
+	if !ok {
+		return nil, newValidationError("template", fmt.Sprintf("Unknown template %q", template))
+	}
+
+	var student models.User
+	if err := rs.userCollection.FindOne(ctx, bson.M{"_id": studentID, "role": "student"}).Decode(&student); err != nil {
+		return nil, err
+	}
+	if len(student.ActiveResumeID) >= MaxResumesPerStudent {
+		return nil, resumeLimitError("template")
+	}
+	if len(student.Education) == 0 && len(student.Skills) == 0 && len(student.Projects) == 0 && len(student.Qualifications) == 0 && student.CGPA == nil {
+		return nil, newValidationError("profile", "Add your education, skills, projects or qualifications to your profile first")
+	}
+
+	body, text, err := renderResume(&student, tpl)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	resume := &models.Resume{
+		ID:          primitive.NewObjectID(),
+		StudentID:   studentID,
+		ResumeName:  strings.TrimSpace(resumeName),
+		OCRText:     text,
+		ContentType: "application/pdf",
+		Size:        int64(len(body)),
+		Template:    template,
+		UploadedAt:  now,
+		ParseStatus: models.ResumeParseDone,
+		ParsedAt:    &now,
+		ParsedData: models.ParsedResume{
+			Contact:   student.Email,
+			Education: student.Education,
+			Skills:    student.Skills,
+		},
+	}
+	if resume.ResumeName == "" {
+		resume.ResumeName = fmt.Sprintf("%s %s (%s)", student.FirstName, student.LastName, template)
+	}
+	resume.FileURL = ResumeFileURL(resume.ID)
+	resume.StorageKey = fmt.Sprintf("resumes/%s/%s.pdf", studentID.Hex(), resume.ID.Hex())
+
+	if err := rs.saveResume(ctx, resume, bytes.NewReader(body), "template"); err != nil {
+		return nil, err
+	}
+	return resume, nil
+}
+
+func resumeLimitError(field string) error {
+	return newValidationError(field, fmt.Sprintf("You can keep at most %d resumes, delete one first", MaxResumesPerStudent))
+}
+
+// saveResume stores the file, inserts the resume and appends it to the
+// student's ActiveResumeID list, undoing the earlier steps if one fails.
+func (rs *ResumeServiceImpl) saveResume(ctx context.Context, resume *models.Resume, body io.Reader, field string) error {
+	if err := rs.store.Put(ctx, resume.StorageKey, body, resume.Size, resume.ContentType); err != nil {
+		return err
+	}
+	if _, err := rs.resumeCollection.InsertOne(ctx, resume); err != nil {
+		rs.store.Delete(ctx, resume.StorageKey)
+		return err
+	}
+
+	// Guard the limit again in the update so parallel uploads cannot exceed
+	// it.
+	result, err := rs.userCollection.UpdateOne(ctx,
+		bson.M{"_id": resume.StudentID, fmt.Sprintf("activeResumeId.%d", MaxResumesPerStudent-1): bson.M{"$exists": false}},
+		bson.M{"$push": bson.M{"activeResumeId": resume.ID}},
+	)
+	if err == nil && result.MatchedCount == 0 {
+		err = resumeLimitError(field)
+	}
+	if err != nil {
+		rs.resumeCollection.DeleteOne(ctx, bson.M{"_id": resume.ID})
+		rs.store.Delete(ctx, resume.StorageKey)
+		return err
+	}
+	return nil
+}
+
+// ListResumes returns the student's active resumes in ActiveResumeID order,
+// primary first.
+func (rs *ResumeServiceImpl) ListResumes(studentID primitive.ObjectID) ([]*ResumeSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var student models.User
+	if err := rs.userCollection.FindOne(ctx, bson.M{"_id": studentID}).Decode(&student); err != nil {
+		return nil, err
+	}
+	summaries := []*ResumeSummary{}
+	if len(student.ActiveResumeID) == 0 {
+		return summaries, nil
+	}
+
+	cursor, err := rs.resumeCollection.Find(ctx, bson.M{"_id": bson.M{"$in": student.ActiveResumeID}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var resumes []models.Resume
+	if err = cursor.All(ctx, &resumes); err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]*models.Resume, len(resumes))
+	for i := range resumes {
+		byID[resumes[i].ID] = &resumes[i]
+	}
+	for _, id := range student.ActiveResumeID {
+		if resume, ok := byID[id]; ok {
+			summaries = append(summaries, &ResumeSummary{Resume: resume, Primary: len(summaries) == 0})
+		}
+	}
+	return summaries, nil
+}
+
+func (rs *ResumeServiceImpl) RenameResume(studentID, resumeID primitive.ObjectID, name string) (*models.Resume, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, newValidationError("resume_name", "Resume name cannot be empty")
+	}
+	if len(name) > 100 {
+		return nil, newValidationError("resume_name", "Resume name can be at most 100 characters")
+	}
+
+	resume, err := rs.ownResume(ctx, studentID, resumeID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := rs.resumeCollection.UpdateByID(ctx, resumeID, bson.M{"$set": bson.M{"resume_name": name}}); err != nil {
+		return nil, err
+	}
+	resume.ResumeName = name
+	return resume, nil
+}
+
+// DeleteResume removes a resume from the student's list. Resumes that were
+// submitted with an application are archived instead so recruiters can still
+// open them.
+func (rs *ResumeServiceImpl) DeleteResume(studentID, resumeID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resume, err := rs.ownResume(ctx, studentID, resumeID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := rs.userCollection.UpdateByID(ctx, studentID, bson.M{"$pull": bson.M{"activeResumeId": resumeID}}); err != nil {
+		return err
+	}
+
+	used, err := rs.applicationCollection.CountDocuments(ctx, bson.M{"resume_id": resumeID})
+	if err != nil {
+		return err
+	}
+	if used > 0 {
+		_, err = rs.resumeCollection.UpdateByID(ctx, resumeID, bson.M{"$set": bson.M{"archived_at": time.Now()}})
+		return err
+	}
+
+	if _, err := rs.resumeCollection.DeleteOne(ctx, bson.M{"_id": resumeID}); err != nil {
+		return err
+	}
+	if resume.StorageKey != "" {
+		return rs.store.Delete(ctx, resume.StorageKey)
+	}
+	return nil
+}
+
+// SetPrimaryResume moves the resume to the front of ActiveResumeID, which is
+// where the profile and dashboards read the primary resume from.
+func (rs *ResumeServiceImpl) SetPrimaryResume(studentID, resumeID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := rs.ownResume(ctx, studentID, resumeID); err != nil {
+		return err
+	}
+
+	var student models.User
+	if err := rs.userCollection.FindOne(ctx, bson.M{"_id": studentID}).Decode(&student); err != nil {
+		return err
+	}
+	ordered := []primitive.ObjectID{resumeID}
+	for _, id := range student.ActiveResumeID {
+		if id != resumeID {
+			ordered = append(ordered, id)
+		}
+	}
+
+	// Only write if the list did not change underneath us.
+	result, err := rs.userCollection.UpdateOne(ctx,
+		bson.M{"_id": studentID, "activeResumeId": student.ActiveResumeID},
+		bson.M{"$set": bson.M{"activeResumeId": ordered}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return newValidationError("resume", "Your resumes changed while updating, please try again")
+	}
+	return nil
+}
+
+// OpenResume opens the file of one of the student's own uploaded resumes.
+// The reader outlives this call, so it is opened without a deadline.
+func (rs *ResumeServiceImpl) OpenResume(studentID, resumeID primitive.ObjectID) (*models.Resume, io.ReadCloser, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var resume models.Resume
+	err := rs.resumeCollection.FindOne(ctx, bson.M{"_id": resumeID, "student_id": studentID}).Decode(&resume)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resume.StorageKey == "" {
+		return nil, nil, mongo.ErrNoDocuments
+	}
+
+	file, err := rs.store.Open(context.Background(), resume.StorageKey)
+	if err == storage.ErrNotFound {
+		return nil, nil, mongo.ErrNoDocuments
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resume, file, nil
+}
+
+// ownResume finds an active resume of the student.
+func (rs *ResumeServiceImpl) ownResume(ctx context.Context, studentID, resumeID primitive.ObjectID) (*models.Resume, error) {
+	active, err := rs.userCollection.CountDocuments(ctx, bson.M{"_id": studentID, "activeResumeId": resumeID})
+	if err != nil {
+		return nil, err
+	}
+	if active == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	var resume models.Resume
+	if err := rs.resumeCollection.FindOne(ctx, bson.M{"_id": resumeID, "student_id": studentID}).Decode(&resume); err != nil {
+		return nil, err
+	}
+	return &resume, nil
+}
@@ -0,0 +1,27 @@
+﻿package services
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var salaryNumberPattern = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// ParseSalaryLPA extracts the lower bound of a free-text salary such as
+// "12 LPA", "10-14 LPA" or "1200000" and returns it in lakhs per annum.
+func ParseSalaryLPA(salary string) float64 {
+	cleaned := strings.ReplaceAll(salary, ",", "")
+	match := salaryNumberPattern.FindString(cleaned)
+	if match == "" {
+		return 0
+	}
+	value, err := strconv.ParseFloat(match, 64)
+	if err != nil {
+		return 0
+	}
+	if value >= 1000 {
+		value = value / 100000
+	}
+	return value
+}
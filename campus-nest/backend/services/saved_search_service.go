@@ -0,0 +1,349 @@
+﻿package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// MaxSavedSearches is how many searches a student can keep.
+	MaxSavedSearches = 10
+	// MaxDailySearchAlerts caps the new-drive alerts a student receives in
+	// any 24 hour window, however many of their searches match.
+	MaxDailySearchAlerts = 5
+)
+
+var SavedSearchListSpec = ListSpec{
+	Sorts: map[string]string{
+		"name":      "name",
+		"createdAt": "created_at",
+	},
+	DefaultSort: "-createdAt",
+}
+
+type SavedSearchServiceImpl struct {
+	searchCollection *mongo.Collection
+	alertCollection  *mongo.Collection
+	userCollection   *mongo.Collection
+}
+
+func NewSavedSearchService(db *mongo.Database) SavedSearchService {
+	return &SavedSearchServiceImpl{
+		searchCollection: db.Collection("saved_searches"),
+		alertCollection:  db.Collection("search_alerts"),
+		userCollection:   db.Collection("users"),
+	}
+}
+
+// EnsureSavedSearchIndexes creates the indexes alert matching relies on. The
+// unique index on search_alerts is what stops a drive from alerting the same
+// student twice.
+func EnsureSavedSearchIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := db.Collection("saved_searches").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "student_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "alerts_enabled", Value: 1}, {Key: "min_package_lpa", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection("search_alerts").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "student_id", Value: 1}, {Key: "job_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "student_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+func (ss *SavedSearchServiceImpl) ListSavedSearches(studentID primitive.ObjectID, q *ListQuery) (*ListPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	page, _, err := FindPage[*models.SavedSearch](ctx, ss.searchCollection, bson.M{"student_id": studentID}, q)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (ss *SavedSearchServiceImpl) CreateSavedSearch(search *models.SavedSearch) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := normalizeSavedSearch(search); err != nil {
+		return err
+	}
+
+	count, err := ss.searchCollection.CountDocuments(ctx, bson.M{"student_id": search.StudentID})
+	if err != nil {
+		return err
+	}
+	if count >= MaxSavedSearches {
+		return newValidationError("search", fmt.Sprintf("You can save at most %d searches, delete one first", MaxSavedSearches))
+	}
+
+	now := time.Now()
+	search.ID = primitive.NewObjectID()
+	search.CreatedAt = now
+	search.UpdatedAt = now
+	search.LastAlertedAt = nil
+
+	_, err = ss.searchCollection.InsertOne(ctx, search)
+	return err
+}
+
+func (ss *SavedSearchServiceImpl) UpdateSavedSearch(studentID, searchID primitive.ObjectID, search *models.SavedSearch) (*models.SavedSearch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := normalizeSavedSearch(search); err != nil {
+		return nil, err
+	}
+
+	var updated models.SavedSearch
+	err := ss.searchCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": searchID, "student_id": studentID},
+		bson.M{"$set": bson.M{
+			"name":            search.Name,
+			"keywords":        search.Keywords,
+			"locations":       search.Locations,
+			"min_package_lpa": search.MinPackageLPA,
+			"eligible_only":   search.EligibleOnly,
+			"alerts_enabled":  search.AlertsEnabled,
+			"updated_at":      time.Now(),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (ss *SavedSearchServiceImpl) DeleteSavedSearch(studentID, searchID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := ss.searchCollection.DeleteOne(ctx, bson.M{"_id": searchID, "student_id": studentID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// searchAlertMu serialises alerting so drives published at the same time
+// cannot each find a student under the daily cap and together exceed it.
+var searchAlertMu sync.Mutex
+
+// AlertNewDrive notifies the students whose saved searches match a drive
+// that was just published. It returns the number of students notified.
+// Drives that are not open yet are ignored; they alert once published.
+func (ss *SavedSearchServiceImpl) AlertNewDrive(job *models.Job) (int, error) {
+	searchAlertMu.Lock()
+	defer searchAlertMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if job.Status != models.JobStatusOpen || job.ApplicationDeadline.Before(time.Now()) {
+		return 0, nil
+	}
+
+	cursor, err := ss.searchCollection.Find(ctx, bson.M{
+		"alerts_enabled":  true,
+		"min_package_lpa": bson.M{"$not": bson.M{"$gt": job.PackageLPA}},
+	}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var searches []models.SavedSearch
+	if err = cursor.All(ctx, &searches); err != nil {
+		return 0, err
+	}
+
+	// A student is alerted once per drive, for the oldest search that matches
+	// once eligibility is taken into account.
+	matched := make(map[primitive.ObjectID][]*models.SavedSearch)
+	var studentIDs []primitive.ObjectID
+	for i := range searches {
+		search := &searches[i]
+		if !searchMatchesJob(search, job) {
+			continue
+		}
+		if _, seen := matched[search.StudentID]; !seen {
+			studentIDs = append(studentIDs, search.StudentID)
+		}
+		matched[search.StudentID] = append(matched[search.StudentID], search)
+	}
+	if len(studentIDs) == 0 {
+		return 0, nil
+	}
+
+	students, err := ss.loadStudents(ctx, studentIDs)
+	if err != nil {
+		return 0, err
+	}
+	recent, err := ss.recentAlertCounts(ctx, studentIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	notified := 0
+	for _, studentID := range studentIDs {
+		student, ok := students[studentID]
+		if !ok || recent[studentID] >= MaxDailySearchAlerts {
+			continue
+		}
+		eligible := len(eligibilityFailures(student, job.Eligibility)) == 0
+		var search *models.SavedSearch
+		for _, candidate := range matched[studentID] {
+			if eligible || !candidate.EligibleOnly {
+				search = candidate
+				break
+			}
+		}
+		if search == nil {
+			continue
+		}
+
+		now := time.Now()
+		_, err := ss.alertCollection.InsertOne(ctx, models.SearchAlert{
+			ID:        primitive.NewObjectID(),
+			StudentID: studentID,
+			JobID:     job.ID,
+			SearchID:  search.ID,
+			CreatedAt: now,
+		})
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		if err != nil {
+			return notified, err
+		}
+
+		subject := "New drive matches your saved search"
+		message := fmt.Sprintf("%s is hiring for %s. It matches your saved search %q.", job.CompanyName.Name, job.Position, search.Name)
+		if _, err := pushNotification(ctx, ss.userCollection, []primitive.ObjectID{studentID}, subject, message); err != nil {
+			return notified, err
+		}
+		ss.searchCollection.UpdateByID(ctx, search.ID, bson.M{"$set": bson.M{"last_alerted_at": now}})
+		notified++
+	}
+	return notified, nil
+}
+
+func (ss *SavedSearchServiceImpl) loadStudents(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*models.User, error) {
+	cursor, err := ss.userCollection.Find(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "role": "student"},
+		options.Find().SetProjection(bson.M{"cgpa": 1, "department": 1, "graduationYear": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	students := make(map[primitive.ObjectID]*models.User, len(users))
+	for i := range users {
+		students[users[i].ID] = &users[i]
+	}
+	return students, nil
+}
+
+// recentAlertCounts counts the alerts each student received in the last 24
+// hours.
+func (ss *SavedSearchServiceImpl) recentAlertCounts(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	cursor, err := ss.alertCollection.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{
+			"student_id": bson.M{"$in": ids},
+			"created_at": bson.M{"$gte": time.Now().Add(-24 * time.Hour)},
+		}},
+		{"$group": bson.M{"_id": "$student_id", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		StudentID primitive.ObjectID `bson:"_id"`
+		Count     int                `bson:"count"`
+	}
+	if err = cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	counts := make(map[primitive.ObjectID]int, len(rows))
+	for _, row := range rows {
+		counts[row.StudentID] = row.Count
+	}
+	return counts, nil
+}
+
+func normalizeSavedSearch(search *models.SavedSearch) error {
+	search.Name = strings.TrimSpace(search.Name)
+	search.Keywords = strings.Join(strings.Fields(search.Keywords), " ")
+	search.Locations = dedupeStrings(search.Locations)
+
+	if search.MinPackageLPA < 0 {
+		return newValidationError("min_package_lpa", "Minimum package cannot be negative")
+	}
+	if search.Keywords == "" && len(search.Locations) == 0 && search.MinPackageLPA == 0 && !search.EligibleOnly {
+		return newValidationError("search", "Add at least one keyword, location, minimum package or the eligible only filter")
+	}
+	if search.Name == "" {
+		search.Name = search.Keywords
+		if search.Name == "" {
+			search.Name = "Saved search"
+		}
+	}
+	return nil
+}
+
+// searchMatchesJob checks the keyword and location filters of a search. Every
+// keyword has to appear as a whole word in the drive's position, company,
+// skills or description, so "Go" does not match "Google". The package filter
+// is applied by the query.
+func searchMatchesJob(search *models.SavedSearch, job *models.Job) bool {
+	if len(search.Locations) > 0 && !locationPreferred(search.Locations, job.Location) {
+		return false
+	}
+	if search.Keywords == "" {
+		return true
+	}
+	text := strings.Join([]string{
+		job.Position,
+		job.CompanyName.Name,
+		string(job.JobType),
+		strings.Join(job.Eligibility.Skills, " "),
+		job.Description,
+	}, " ")
+	for _, keyword := range strings.Fields(search.Keywords) {
+		if !regexp.MustCompile("(?i)" + termPattern(keyword)).MatchString(text) {
+			return false
+		}
+	}
+	return true
+}
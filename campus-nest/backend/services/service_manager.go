@@ -13,6 +13,26 @@ type ServiceManager struct {
 	JobService       JobService
 	DashboardService DashboardService
 	CompanyService   CompanyService
+	PolicyService    PolicyService
+	OfferService     OfferService
+	PlacementService PlacementService
+	WaitlistService  WaitlistService
+	TemplateService  TemplateService
+	JobSearchService JobSearchService
+
+	RecommendationService RecommendationService
+	SavedSearchService    SavedSearchService
+	BookmarkService       BookmarkService
+	PPOService            PPOService
+	AttachmentService     AttachmentService
+	ResumeService         ResumeService
+	ResumeParseService    ResumeParseService
+	ResumeExportService   ResumeExportService
+	ResumeAccessService   ResumeAccessService
+	MatchService          MatchService
+	ResumeSearchService   ResumeSearchService
+	SkillService          SkillService
+	SkillGapService       SkillGapService
 }
 
 
@@ -25,6 +45,26 @@ func NewServiceManager(db *mongo.Database) *ServiceManager {
 		JobService:       NewJobService(db),
 		DashboardService: NewDashboardService(db),
 		CompanyService:   NewCompanyService(db),
+		PolicyService:    NewPolicyService(db),
+		OfferService:     NewOfferService(db),
+		PlacementService: NewPlacementService(db),
+		WaitlistService:  NewWaitlistService(db),
+		TemplateService:  NewTemplateService(db),
+		JobSearchService: NewJobSearchService(db),
+
+		RecommendationService: NewRecommendationService(db),
+		SavedSearchService:    NewSavedSearchService(db),
+		BookmarkService:       NewBookmarkService(db),
+		PPOService:            NewPPOService(db),
+		AttachmentService:     NewAttachmentService(db),
+		ResumeService:         NewResumeService(db),
+		ResumeParseService:    NewResumeParseService(db),
+		ResumeExportService:   NewResumeExportService(db),
+		ResumeAccessService:   NewResumeAccessService(db),
+		MatchService:          NewMatchService(db),
+		ResumeSearchService:   NewResumeSearchService(db),
+		SkillService:          NewSkillService(db),
+		SkillGapService:       NewSkillGapService(db),
 	}
 }
 
@@ -62,3 +102,117 @@ func (sm *ServiceManager) GetDashboardService() DashboardService {
 func (sm *ServiceManager) GetCompanyService() CompanyService {
 	return sm.CompanyService
 }
+
+
+
+func (sm *ServiceManager) GetPolicyService() PolicyService {
+	return sm.PolicyService
+}
+
+
+
+func (sm *ServiceManager) GetOfferService() OfferService {
+	return sm.OfferService
+}
+
+
+
+func (sm *ServiceManager) GetPlacementService() PlacementService {
+	return sm.PlacementService
+}
+
+
+
+func (sm *ServiceManager) GetWaitlistService() WaitlistService {
+	return sm.WaitlistService
+}
+
+
+
+func (sm *ServiceManager) GetTemplateService() TemplateService {
+	return sm.TemplateService
+}
+
+
+
+func (sm *ServiceManager) GetJobSearchService() JobSearchService {
+	return sm.JobSearchService
+}
+
+
+
+func (sm *ServiceManager) GetRecommendationService() RecommendationService {
+	return sm.RecommendationService
+}
+
+
+
+func (sm *ServiceManager) GetSavedSearchService() SavedSearchService {
+	return sm.SavedSearchService
+}
+
+
+
+func (sm *ServiceManager) GetBookmarkService() BookmarkService {
+	return sm.BookmarkService
+}
+
+
+
+func (sm *ServiceManager) GetPPOService() PPOService {
+	return sm.PPOService
+}
+
+
+
+func (sm *ServiceManager) GetAttachmentService() AttachmentService {
+	return sm.AttachmentService
+}
+
+
+
+func (sm *ServiceManager) GetResumeService() ResumeService {
+	return sm.ResumeService
+}
+
+
+
+func (sm *ServiceManager) GetResumeParseService() ResumeParseService {
+	return sm.ResumeParseService
+}
+
+
+
+func (sm *ServiceManager) GetResumeExportService() ResumeExportService {
+	return sm.ResumeExportService
+}
+
+
+
+func (sm *ServiceManager) GetResumeAccessService() ResumeAccessService {
+	return sm.ResumeAccessService
+}
+
+
+
+func (sm *ServiceManager) GetMatchService() MatchService {
+	return sm.MatchService
+}
+
+
+
+func (sm *ServiceManager) GetResumeSearchService() ResumeSearchService {
+	return sm.ResumeSearchService
+}
+
+
+
+func (sm *ServiceManager) GetSkillService() SkillService {
+	return sm.SkillService
+}
+
+
+
+func (sm *ServiceManager) GetSkillGapService() SkillGapService {
+	return sm.SkillGapService
+}
@@ -0,0 +1,312 @@
+﻿package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DefaultSkillGapMonths = 6
+	MaxSkillGapMonths     = 36
+	DefaultSkillGapLimit  = 10
+	MaxSkillGapLimit      = 50
+)
+
+type SkillGapServiceImpl struct {
+	jobCollection   *mongo.Collection
+	userCollection  *mongo.Collection
+	skillCollection *mongo.Collection
+	taxonomy        *skillTaxonomy
+}
+
+func NewSkillGapService(db *mongo.Database) SkillGapService {
+	return &SkillGapServiceImpl{
+		jobCollection:   db.Collection("jobs"),
+		userCollection:  db.Collection("users"),
+		skillCollection: db.Collection("skills"),
+		taxonomy:        newSkillTaxonomy(db),
+	}
+}
+
+type skillGapStudent struct {
+	Department     string   `bson:"department"`
+	GraduationYear int      `bson:"graduationYear"`
+	Skills         []string `bson:"skills"`
+}
+
+// skillGapGroup collects the unplaced students of one department and batch
+// and the recent drives open to them.
+type skillGapGroup struct {
+	department string
+	batch      int
+	students   int
+	drives     int
+	// have and want count students and drives by skill key.
+	have map[string]int
+	want map[string]int
+}
+
+func newSkillGapGroup(department string, batch int) *skillGapGroup {
+	return &skillGapGroup{department: department, batch: batch, have: map[string]int{}, want: map[string]int{}}
+}
+
+// GetSkillGaps compares how often recent drives ask for each skill with how
+// many unplaced students list it, per department and batch. A skill's gap is
+// the share of drives asking for it minus the share of students who have
+// it, in percentage points; the lists are ranked by it. TPOs only see their
+// own department.
+func (gs *SkillGapServiceImpl) GetSkillGaps(viewerID primitive.ObjectID, params *SkillGapParams) (*SkillGapReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	months := params.Months
+	if months == 0 {
+		months = DefaultSkillGapMonths
+	}
+	if months < 1 || months > MaxSkillGapMonths {
+		return nil, newValidationError("months", fmt.Sprintf("Months must be between 1 and %d", MaxSkillGapMonths))
+	}
+	limit := params.Limit
+	if limit == 0 {
+		limit = DefaultSkillGapLimit
+	}
+	if limit < 1 || limit > MaxSkillGapLimit {
+		return nil, newValidationError("limit", fmt.Sprintf("Limit must be between 1 and %d", MaxSkillGapLimit))
+	}
+
+	var viewer models.User
+	if err := gs.userCollection.FindOne(ctx, bson.M{"_id": viewerID}).Decode(&viewer); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrForbidden
+		}
+		return nil, err
+	}
+	departments := params.Departments
+	switch viewer.Role {
+	case "admin":
+	case "tpo":
+		if viewer.Department == nil || *viewer.Department == "" {
+			return nil, ErrForbidden
+		}
+		departments = []string{*viewer.Department}
+	default:
+		return nil, ErrForbidden
+	}
+
+	names, err := gs.taxonomy.names(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// spellings keeps the canonical name of each skill key seen, or the first
+	// spelling of skills the taxonomy does not know.
+	spellings := map[string]string{}
+	keysOf := func(skills []string) []string {
+		keys := make([]string, 0, len(skills))
+		for _, skill := range skills {
+			key := skillKey(skill)
+			if key == "" || containsString(keys, key) {
+				continue
+			}
+			if _, ok := spellings[key]; !ok {
+				if name, known := names[key]; known {
+					spellings[key] = name
+				} else {
+					spellings[key] = strings.Join(strings.Fields(skill), " ")
+				}
+			}
+			keys = append(keys, key)
+		}
+		return keys
+	}
+
+	studentFilter := bson.M{"role": "student", "placedStatus": bson.M{"$ne": PlacementStatusPlaced}}
+	if len(departments) > 0 {
+		studentFilter["department"] = bson.M{"$in": caseInsensitive(departments)}
+	}
+	if len(params.Batches) > 0 {
+		studentFilter["graduationYear"] = bson.M{"$in": params.Batches}
+	}
+	cursor, err := gs.userCollection.Find(ctx, studentFilter,
+		options.Find().SetProjection(bson.M{"department": 1, "graduationYear": 1, "skills": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var students []skillGapStudent
+	if err := cursor.All(ctx, &students); err != nil {
+		return nil, err
+	}
+
+	// overall covers every student in scope and the drives open to any of
+	// them.
+	overall := newSkillGapGroup("", 0)
+	groups := map[string]*skillGapGroup{}
+	var order []*skillGapGroup
+	for _, student := range students {
+		department := strings.Join(strings.Fields(student.Department), " ")
+		id := fmt.Sprintf("%s|%d", strings.ToLower(department), student.GraduationYear)
+		group := groups[id]
+		if group == nil {
+			group = newSkillGapGroup(department, student.GraduationYear)
+			groups[id] = group
+			order = append(order, group)
+		}
+		group.students++
+		overall.students++
+		for _, key := range keysOf(student.Skills) {
+			group.have[key]++
+			overall.have[key]++
+		}
+	}
+
+	since := time.Now().AddDate(0, -months, 0)
+	cursor, err = gs.jobCollection.Find(ctx,
+		bson.M{"created_at": bson.M{"$gte": since}, "status": bson.M{"$ne": models.JobStatusDraft}},
+		options.Find().SetProjection(bson.M{"eligibility": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var drives []models.Job
+	if err := cursor.All(ctx, &drives); err != nil {
+		return nil, err
+	}
+
+	for _, drive := range drives {
+		keys := keysOf(drive.Eligibility.Skills)
+		open := false
+		for _, group := range order {
+			if !openToGroup(&drive.Eligibility, group.department, group.batch) {
+				continue
+			}
+			open = true
+			group.drives++
+			for _, key := range keys {
+				group.want[key]++
+			}
+		}
+		if open {
+			overall.drives++
+			for _, key := range keys {
+				overall.want[key]++
+			}
+		}
+	}
+
+	categories, err := gs.categories(ctx, spellings)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SkillGapReport{
+		Since:    since,
+		Drives:   overall.drives,
+		Students: overall.students,
+		Overall:  overall.gaps(spellings, categories, limit),
+		Groups:   []*SkillGapGroup{},
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if !strings.EqualFold(a.department, b.department) {
+			return strings.ToLower(a.department) < strings.ToLower(b.department)
+		}
+		return a.batch < b.batch
+	})
+	for _, group := range order {
+		report.Groups = append(report.Groups, &SkillGapGroup{
+			Department: group.department,
+			Batch:      group.batch,
+			Drives:     group.drives,
+			Students:   group.students,
+			Gaps:       group.gaps(spellings, categories, limit),
+		})
+	}
+	return report, nil
+}
+
+// openToGroup reports whether students of the department and batch may
+// apply to a drive with these criteria. An unknown department only passes
+// criteria that do not restrict it; batches are checked as for a single
+// student, so an unknown one passes.
+func openToGroup(e *models.Eligibility, department string, batch int) bool {
+	if len(e.Course) > 0 && (department == "" || !containsFold(e.Course, department)) {
+		return false
+	}
+	return batchAllowed(*e, batch)
+}
+
+// categories looks up the taxonomy category of the skills seen.
+func (gs *SkillGapServiceImpl) categories(ctx context.Context, spellings map[string]string) (map[string]string, error) {
+	keys := make([]string, 0, len(spellings))
+	for key := range spellings {
+		keys = append(keys, key)
+	}
+	categories := map[string]string{}
+	if len(keys) == 0 {
+		return categories, nil
+	}
+	cursor, err := gs.skillCollection.Find(ctx, bson.M{"keys": bson.M{"$in": keys}},
+		options.Find().SetProjection(bson.M{"keys": 1, "category": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var skills []models.Skill
+	if err := cursor.All(ctx, &skills); err != nil {
+		return nil, err
+	}
+	for _, skill := range skills {
+		for _, key := range skill.Keys {
+			categories[key] = skill.Category
+		}
+	}
+	return categories, nil
+}
+
+// gaps ranks the skills drives ask for more often than the group's students
+// have them, widest gap first.
+func (g *skillGapGroup) gaps(spellings, categories map[string]string, limit int) []SkillGap {
+	gaps := []SkillGap{}
+	if g.drives == 0 {
+		return gaps
+	}
+	for key, drives := range g.want {
+		gap := SkillGap{
+			Skill:             spellings[key],
+			Category:          categories[key],
+			DrivesRequesting:  drives,
+			DemandRate:        roundPercent(int64(drives), int64(g.drives)),
+			StudentsWithSkill: g.have[key],
+			StudentsLacking:   g.students - g.have[key],
+		}
+		if g.students > 0 {
+			gap.SupplyRate = roundPercent(int64(g.have[key]), int64(g.students))
+		}
+		gap.Gap = math.Round((gap.DemandRate-gap.SupplyRate)*100) / 100
+		if gap.Gap > 0 {
+			gaps = append(gaps, gap)
+		}
+	}
+	sort.Slice(gaps, func(i, j int) bool {
+		a, b := gaps[i], gaps[j]
+		if a.Gap != b.Gap {
+			return a.Gap > b.Gap
+		}
+		if a.DrivesRequesting != b.DrivesRequesting {
+			return a.DrivesRequesting > b.DrivesRequesting
+		}
+		return strings.ToLower(a.Skill) < strings.ToLower(b.Skill)
+	})
+	if len(gaps) > limit {
+		gaps = gaps[:limit]
+	}
+	return gaps
+}
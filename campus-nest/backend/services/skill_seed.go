@@ -0,0 +1,116 @@
+﻿package services
+
+// Skill categories of the default taxonomy. Admins may use others.
+const (
+	SkillCategoryLanguages    = "Programming Languages"
+	SkillCategoryWeb          = "Web Development"
+	SkillCategoryMobile       = "Mobile Development"
+	SkillCategoryDatabases    = "Databases"
+	SkillCategoryCloud        = "Cloud & DevOps"
+	SkillCategoryData         = "Data Science & AI"
+	SkillCategoryFundamentals = "CS Fundamentals"
+	SkillCategoryTools        = "Tools"
+	SkillCategoryDesign       = "Design"
+	SkillCategorySoft         = "Soft Skills"
+)
+
+type seedSkill struct {
+	name     string
+	category string
+	aliases  []string
+}
+
+// defaultSkills is loaded into an empty taxonomy. Spellings that only differ
+// in case, spaces, dots, dashes or underscores need no alias, as they
+// normalise to the same key.
+var defaultSkills = []seedSkill{
+	{"C", SkillCategoryLanguages, []string{"C language", "C programming"}},
+	{"C++", SkillCategoryLanguages, []string{"cpp", "c plus plus"}},
+	{"C#", SkillCategoryLanguages, []string{"csharp", "c sharp"}},
+	{"Go", SkillCategoryLanguages, []string{"golang"}},
+	{"Java", SkillCategoryLanguages, []string{"core java", "java se"}},
+	{"JavaScript", SkillCategoryLanguages, []string{"js", "ecmascript", "es6"}},
+	{"TypeScript", SkillCategoryLanguages, []string{"ts"}},
+	{"Python", SkillCategoryLanguages, []string{"py", "python3", "python 3"}},
+	{"Kotlin", SkillCategoryLanguages, nil},
+	{"Swift", SkillCategoryLanguages, nil},
+	{"Rust", SkillCategoryLanguages, nil},
+	{"PHP", SkillCategoryLanguages, nil},
+	{"Ruby", SkillCategoryLanguages, nil},
+	{"R", SkillCategoryLanguages, []string{"r programming", "r language"}},
+	{"MATLAB", SkillCategoryLanguages, nil},
+	{"Dart", SkillCategoryLanguages, nil},
+	{"Shell Scripting", SkillCategoryLanguages, []string{"bash", "shell"}},
+
+	{"HTML", SkillCategoryWeb, []string{"html5"}},
+	{"CSS", SkillCategoryWeb, []string{"css3"}},
+	{"React", SkillCategoryWeb, []string{"reactjs", "react.js"}},
+	{"Angular", SkillCategoryWeb, []string{"angularjs", "angular.js"}},
+	{"Vue.js", SkillCategoryWeb, []string{"vue"}},
+	{"Next.js", SkillCategoryWeb, []string{"next"}},
+	{"Node.js", SkillCategoryWeb, []string{"node"}},
+	{"Express", SkillCategoryWeb, []string{"expressjs", "express.js"}},
+	{"Django", SkillCategoryWeb, nil},
+	{"Flask", SkillCategoryWeb, nil},
+	{"Spring Boot", SkillCategoryWeb, []string{"spring", "springboot"}},
+	{"Tailwind CSS", SkillCategoryWeb, []string{"tailwind"}},
+	{"REST APIs", SkillCategoryWeb, []string{"rest", "rest api", "restful apis", "restful"}},
+	{"GraphQL", SkillCategoryWeb, nil},
+
+	{"Android", SkillCategoryMobile, []string{"android development"}},
+	{"iOS", SkillCategoryMobile, []string{"ios development"}},
+	{"Flutter", SkillCategoryMobile, nil},
+	{"React Native", SkillCategoryMobile, nil},
+
+	{"SQL", SkillCategoryDatabases, nil},
+	{"MySQL", SkillCategoryDatabases, nil},
+	{"PostgreSQL", SkillCategoryDatabases, []string{"postgres", "psql"}},
+	{"MongoDB", SkillCategoryDatabases, []string{"mongo"}},
+	{"Redis", SkillCategoryDatabases, nil},
+	{"Oracle Database", SkillCategoryDatabases, []string{"oracle", "oracle db"}},
+	{"Firebase", SkillCategoryDatabases, nil},
+
+	{"AWS", SkillCategoryCloud, []string{"amazon web services"}},
+	{"Azure", SkillCategoryCloud, []string{"microsoft azure"}},
+	{"Google Cloud", SkillCategoryCloud, []string{"gcp", "google cloud platform"}},
+	{"Docker", SkillCategoryCloud, nil},
+	{"Kubernetes", SkillCategoryCloud, []string{"k8s"}},
+	{"Linux", SkillCategoryCloud, nil},
+	{"CI/CD", SkillCategoryCloud, []string{"cicd", "continuous integration"}},
+	{"Terraform", SkillCategoryCloud, nil},
+	{"Jenkins", SkillCategoryCloud, nil},
+
+	{"Machine Learning", SkillCategoryData, []string{"ml"}},
+	{"Deep Learning", SkillCategoryData, []string{"dl"}},
+	{"Data Science", SkillCategoryData, nil},
+	{"Data Analysis", SkillCategoryData, []string{"data analytics"}},
+	{"Natural Language Processing", SkillCategoryData, []string{"nlp"}},
+	{"Computer Vision", SkillCategoryData, nil},
+	{"TensorFlow", SkillCategoryData, nil},
+	{"PyTorch", SkillCategoryData, nil},
+	{"scikit-learn", SkillCategoryData, []string{"sklearn"}},
+	{"Pandas", SkillCategoryData, nil},
+	{"NumPy", SkillCategoryData, nil},
+	{"Power BI", SkillCategoryData, nil},
+	{"Tableau", SkillCategoryData, nil},
+	{"Generative AI", SkillCategoryData, []string{"genai", "gen ai", "llm", "llms"}},
+
+	{"Data Structures and Algorithms", SkillCategoryFundamentals, []string{"dsa", "data structures", "algorithms"}},
+	{"Object-Oriented Programming", SkillCategoryFundamentals, []string{"oop", "oops"}},
+	{"Operating Systems", SkillCategoryFundamentals, []string{"os"}},
+	{"Computer Networks", SkillCategoryFundamentals, []string{"networking", "cn"}},
+	{"DBMS", SkillCategoryFundamentals, []string{"database management systems"}},
+	{"System Design", SkillCategoryFundamentals, nil},
+
+	{"Git", SkillCategoryTools, []string{"github", "version control"}},
+	{"Microsoft Excel", SkillCategoryTools, []string{"excel", "ms excel"}},
+	{"Jira", SkillCategoryTools, nil},
+
+	{"Figma", SkillCategoryDesign, nil},
+	{"UI/UX Design", SkillCategoryDesign, []string{"ui/ux", "ux", "ui design", "ux design"}},
+
+	{"Communication", SkillCategorySoft, []string{"communication skills"}},
+	{"Leadership", SkillCategorySoft, nil},
+	{"Teamwork", SkillCategorySoft, []string{"team work", "team player"}},
+	{"Problem Solving", SkillCategorySoft, nil},
+}
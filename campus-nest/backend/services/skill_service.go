@@ -0,0 +1,604 @@
+﻿package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DefaultSkillSuggestions = 10
+	MaxSkillSuggestions     = 50
+
+	// Other instances may change the taxonomy, so the cached copy is
+	// reloaded this often.
+	skillIndexTTL = time.Minute
+	// Multi-word skills split on spaces, as in "Machine Learning", are
+	// joined back up to this many words.
+	maxSkillWords = 4
+
+	skillTaxonomyMigration = "skill-taxonomy"
+	maxUnmappedSkills      = 50
+)
+
+var SkillListSpec = ListSpec{
+	Sorts: map[string]string{
+		"name":      "name",
+		"category":  "category",
+		"updatedAt": "updated_at",
+	},
+	DefaultSort: "name",
+}
+
+// skillFields are the stored skill lists kept in canonical form, by
+// collection.
+var skillFields = []struct {
+	collection string
+	field      string
+}{
+	{"users", "skills"},
+	{"jobs", "eligibility.skills"},
+	{"drive_templates", "eligibility.skills"},
+	{"resumes", "parsed_data.skills"},
+	{"applications", "snapshot.skills"},
+	{"applications", "snapshot.resume.parsed_data.skills"},
+}
+
+// skillKey is how spellings of a skill are compared: without case, spaces,
+// dots, dashes or underscores, so "Node.js", "nodejs" and "Node JS" agree
+// while "C", "C++" and "C#" stay apart.
+func skillKey(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsSpace(r) || r == '.' || r == '-' || r == '_' {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// skillKeys returns the distinct keys of a skill's name and aliases, the
+// name's first.
+func skillKeys(name string, aliases []string) []string {
+	keys := []string{skillKey(name)}
+	for _, alias := range aliases {
+		if key := skillKey(alias); key != "" && !containsString(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+var (
+	skillIndexMu     sync.Mutex
+	skillIndex       map[string]string
+	skillIndexLoaded time.Time
+)
+
+// invalidateSkillIndex makes the next normalisation reload the taxonomy.
+func invalidateSkillIndex() {
+	skillIndexMu.Lock()
+	skillIndex = nil
+	skillIndexMu.Unlock()
+}
+
+// skillTaxonomy maps free-text skills to their canonical names. Services
+// that store skills hold one and run every list through normalize.
+type skillTaxonomy struct {
+	collection *mongo.Collection
+}
+
+func newSkillTaxonomy(db *mongo.Database) *skillTaxonomy {
+	return &skillTaxonomy{collection: db.Collection("skills")}
+}
+
+// names maps every key of the taxonomy to its skill's canonical name.
+func (t *skillTaxonomy) names(ctx context.Context) (map[string]string, error) {
+	skillIndexMu.Lock()
+	defer skillIndexMu.Unlock()
+	if skillIndex != nil && time.Since(skillIndexLoaded) < skillIndexTTL {
+		return skillIndex, nil
+	}
+
+	cursor, err := t.collection.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"name": 1, "keys": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var skills []models.Skill
+	if err := cursor.All(ctx, &skills); err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(skills)*2)
+	for _, skill := range skills {
+		for _, key := range skill.Keys {
+			names[key] = skill.Name
+		}
+	}
+	skillIndex = names
+	skillIndexLoaded = time.Now()
+	return names, nil
+}
+
+func (t *skillTaxonomy) normalize(ctx context.Context, skills []string) ([]string, error) {
+	if len(skills) == 0 {
+		return skills, nil
+	}
+	names, err := t.names(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return canonicalSkills(names, skills), nil
+}
+
+// normalizeWords is normalize for a list split on spaces. Runs of words that
+// together spell a known skill are joined again.
+func (t *skillTaxonomy) normalizeWords(ctx context.Context, words []string) ([]string, error) {
+	if len(words) == 0 {
+		return words, nil
+	}
+	names, err := t.names(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var joined []string
+	for i := 0; i < len(words); {
+		n := 1
+		for size := maxSkillWords; size > 1; size-- {
+			if i+size <= len(words) {
+				if _, ok := names[skillKey(strings.Join(words[i:i+size], ""))]; ok {
+					n = size
+					break
+				}
+			}
+		}
+		joined = append(joined, strings.Join(words[i:i+n], " "))
+		i += n
+	}
+	return canonicalSkills(names, joined), nil
+}
+
+// canonicalSkills replaces known skills with their canonical names, tidies
+// the spacing of unknown ones and drops repeats, keeping the order.
+func canonicalSkills(names map[string]string, skills []string) []string {
+	out := make([]string, 0, len(skills))
+	seen := make(map[string]bool, len(skills))
+	for _, skill := range skills {
+		skill = strings.Join(strings.Fields(skill), " ")
+		key := skillKey(skill)
+		if key == "" {
+			continue
+		}
+		if name, ok := names[key]; ok {
+			skill = name
+			key = skillKey(name)
+		}
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, skill)
+		}
+	}
+	return out
+}
+
+type SkillServiceImpl struct {
+	skillCollection *mongo.Collection
+	db              *mongo.Database
+	taxonomy        *skillTaxonomy
+}
+
+func NewSkillService(db *mongo.Database) SkillService {
+	return &SkillServiceImpl{
+		skillCollection: db.Collection("skills"),
+		db:              db,
+		taxonomy:        newSkillTaxonomy(db),
+	}
+}
+
+// EnsureSkillTaxonomy indexes the taxonomy, loads the default skills into an
+// empty one, and the first time it runs brings the skills already stored up
+// to date with it.
+func EnsureSkillTaxonomy(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	skills := db.Collection("skills")
+	_, err := skills.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "keys", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "category", Value: 1}, {Key: "name", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	count, err := skills.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		now := time.Now()
+		docs := make([]interface{}, 0, len(defaultSkills))
+		for _, seed := range defaultSkills {
+			aliases := seed.aliases
+			if aliases == nil {
+				aliases = []string{}
+			}
+			docs = append(docs, models.Skill{
+				Name:      seed.name,
+				Aliases:   aliases,
+				Category:  seed.category,
+				Keys:      skillKeys(seed.name, aliases),
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+		}
+		if _, err := skills.InsertMany(ctx, docs); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+		invalidateSkillIndex()
+	}
+
+	migrations := db.Collection("migrations")
+	err = migrations.FindOne(ctx, bson.M{"_id": skillTaxonomyMigration}).Err()
+	if err == nil {
+		return nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return err
+	}
+	report, err := NewSkillService(db).NormalizeStoredSkills()
+	if err != nil {
+		return err
+	}
+	log.Printf("Normalised stored skills: %d user(s), %d drive(s), %d template(s), %d resume(s), %d application(s) updated",
+		report.Users, report.Jobs, report.Templates, report.Resumes, report.Applications)
+
+	// The normalisation has its own, longer timeout.
+	saveCtx, saveCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer saveCancel()
+	_, err = migrations.InsertOne(saveCtx, bson.M{"_id": skillTaxonomyMigration, "applied_at": time.Now(), "report": report})
+	return err
+}
+
+func (ss *SkillServiceImpl) ListSkills(search, category string, q *ListQuery) (*ListPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if key := skillKey(search); key != "" {
+		filter["keys"] = primitive.Regex{Pattern: regexp.QuoteMeta(key)}
+	}
+	if category = strings.TrimSpace(category); category != "" {
+		filter["category"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(category) + "$", Options: "i"}
+	}
+	page, _, err := FindPage[*models.Skill](ctx, ss.skillCollection, filter, q)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// SuggestSkills autocompletes a skill from the start of its name or of one of
+// its aliases. Skills whose name matches come first.
+func (ss *SkillServiceImpl) SuggestSkills(prefix string, limit int) ([]SkillSuggestion, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if limit <= 0 {
+		limit = DefaultSkillSuggestions
+	}
+	if limit > MaxSkillSuggestions {
+		return nil, newValidationError("limit", fmt.Sprintf("Limit can be at most %d", MaxSkillSuggestions))
+	}
+	key := skillKey(prefix)
+	if key == "" {
+		return nil, newValidationError("q", "Type part of a skill name")
+	}
+
+	// The anchored, case-sensitive prefix uses the keys index.
+	cursor, err := ss.skillCollection.Find(ctx,
+		bson.M{"keys": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(key)}},
+		options.Find().SetLimit(200))
+	if err != nil {
+		return nil, err
+	}
+	var skills []models.Skill
+	if err := cursor.All(ctx, &skills); err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]SkillSuggestion, 0, len(skills))
+	for _, skill := range skills {
+		suggestion := SkillSuggestion{Name: skill.Name, Category: skill.Category}
+		if !strings.HasPrefix(skillKey(skill.Name), key) {
+			for _, alias := range skill.Aliases {
+				if strings.HasPrefix(skillKey(alias), key) {
+					suggestion.MatchedAlias = alias
+					break
+				}
+			}
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if (a.MatchedAlias == "") != (b.MatchedAlias == "") {
+			return a.MatchedAlias == ""
+		}
+		if len(a.Name) != len(b.Name) {
+			return len(a.Name) < len(b.Name)
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// cleanSkillInput validates a skill and drops aliases that repeat the name
+// or each other.
+func cleanSkillInput(input *SkillInput) (*models.Skill, error) {
+	name := strings.Join(strings.Fields(input.Name), " ")
+	if skillKey(name) == "" {
+		return nil, newValidationError("name", "Skill name is required")
+	}
+	if len(name) > 60 {
+		return nil, newValidationError("name", "Skill name can be at most 60 characters")
+	}
+	category := strings.Join(strings.Fields(input.Category), " ")
+	if category == "" {
+		return nil, newValidationError("category", "Category is required")
+	}
+
+	skill := &models.Skill{Name: name, Category: category, Aliases: []string{}}
+	seen := []string{skillKey(name)}
+	for _, alias := range input.Aliases {
+		alias = strings.Join(strings.Fields(alias), " ")
+		key := skillKey(alias)
+		if key == "" || containsString(seen, key) {
+			continue
+		}
+		if len(alias) > 60 {
+			return nil, newValidationError("aliases", "Aliases can be at most 60 characters")
+		}
+		seen = append(seen, key)
+		skill.Aliases = append(skill.Aliases, alias)
+	}
+	skill.Keys = seen
+	return skill, nil
+}
+
+func (ss *SkillServiceImpl) CreateSkill(input *SkillInput, createdBy primitive.ObjectID) (*models.Skill, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	skill, err := cleanSkillInput(input)
+	if err != nil {
+		return nil, err
+	}
+	skill.ID = primitive.NewObjectID()
+	skill.CreatedBy = createdBy
+	skill.CreatedAt = time.Now()
+	skill.UpdatedAt = skill.CreatedAt
+
+	if _, err := ss.skillCollection.InsertOne(ctx, skill); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, newValidationError("name", "Another skill already uses this name or one of these aliases")
+		}
+		return nil, err
+	}
+	invalidateSkillIndex()
+	return skill, nil
+}
+
+// UpdateSkill replaces a skill's name, aliases and category. A renamed skill
+// keeps its old name as an alias so data stored under it still matches.
+func (ss *SkillServiceImpl) UpdateSkill(skillID primitive.ObjectID, input *SkillInput) (*models.Skill, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var current models.Skill
+	if err := ss.skillCollection.FindOne(ctx, bson.M{"_id": skillID}).Decode(&current); err != nil {
+		return nil, err
+	}
+	if skillKey(input.Name) != skillKey(current.Name) {
+		input.Aliases = append(input.Aliases, current.Name)
+	}
+	skill, err := cleanSkillInput(input)
+	if err != nil {
+		return nil, err
+	}
+	skill.ID = current.ID
+	skill.CreatedBy = current.CreatedBy
+	skill.CreatedAt = current.CreatedAt
+	skill.UpdatedAt = time.Now()
+
+	if _, err := ss.skillCollection.ReplaceOne(ctx, bson.M{"_id": skillID}, skill); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, newValidationError("name", "Another skill already uses this name or one of these aliases")
+		}
+		return nil, err
+	}
+	invalidateSkillIndex()
+	return skill, nil
+}
+
+// DeleteSkill removes a skill from the taxonomy. Stored skills keep their
+// canonical spelling.
+func (ss *SkillServiceImpl) DeleteSkill(skillID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := ss.skillCollection.DeleteOne(ctx, bson.M{"_id": skillID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	invalidateSkillIndex()
+	return nil
+}
+
+func (ss *SkillServiceImpl) NormalizeSkills(skills []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return ss.taxonomy.normalize(ctx, skills)
+}
+
+// NormalizeStoredSkills rewrites every stored skill list in canonical form.
+// It runs once when the taxonomy is introduced and again whenever admins
+// want aliases added since applied to existing data. The report lists the
+// most common skills the taxonomy does not know, which are candidates for
+// new entries or aliases.
+func (ss *SkillServiceImpl) NormalizeStoredSkills() (*SkillNormalizationReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	invalidateSkillIndex()
+	names, err := ss.taxonomy.names(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SkillNormalizationReport{Unmapped: []SkillUsage{}}
+	unmapped := map[string]*SkillUsage{}
+	for _, target := range skillFields {
+		updated, err := normalizeSkillField(ctx, ss.db.Collection(target.collection), target.field, names, unmapped)
+		if err != nil {
+			return nil, err
+		}
+		switch target.collection {
+		case "users":
+			report.Users += updated
+		case "jobs":
+			report.Jobs += updated
+		case "drive_templates":
+			report.Templates += updated
+		case "resumes":
+			report.Resumes += updated
+		case "applications":
+			report.Applications += updated
+		}
+	}
+
+	for _, usage := range unmapped {
+		report.Unmapped = append(report.Unmapped, *usage)
+	}
+	sort.Slice(report.Unmapped, func(i, j int) bool {
+		if report.Unmapped[i].Count != report.Unmapped[j].Count {
+			return report.Unmapped[i].Count > report.Unmapped[j].Count
+		}
+		return report.Unmapped[i].Skill < report.Unmapped[j].Skill
+	})
+	if len(report.Unmapped) > maxUnmappedSkills {
+		report.Unmapped = report.Unmapped[:maxUnmappedSkills]
+	}
+	return report, nil
+}
+
+// normalizeSkillField rewrites the string array at field in each document
+// of coll and counts the spellings the taxonomy does not know.
+func normalizeSkillField(ctx context.Context, coll *mongo.Collection, field string, names map[string]string, unmapped map[string]*SkillUsage) (int64, error) {
+	cursor, err := coll.Find(ctx,
+		bson.M{field + ".0": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{field: 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var updates []mongo.WriteModel
+	var updated int64
+	flush := func() error {
+		if len(updates) == 0 {
+			return nil
+		}
+		result, err := coll.BulkWrite(ctx, updates, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return err
+		}
+		updated += result.ModifiedCount
+		updates = updates[:0]
+		return nil
+	}
+
+	path := strings.Split(field, ".")
+	for cursor.Next(ctx) {
+		value, err := cursor.Current.LookupErr(path...)
+		if err != nil {
+			continue
+		}
+		array, ok := value.ArrayOK()
+		if !ok {
+			continue
+		}
+		values, err := array.Values()
+		if err != nil {
+			return updated, err
+		}
+		var skills []string
+		for _, v := range values {
+			if s, ok := v.StringValueOK(); ok {
+				skills = append(skills, s)
+			}
+		}
+
+		if field == "skills" || field == "eligibility.skills" {
+			for _, skill := range skills {
+				key := skillKey(skill)
+				if _, known := names[key]; known || key == "" {
+					continue
+				}
+				if unmapped[key] == nil {
+					unmapped[key] = &SkillUsage{Skill: strings.Join(strings.Fields(skill), " ")}
+				}
+				unmapped[key].Count++
+			}
+		}
+
+		normalized := canonicalSkills(names, skills)
+		if len(normalized) == len(values) && equalStrings(normalized, skills) {
+			continue
+		}
+		updates = append(updates, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": cursor.Current.Lookup("_id")}).
+			SetUpdate(bson.M{"$set": bson.M{field: normalized}}))
+		if len(updates) >= 500 {
+			if err := flush(); err != nil {
+				return updated, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return updated, err
+	}
+	return updated, flush()
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+﻿package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// readSpreadsheet returns the rows of a CSV file or of the first sheet of an
+// XLSX workbook, picked by file extension.
+func readSpreadsheet(r io.Reader, filename string) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, newValidationError("file", fmt.Sprintf("The CSV file could not be read: %v", err))
+		}
+		return rows, nil
+	case ".xlsx":
+		book, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, newValidationError("file", fmt.Sprintf("The workbook could not be read: %v", err))
+		}
+		defer book.Close()
+
+		sheets := book.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, newValidationError("file", "The workbook has no sheets")
+		}
+		rows, err := book.GetRows(sheets[0])
+		if err != nil {
+			return nil, newValidationError("file", fmt.Sprintf("The sheet %q could not be read: %v", sheets[0], err))
+		}
+		return rows, nil
+	default:
+		return nil, newValidationError("file", fmt.Sprintf("Unsupported file type %q, upload a .csv or .xlsx file", filepath.Ext(filename)))
+	}
+}
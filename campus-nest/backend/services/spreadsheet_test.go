@@ -0,0 +1,36 @@
+﻿package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadSpreadsheet(t *testing.T) {
+	rows, err := readSpreadsheet(strings.NewReader("position, company\nSDE,Acme,extra\n"), "drives.CSV")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || strings.Join(rows[0], "|") != "position|company" || len(rows[1]) != 3 {
+		t.Errorf("readSpreadsheet() = %q", rows)
+	}
+}
+
+func TestReadSpreadsheetErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		filename string
+	}{
+		{"malformed CSV", "position,company\n\"SDE,Acme\n", "drives.csv"},
+		{"not a workbook", "position,company\n", "drives.xlsx"},
+		{"unsupported type", "position,company\n", "drives.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readSpreadsheet(strings.NewReader(tt.body), tt.filename)
+			if !IsValidationError(err) {
+				t.Errorf("readSpreadsheet() = %v, want a validation error", err)
+			}
+		})
+	}
+}
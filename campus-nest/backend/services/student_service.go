@@ -2,6 +2,12 @@
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"backend/models"
@@ -9,12 +15,25 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const studentBatchMigration = "student-graduation-year"
+
+var educationYear = regexp.MustCompile(`\b(?:19|20)\d{2}\b`)
+
+const (
+	// maxProfileEntries caps each list on the profile.
+	maxProfileEntries = 20
+	// maxProfileText caps free text such as project descriptions.
+	maxProfileText = 2000
 )
 
 type StudentServiceImpl struct {
 	userCollection        *mongo.Collection
 	resumeCollection      *mongo.Collection
 	applicationCollection *mongo.Collection
+	skills                *skillTaxonomy
 }
 
 func NewStudentService(db *mongo.Database) StudentService {
@@ -22,7 +41,76 @@ func NewStudentService(db *mongo.Database) StudentService {
 		userCollection:        db.Collection("users"),
 		resumeCollection:      db.Collection("resumes"),
 		applicationCollection: db.Collection("applications"),
+		skills:                newSkillTaxonomy(db),
+	}
+}
+
+// validGraduationYear accepts the batches drives may be restricted to.
+func validGraduationYear(year int) bool {
+	return year >= 2000 && year <= time.Now().Year()+10
+}
+
+// graduationYearFromEducation takes the latest year in the student's
+// education history, which is when their degree ends. It returns 0 when no
+// entry has a usable year.
+func graduationYearFromEducation(education []models.Education) int {
+	latest := 0
+	for _, e := range education {
+		for _, match := range educationYear.FindAllString(e.Year, -1) {
+			if year, _ := strconv.Atoi(match); validGraduationYear(year) && year > latest {
+				latest = year
+			}
+		}
 	}
+	return latest
+}
+
+// EnsureStudentBatches runs once to fill in the graduation year of students
+// added before it was recorded, from their education history. Students
+// without a usable year keep an unknown batch.
+func EnsureStudentBatches(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	migrations := db.Collection("migrations")
+	err := migrations.FindOne(ctx, bson.M{"_id": studentBatchMigration}).Err()
+	if err == nil {
+		return nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	users := db.Collection("users")
+	cursor, err := users.Find(ctx,
+		bson.M{"role": "student", "graduationYear": bson.M{"$exists": false}, "education.0": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"education": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	var students []models.User
+	if err := cursor.All(ctx, &students); err != nil {
+		return err
+	}
+
+	var writes []mongo.WriteModel
+	for _, student := range students {
+		if year := graduationYearFromEducation(student.Education); year != 0 {
+			writes = append(writes, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": student.ID, "graduationYear": bson.M{"$exists": false}}).
+				SetUpdate(bson.M{"$set": bson.M{"graduationYear": year}}))
+		}
+	}
+	if len(writes) > 0 {
+		if _, err := users.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+			return err
+		}
+	}
+	log.Printf("Derived the graduation year of %d student(s) from their education", len(writes))
+
+	_, err = migrations.InsertOne(ctx, bson.M{"_id": studentBatchMigration, "applied_at": time.Now(), "updated": len(writes)})
+	return err
 }
 
 func (ss *StudentServiceImpl) GetStudentProfile(studentID primitive.ObjectID) (*StudentProfileResponse, error) {
@@ -63,6 +151,12 @@ func (ss *StudentServiceImpl) GetStudentProfile(studentID primitive.ObjectID) (*
 		Skills:     skills,
 		RollNumber: user.RollNumber,
 		ResumeLink: resumeLink,
+
+		GraduationYear:     user.GraduationYear,
+		PreferredLocations: user.PreferredLocations,
+		Education:          user.Education,
+		Projects:           user.Projects,
+		Qualifications:     user.Qualifications,
 	}, nil
 }
 
@@ -125,10 +219,165 @@ func (ss *StudentServiceImpl) UpdateStudentSkills(studentID primitive.ObjectID,
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	_, err := ss.userCollection.UpdateOne(
+	skills, err := ss.skills.normalize(ctx, skills)
+	if err != nil {
+		return err
+	}
+	_, err = ss.userCollection.UpdateOne(
 		ctx,
 		bson.M{"_id": studentID},
 		bson.M{"$set": bson.M{"skills": skills, "updatedAt": time.Now()}},
 	)
 	return err
 }
+
+// UpdateStudentProfile validates and saves the profile fields in update and
+// returns the updated profile.
+func (ss *StudentServiceImpl) UpdateStudentProfile(studentID primitive.ObjectID, update *ProfileUpdate) (*StudentProfileResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := validateProfileUpdate(update); err != nil {
+		return nil, err
+	}
+
+	set := bson.M{"updatedAt": time.Now()}
+	if update.Skills != nil {
+		skills, err := ss.skills.normalize(ctx, *update.Skills)
+		if err != nil {
+			return nil, err
+		}
+		set["skills"] = skills
+	}
+	if update.PreferredLocations != nil {
+		set["preferredLocations"] = *update.PreferredLocations
+	}
+	if update.GraduationYear != nil {
+		set["graduationYear"] = *update.GraduationYear
+	}
+	if update.Education != nil {
+		set["education"] = *update.Education
+	}
+	if update.Projects != nil {
+		set["projects"] = *update.Projects
+	}
+	if update.Qualifications != nil {
+		set["qualifications"] = *update.Qualifications
+	}
+
+	result, err := ss.userCollection.UpdateOne(ctx, bson.M{"_id": studentID, "role": "student"}, bson.M{"$set": set})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return ss.GetStudentProfile(studentID)
+}
+
+// validateProfileUpdate checks a profile update and tidies it in place:
+// text is trimmed and repeated list values are dropped.
+func validateProfileUpdate(update *ProfileUpdate) error {
+	if update.Skills == nil && update.PreferredLocations == nil && update.GraduationYear == nil &&
+		update.Education == nil && update.Projects == nil && update.Qualifications == nil {
+		return newValidationError("profile", "Nothing to update")
+	}
+
+	tooMany := func(field string, n int) error {
+		if n > maxProfileEntries {
+			return newValidationError(field, fmt.Sprintf("%s can have at most %d entries", field, maxProfileEntries))
+		}
+		return nil
+	}
+	text := func(field string, value *string, required bool) error {
+		*value = strings.TrimSpace(*value)
+		if required && *value == "" {
+			return newValidationError(field, fmt.Sprintf("%s is required", field))
+		}
+		if len(*value) > maxProfileText {
+			return newValidationError(field, fmt.Sprintf("%s can be at most %d characters", field, maxProfileText))
+		}
+		return nil
+	}
+
+	if update.Skills != nil {
+		if err := tooMany("skills", len(*update.Skills)); err != nil {
+			return err
+		}
+	}
+	if update.PreferredLocations != nil {
+		locations := dedupeStrings(*update.PreferredLocations)
+		if err := tooMany("preferredLocations", len(locations)); err != nil {
+			return err
+		}
+		update.PreferredLocations = &locations
+	}
+	if update.GraduationYear != nil && !validGraduationYear(*update.GraduationYear) {
+		return newValidationError("graduationYear", fmt.Sprintf("Graduation year must be between 2000 and %d", time.Now().Year()+10))
+	}
+
+	if update.Education != nil {
+		if err := tooMany("education", len(*update.Education)); err != nil {
+			return err
+		}
+		for i := range *update.Education {
+			entry := &(*update.Education)[i]
+			field := fmt.Sprintf("education[%d]", i)
+			if err := text(field+".degree", &entry.Degree, true); err != nil {
+				return err
+			}
+			if err := text(field+".institution", &entry.Institution, true); err != nil {
+				return err
+			}
+			if err := text(field+".year", &entry.Year, false); err != nil {
+				return err
+			}
+			if gpa, ok := entry.GPA.(float64); ok && (gpa < 0 || gpa > 100) {
+				return newValidationError(field+".gpa", field+".gpa must be between 0 and 100")
+			}
+		}
+	}
+
+	if update.Projects != nil {
+		if err := tooMany("projects", len(*update.Projects)); err != nil {
+			return err
+		}
+		for i := range *update.Projects {
+			project := &(*update.Projects)[i]
+			field := fmt.Sprintf("projects[%d]", i)
+			if err := text(field+".title", &project.Title, true); err != nil {
+				return err
+			}
+			if err := text(field+".description", &project.Description, false); err != nil {
+				return err
+			}
+			if err := text(field+".link", &project.Link, false); err != nil {
+				return err
+			}
+			if project.Link != "" {
+				u, err := url.Parse(project.Link)
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return newValidationError(field+".link", field+".link must be an http or https URL")
+				}
+			}
+			project.Technologies = dedupeStrings(project.Technologies)
+		}
+	}
+
+	if update.Qualifications != nil {
+		if err := tooMany("qualifications", len(*update.Qualifications)); err != nil {
+			return err
+		}
+		for i := range *update.Qualifications {
+			qualification := &(*update.Qualifications)[i]
+			field := fmt.Sprintf("qualifications[%d]", i)
+			if err := text(field+".title", &qualification.Title, true); err != nil {
+				return err
+			}
+			if err := text(field+".description", &qualification.Description, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
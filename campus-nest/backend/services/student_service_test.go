@@ -0,0 +1,59 @@
+﻿package services
+
+import (
+	"testing"
+
+	"backend/models"
+)
+
+func TestValidateProfileUpdate(t *testing.T) {
+	year := func(y int) *int { return &y }
+	tests := []struct {
+		name   string
+		update ProfileUpdate
+		field  string
+	}{
+		{"graduation year only", ProfileUpdate{GraduationYear: year(2026)}, ""},
+		{"empty update", ProfileUpdate{}, "profile"},
+		{"graduation year out of range", ProfileUpdate{GraduationYear: year(1999)}, "graduationYear"},
+		{"project without a title", ProfileUpdate{Projects: &[]models.Project{{Title: "Portal"}, {Title: "  ", Description: "x"}}}, "projects[1].title"},
+		{"project link not a URL", ProfileUpdate{Projects: &[]models.Project{{Title: "Portal", Link: "javascript:alert(1)"}}}, "projects[0].link"},
+		{"education without an institution", ProfileUpdate{Education: &[]models.Education{{Degree: "B.Tech"}}}, "education[0].institution"},
+		{"education score out of range", ProfileUpdate{Education: &[]models.Education{{Degree: "B.Tech", Institution: "NIT", GPA: 120.0}}}, "education[0].gpa"},
+		{"qualification without a title", ProfileUpdate{Qualifications: &[]models.Qualification{{Description: "AWS"}}}, "qualifications[0].title"},
+		{"too many skills", ProfileUpdate{Skills: &[]string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p", "q", "r", "s", "t", "u"}}, "skills"},
+		{"cleared lists", ProfileUpdate{Skills: &[]string{}, Projects: &[]models.Project{}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateProfileUpdate(&tt.update)
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("validateProfileUpdate() = %v, want nil", err)
+				}
+				return
+			}
+			verr, ok := err.(*ValidationError)
+			if !ok || verr.Field != tt.field {
+				t.Errorf("validateProfileUpdate() = %v, want a validation error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestValidateProfileUpdateTidies(t *testing.T) {
+	update := ProfileUpdate{
+		PreferredLocations: &[]string{" Pune ", "pune", "", "Remote"},
+		Projects:           &[]models.Project{{Title: " Portal ", Link: " https://example.com/portal ", Technologies: []string{"Go", "go ", ""}}},
+	}
+	if err := validateProfileUpdate(&update); err != nil {
+		t.Fatal(err)
+	}
+	if got := *update.PreferredLocations; len(got) != 2 || got[0] != "Pune" || got[1] != "Remote" {
+		t.Errorf("PreferredLocations = %q", got)
+	}
+	project := (*update.Projects)[0]
+	if project.Title != "Portal" || project.Link != "https://example.com/portal" || len(project.Technologies) != 1 {
+		t.Errorf("project = %+v", project)
+	}
+}
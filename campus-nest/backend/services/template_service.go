@@ -0,0 +1,155 @@
+﻿package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type TemplateServiceImpl struct {
+	templateCollection *mongo.Collection
+	jobCollection      *mongo.Collection
+
+	jobService JobService
+}
+
+func NewTemplateService(db *mongo.Database) TemplateService {
+	return &TemplateServiceImpl{
+		templateCollection: db.Collection("drive_templates"),
+		jobCollection:      db.Collection("jobs"),
+
+		jobService: NewJobService(db),
+	}
+}
+
+var TemplateListSpec = ListSpec{
+	Sorts: map[string]string{
+		"name":      "name",
+		"company":   "company_name.name",
+		"updatedAt": "updated_at",
+	},
+	DefaultSort: "-updatedAt",
+}
+
+func (ts *TemplateServiceImpl) ListTemplates(companyID *primitive.ObjectID, q *ListQuery) (*ListPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if companyID != nil {
+		filter["company_name.companyId"] = *companyID
+	}
+
+	page, _, err := FindPage[*models.DriveTemplate](ctx, ts.templateCollection, filter, q)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (ts *TemplateServiceImpl) GetTemplate(templateID primitive.ObjectID) (*models.DriveTemplate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var template models.DriveTemplate
+	if err := ts.templateCollection.FindOne(ctx, bson.M{"_id": templateID}).Decode(&template); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// SaveJobAsTemplate stores the reusable parts of a drive. Saving again under
+// the same name for the same company overwrites the earlier template.
+func (ts *TemplateServiceImpl) SaveJobAsTemplate(jobID primitive.ObjectID, name string, createdBy primitive.ObjectID) (*models.DriveTemplate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var job models.Job
+	if err := ts.jobCollection.FindOne(ctx, bson.M{"_id": jobID}).Decode(&job); err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = job.Position
+	}
+
+	now := time.Now()
+	template := models.DriveTemplate{
+		Name:        name,
+		CompanyName: job.CompanyName,
+		Position:    job.Position,
+		Description: job.Description,
+		Eligibility: job.Eligibility,
+		SalaryRange: job.SalaryRange,
+		JobType:     job.JobType,
+		Internship:  job.Internship,
+		Location:    job.Location,
+		SourceJobID: &job.ID,
+		CreatedBy:   createdBy,
+		UpdatedAt:   now,
+	}
+	// Batch and graduation year belong to a single cycle, not the template.
+	template.Eligibility.Batch = nil
+	template.Eligibility.GraduationYear = 0
+
+	filter := bson.M{"company_name.companyId": job.CompanyName.CompanyID, "name": name}
+	var existing models.DriveTemplate
+	err := ts.templateCollection.FindOne(ctx, filter).Decode(&existing)
+	switch err {
+	case nil:
+		template.ID = existing.ID
+		template.CreatedAt = existing.CreatedAt
+		_, err = ts.templateCollection.ReplaceOne(ctx, bson.M{"_id": existing.ID}, template)
+	case mongo.ErrNoDocuments:
+		template.ID = primitive.NewObjectID()
+		template.CreatedAt = now
+		_, err = ts.templateCollection.InsertOne(ctx, template)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (ts *TemplateServiceImpl) DeleteTemplate(templateID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := ts.templateCollection.DeleteOne(ctx, bson.M{"_id": templateID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (ts *TemplateServiceImpl) CreateDriveFromTemplate(templateID primitive.ObjectID, cycle *DriveCycle, createdBy primitive.ObjectID) (*models.Job, error) {
+	template, err := ts.GetTemplate(templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.Job{
+		CompanyName: template.CompanyName,
+		Position:    template.Position,
+		Description: template.Description,
+		Eligibility: template.Eligibility,
+		SalaryRange: template.SalaryRange,
+		JobType:     template.JobType,
+		Internship:  template.Internship,
+		Location:    template.Location,
+	}
+	if err := ts.jobService.CreateDraft(job, cycle, createdBy); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
@@ -69,7 +69,19 @@ func (ts *TPOServiceImpl) GetTPOProfile(tpoID primitive.ObjectID) (*TPOProfileRe
 	}, nil
 }
 
-func (ts *TPOServiceImpl) GetStudentsInDepartment(tpoID primitive.ObjectID, searchQuery string) (*StudentsResponse, error) {
+var StudentListSpec = ListSpec{
+	Sorts: map[string]string{
+		"firstName":  "firstName",
+		"lastName":   "lastName",
+		"rollNumber": "rollNumber",
+		"department": "department",
+		"cgpa":       "cgpa",
+		"createdAt":  "createdAt",
+	},
+	DefaultSort: "firstName",
+}
+
+func (ts *TPOServiceImpl) GetStudentsInDepartment(tpoID primitive.ObjectID, searchQuery string, q *ListQuery) (*ListPage, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -79,15 +91,14 @@ func (ts *TPOServiceImpl) GetStudentsInDepartment(tpoID primitive.ObjectID, sear
 	if err != nil {
 		return nil, err
 	}
-
-
-	query := bson.M{
-		"role": "student",
+	if tpo.Department == nil || *tpo.Department == "" {
+		return nil, newValidationError("department", "TPO department not set; cannot fetch students")
 	}
 
 
-	if tpo.Department != nil && *tpo.Department != "" {
-		query["department"] = *tpo.Department
+	query := bson.M{
+		"role":       "student",
+		"department": *tpo.Department,
 	}
 
 
@@ -100,36 +111,19 @@ func (ts *TPOServiceImpl) GetStudentsInDepartment(tpoID primitive.ObjectID, sear
 	}
 
 
-	cursor, err := ts.userCollection.Find(ctx, query)
+	page, students, err := FindPage[models.User](ctx, ts.userCollection, query, q)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
-
-	var students []models.User
-	if err = cursor.All(ctx, &students); err != nil {
-		return nil, err
-	}
-
 
-	placedCount := 0
-	unplacedCount := 0
 	studentData := make([]*StudentInfo, len(students))
-
 	for i, student := range students {
 
-		placementStatus := "Unplaced"
+		placementStatus := PlacementStatusUnplaced
 		if student.PlacementStatus != nil && *student.PlacementStatus != "" {
 			placementStatus = *student.PlacementStatus
 		}
 
-
-		if placementStatus == "Placed" {
-			placedCount++
-		} else {
-			unplacedCount++
-		}
-
 		studentData[i] = &StudentInfo{
 			ID:              student.ID,
 			FirstName:       student.FirstName,
@@ -144,19 +138,25 @@ func (ts *TPOServiceImpl) GetStudentsInDepartment(tpoID primitive.ObjectID, sear
 	}
 
 
-	totalStudents := len(students)
+	// The statistics cover every matching student, not only this page.
+	placedCount, err := ts.userCollection.CountDocuments(ctx, bson.M{
+		"$and": bson.A{query, bson.M{"placedStatus": PlacementStatusPlaced}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	placementRate := 0.0
-	if totalStudents > 0 {
-		placementRate = float64(placedCount) / float64(totalStudents) * 100
+	if page.Total > 0 {
+		placementRate = float64(placedCount) / float64(page.Total) * 100
 	}
 
-	return &StudentsResponse{
-		Students: studentData,
-		Statistics: &PlacementStats{
-			TotalStudents:    totalStudents,
-			PlacedStudents:   placedCount,
-			UnplacedStudents: unplacedCount,
-			PlacementRate:    placementRate,
-		},
-	}, nil
+	page.Items = studentData
+	page.Summary = &PlacementStats{
+		TotalStudents:    int(page.Total),
+		PlacedStudents:   int(placedCount),
+		UnplacedStudents: int(page.Total - placedCount),
+		PlacementRate:    placementRate,
+	}
+	return page, nil
 }
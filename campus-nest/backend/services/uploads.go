@@ -0,0 +1,64 @@
+﻿package services
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// Resumes must be PDFs, the only format the parser reads.
+var resumeContentTypes = []string{"application/pdf"}
+
+var documentContentTypes = []string{
+	"application/pdf",
+	"application/msword",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+}
+
+// sniffUpload detects the type of an uploaded file from its content rather
+// than trusting the name or the client's Content-Type. It returns the
+// detected type, its extension and a reader that still yields the whole
+// file.
+func sniffUpload(r io.Reader, allowed []string, field, kinds string) (string, string, io.Reader, error) {
+	head := make([]byte, 3072)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", "", nil, err
+	}
+	head = head[:n]
+
+	detected := mimetype.Detect(head)
+	for _, contentType := range allowed {
+		if detected.Is(contentType) {
+			return contentType, detected.Extension(), io.MultiReader(bytes.NewReader(head), r), nil
+		}
+	}
+	return "", "", nil, newValidationError(field, fmt.Sprintf("Only %s files are accepted", kinds))
+}
+
+func checkUploadSize(size, max int64, field string) error {
+	if size <= 0 {
+		return newValidationError(field, "The uploaded file is empty")
+	}
+	if size > max {
+		return newValidationError(field, fmt.Sprintf("Files can be at most %d MB", max>>20))
+	}
+	return nil
+}
+
+// uploadFileName keeps the base name of what the client sent, falling back
+// to a generic name with the detected extension.
+func uploadFileName(name, fallback, ext string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return fallback + ext
+	}
+	if len(name) > 200 {
+		name = name[len(name)-200:]
+	}
+	return name
+}
@@ -0,0 +1,324 @@
+﻿package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WaitlistServiceImpl struct {
+	jobCollection         *mongo.Collection
+	applicationCollection *mongo.Collection
+	userCollection        *mongo.Collection
+
+	placementService PlacementService
+}
+
+func NewWaitlistService(db *mongo.Database) WaitlistService {
+	return &WaitlistServiceImpl{
+		jobCollection:         db.Collection("jobs"),
+		applicationCollection: db.Collection("applications"),
+		userCollection:        db.Collection("users"),
+
+		placementService: NewPlacementService(db),
+	}
+}
+
+func (ws *WaitlistServiceImpl) GetWaitlist(jobID primitive.ObjectID) ([]*models.Application, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return ws.waitlist(ctx, jobID)
+}
+
+var WaitlistListSpec = ListSpec{
+	Sorts:       map[string]string{"rank": "waitlist_rank"},
+	DefaultSort: "rank",
+}
+
+func (ws *WaitlistServiceImpl) ListWaitlist(jobID primitive.ObjectID, q *ListQuery) (*ListPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"job_id": jobID, "status": models.ApplicationStatusWaitlisted}
+	page, _, err := FindPage[*models.Application](ctx, ws.applicationCollection, filter, q)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (ws *WaitlistServiceImpl) waitlist(ctx context.Context, jobID primitive.ObjectID) ([]*models.Application, error) {
+	cursor, err := ws.applicationCollection.Find(ctx,
+		bson.M{"job_id": jobID, "status": models.ApplicationStatusWaitlisted},
+		options.Find().SetSort(bson.D{{Key: "waitlist_rank", Value: 1}, {Key: "updated_on", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	applications := []*models.Application{}
+	if err = cursor.All(ctx, &applications); err != nil {
+		return nil, err
+	}
+	return applications, nil
+}
+
+func (ws *WaitlistServiceImpl) AddToWaitlist(jobID, studentID primitive.ObjectID, remarks string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rank, err := ws.nextRank(ctx, jobID)
+	if err != nil {
+		return 0, err
+	}
+
+	set := bson.M{
+		"status":        models.ApplicationStatusWaitlisted,
+		"waitlist_rank": rank,
+		"updated_on":    time.Now(),
+	}
+	if remarks != "" {
+		set["remarks"] = remarks
+	}
+	result, err := ws.applicationCollection.UpdateOne(ctx,
+		bson.M{"job_id": jobID, "student_id": studentID, "status": bson.M{"$ne": models.ApplicationStatusWaitlisted}},
+		bson.M{"$set": set},
+	)
+	if err != nil {
+		return 0, err
+	}
+	if result.MatchedCount == 0 {
+		var existing models.Application
+		if err := ws.applicationCollection.FindOne(ctx, bson.M{"job_id": jobID, "student_id": studentID}).Decode(&existing); err != nil {
+			return 0, err
+		}
+		return existing.WaitlistRank, nil
+	}
+	return rank, nil
+}
+
+// waitlistCounter is the drive's count of waitlist ranks handed out.
+type waitlistCounter struct {
+	Seq int `bson:"waitlist_rank_seq"`
+}
+
+// nextRank hands out the next waitlist rank from a counter on the drive, so
+// students waitlisted at the same time never share a rank. Drives
+// waitlisted on before the counter existed start it after their last rank.
+func (ws *WaitlistServiceImpl) nextRank(ctx context.Context, jobID primitive.ObjectID) (int, error) {
+	inc := func() (int, error) {
+		var counter waitlistCounter
+		err := ws.jobCollection.FindOneAndUpdate(ctx,
+			bson.M{"_id": jobID, "waitlist_rank_seq": bson.M{"$exists": true}},
+			bson.M{"$inc": bson.M{"waitlist_rank_seq": 1}},
+			options.FindOneAndUpdate().SetProjection(bson.M{"waitlist_rank_seq": 1}).SetReturnDocument(options.After),
+		).Decode(&counter)
+		return counter.Seq, err
+	}
+
+	rank, err := inc()
+	if err != mongo.ErrNoDocuments {
+		return rank, err
+	}
+
+	var last models.Application
+	err = ws.applicationCollection.FindOne(ctx,
+		bson.M{"job_id": jobID, "status": models.ApplicationStatusWaitlisted},
+		options.FindOne().SetSort(bson.D{{Key: "waitlist_rank", Value: -1}}),
+	).Decode(&last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return 0, err
+	}
+	if _, err := ws.jobCollection.UpdateOne(ctx,
+		bson.M{"_id": jobID, "waitlist_rank_seq": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"waitlist_rank_seq": last.WaitlistRank}},
+	); err != nil {
+		return 0, err
+	}
+	return inc()
+}
+
+// rankCounter reads the drive's rank counter before the waitlist is
+// renumbered, for resetRankCounter.
+func (ws *WaitlistServiceImpl) rankCounter(ctx context.Context, jobID primitive.ObjectID) (int, error) {
+	var counter waitlistCounter
+	err := ws.jobCollection.FindOne(ctx, bson.M{"_id": jobID},
+		options.FindOne().SetProjection(bson.M{"waitlist_rank_seq": 1})).Decode(&counter)
+	return counter.Seq, err
+}
+
+// resetRankCounter lets the next student waitlisted follow a waitlist that
+// was renumbered 1..n. It is skipped when someone was waitlisted meanwhile,
+// since their rank came after the counter that was read.
+func (ws *WaitlistServiceImpl) resetRankCounter(ctx context.Context, jobID primitive.ObjectID, seen, n int) error {
+	_, err := ws.jobCollection.UpdateOne(ctx,
+		bson.M{"_id": jobID, "waitlist_rank_seq": seen},
+		bson.M{"$set": bson.M{"waitlist_rank_seq": n}},
+	)
+	return err
+}
+
+func (ws *WaitlistServiceImpl) ReorderWaitlist(jobID primitive.ObjectID, studentIDs []primitive.ObjectID) ([]*models.Application, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	seen, err := ws.rankCounter(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := ws.waitlist(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if len(current) != len(studentIDs) {
+		return nil, newValidationError("studentIds", "The new order must list every waitlisted student exactly once")
+	}
+	waitlisted := make(map[primitive.ObjectID]bool, len(current))
+	for _, app := range current {
+		waitlisted[app.StudentID] = true
+	}
+	for _, id := range studentIDs {
+		if !waitlisted[id] {
+			return nil, newValidationError("studentIds", "The new order must list every waitlisted student exactly once")
+		}
+		delete(waitlisted, id)
+	}
+
+	for i, id := range studentIDs {
+		_, err := ws.applicationCollection.UpdateOne(ctx,
+			bson.M{"job_id": jobID, "student_id": id, "status": models.ApplicationStatusWaitlisted},
+			bson.M{"$set": bson.M{"waitlist_rank": i + 1}},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := ws.resetRankCounter(ctx, jobID, seen, len(studentIDs)); err != nil {
+		return nil, err
+	}
+	return ws.waitlist(ctx, jobID)
+}
+
+// CompactRanks renumbers the waitlist 1..n so gaps left by students who were
+// moved off the list do not survive.
+func (ws *WaitlistServiceImpl) CompactRanks(jobID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := ws.applicationCollection.UpdateMany(ctx,
+		bson.M{"job_id": jobID, "status": bson.M{"$ne": models.ApplicationStatusWaitlisted}, "waitlist_rank": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"waitlist_rank": ""}},
+	)
+	if err != nil {
+		return err
+	}
+	return ws.compact(ctx, jobID)
+}
+
+func (ws *WaitlistServiceImpl) compact(ctx context.Context, jobID primitive.ObjectID) error {
+	seen, err := ws.rankCounter(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	current, err := ws.waitlist(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	for i, app := range current {
+		if app.WaitlistRank == i+1 {
+			continue
+		}
+		if _, err := ws.applicationCollection.UpdateByID(ctx, app.ID, bson.M{"$set": bson.M{"waitlist_rank": i + 1}}); err != nil {
+			return err
+		}
+	}
+	return ws.resetRankCounter(ctx, jobID, seen, len(current))
+}
+
+func (ws *WaitlistServiceImpl) PromoteNext(jobID primitive.ObjectID) (*models.Application, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var job models.Job
+	if err := ws.jobCollection.FindOne(ctx, bson.M{"_id": jobID}).Decode(&job); err != nil {
+		return nil, err
+	}
+
+	var promoted models.Application
+	err := ws.applicationCollection.FindOneAndUpdate(ctx,
+		bson.M{"job_id": jobID, "status": models.ApplicationStatusWaitlisted},
+		bson.M{
+			"$set":   bson.M{"status": models.ApplicationStatusSelected, "updated_on": time.Now()},
+			"$unset": bson.M{"waitlist_rank": ""},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "waitlist_rank", Value: 1}, {Key: "updated_on", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&promoted)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ws.compact(ctx, jobID); err != nil {
+		return nil, err
+	}
+	if _, err := ws.placementService.SyncStudentPlacement(promoted.StudentID); err != nil {
+		return nil, err
+	}
+
+	pushNotification(ctx, ws.userCollection, []primitive.ObjectID{promoted.StudentID},
+		"You've been selected from the waitlist",
+		fmt.Sprintf("A position has opened up and you have been moved from the waitlist to selected for the position of %s at %s. Our HR team will contact you shortly with the offer letter and next steps.", job.Position, job.CompanyName.Name),
+	)
+	notifyCompanyRecruiters(ctx, ws.userCollection, job.CompanyName.CompanyID,
+		"Waitlisted candidate promoted",
+		fmt.Sprintf("The next waitlisted candidate has been promoted to selected for the position of %s.", job.Position),
+	)
+
+	return &promoted, nil
+}
+
+// HandleVacancy is called when a selected candidate drops out. Drives with
+// auto promotion enabled fill the seat from the waitlist straight away;
+// otherwise the recruiters are told a seat is free so they can promote.
+func (ws *WaitlistServiceImpl) HandleVacancy(jobID primitive.ObjectID) (*models.Application, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var job models.Job
+	if err := ws.jobCollection.FindOne(ctx, bson.M{"_id": jobID}).Decode(&job); err != nil {
+		return nil, err
+	}
+
+	waiting, err := ws.applicationCollection.CountDocuments(ctx, bson.M{"job_id": jobID, "status": models.ApplicationStatusWaitlisted})
+	if err != nil || waiting == 0 {
+		return nil, err
+	}
+
+	if job.AutoPromoteWaitlist {
+		return ws.PromoteNext(jobID)
+	}
+
+	notifyCompanyRecruiters(ctx, ws.userCollection, job.CompanyName.CompanyID,
+		"A selected candidate has dropped out",
+		fmt.Sprintf("A seat is open for the position of %s and %d candidate(s) are waitlisted. Promote the next candidate from the waitlist to fill it.", job.Position, waiting),
+	)
+	return nil, nil
+}
+
+func (ws *WaitlistServiceImpl) SetAutoPromote(jobID primitive.ObjectID, enabled bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := ws.jobCollection.UpdateByID(ctx, jobID, bson.M{"$set": bson.M{"auto_promote_waitlist": enabled}})
+	return err
+}
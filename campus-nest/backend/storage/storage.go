@@ -0,0 +1,84 @@
+﻿package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage keeps uploaded files. Keys are slash separated paths such as
+// "drives/<jobId>/<attachmentId>.pdf"; metadata like the original file name
+// and content type lives with the document that references the key.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	defaultStorage Storage
+	defaultMu      sync.Mutex
+)
+
+// Configure builds the storage backend selected by STORAGE_DRIVER ("local",
+// the default, or "s3") and makes it the one Default returns.
+func Configure() error {
+	s, err := FromEnv()
+	if err != nil {
+		return err
+	}
+	defaultMu.Lock()
+	defaultStorage = s
+	defaultMu.Unlock()
+	return nil
+}
+
+// Default returns the configured storage, falling back to a local store in
+// ./uploads when Configure was not called.
+func Default() Storage {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultStorage == nil {
+		defaultStorage = &Local{root: "uploads"}
+	}
+	return defaultStorage
+}
+
+func FromEnv() (Storage, error) {
+	switch driver := strings.ToLower(os.Getenv("STORAGE_DRIVER")); driver {
+	case "", "local":
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocal(dir)
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: os.Getenv("S3_PATH_STYLE") != "false",
+		})
+	default:
+		return nil, fmt.Errorf("storage: unknown STORAGE_DRIVER %q", driver)
+	}
+}
+
+// cleanKey rejects keys that could escape the store.
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + key)[1:]
+	if key == "" || cleaned != key {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+	return cleaned, nil
+}
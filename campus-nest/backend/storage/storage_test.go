@@ -0,0 +1,210 @@
+﻿package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testRoundTrip puts, reads, overwrites and deletes an object under prefix.
+func testRoundTrip(t *testing.T, s Storage, prefix string) {
+	t.Helper()
+	ctx := context.Background()
+	key := prefix + "drives/job/attachment.pdf"
+
+	put := func(body string) {
+		t.Helper()
+		if err := s.Put(ctx, key, strings.NewReader(body), int64(len(body)), "application/pdf"); err != nil {
+			t.Fatalf("Put(%q) = %v", key, err)
+		}
+	}
+	read := func() string {
+		t.Helper()
+		r, err := s.Open(ctx, key)
+		if err != nil {
+			t.Fatalf("Open(%q) = %v", key, err)
+		}
+		defer r.Close()
+		body, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	put("%PDF-1.4 first")
+	if got := read(); got != "%PDF-1.4 first" {
+		t.Errorf("Open() = %q, want the stored body", got)
+	}
+	put("%PDF-1.4 second")
+	if got := read(); got != "%PDF-1.4 second" {
+		t.Errorf("Open() after overwrite = %q, want the new body", got)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete(%q) = %v", key, err)
+	}
+	if _, err := s.Open(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open() after Delete = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Delete() of a missing object = %v, want nil", err)
+	}
+}
+
+func testInvalidKeys(t *testing.T, s Storage) {
+	t.Helper()
+	ctx := context.Background()
+	for _, key := range []string{"", "../secret", "drives/../../secret", "/drives/a.pdf", "drives//a.pdf", "drives/"} {
+		if err := s.Put(ctx, key, strings.NewReader("x"), 1, ""); err == nil {
+			t.Errorf("Put(%q) = nil, want an error", key)
+		}
+		if _, err := s.Open(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Open(%q) = %v, want an invalid key error", key, err)
+		}
+		if err := s.Delete(ctx, key); err == nil {
+			t.Errorf("Delete(%q) = nil, want an error", key)
+		}
+	}
+}
+
+func TestLocal(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewLocal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testRoundTrip(t, s, "")
+	testInvalidKeys(t, s)
+
+	// Nothing is left behind, not even the temporary upload files.
+	entries, err := os.ReadDir(root + "/drives/job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("files left in the store: %v", entries)
+	}
+}
+
+// fakeS3 is an in-memory bucket that checks requests are signed and
+// addressed path style.
+type fakeS3 struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=key/") || !strings.Contains(auth, "/us-east-1/s3/aws4_request") {
+		f.t.Errorf("%s %s has Authorization %q", r.Method, r.URL.Path, auth)
+	}
+	if r.Header.Get("X-Amz-Content-Sha256") != unsignedPayload || r.Header.Get("X-Amz-Date") == "" {
+		f.t.Errorf("%s %s is missing the signing headers", r.Method, r.URL.Path)
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/bucket/")
+	if !ok {
+		f.t.Errorf("request path %q is not path style", r.URL.Path)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[key] = string(body)
+	case http.MethodGet:
+		body, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, body)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3WithFakeServer(t *testing.T) {
+	server := httptest.NewServer(&fakeS3{t: t, objects: map[string]string{}})
+	defer server.Close()
+
+	s, err := NewS3(S3Config{Endpoint: server.URL + "/", Bucket: "bucket", AccessKey: "key", SecretKey: "secret", PathStyle: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testRoundTrip(t, s, "")
+	testInvalidKeys(t, s)
+}
+
+func TestS3Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	s, err := NewS3(S3Config{Endpoint: server.URL, Bucket: "bucket", AccessKey: "key", SecretKey: "secret", PathStyle: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Open(context.Background(), "drives/a.pdf")
+	if err == nil || errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("Open() = %v, want the service's error", err)
+	}
+	if err := s.Delete(context.Background(), "drives/a.pdf"); err == nil {
+		t.Error("Delete() = nil, want the service's error")
+	}
+}
+
+func TestS3ObjectURL(t *testing.T) {
+	tests := []struct {
+		endpoint  string
+		pathStyle bool
+		want      string
+	}{
+		{"http://localhost:9000", true, "http://localhost:9000/bucket/drives/a.pdf"},
+		{"https://s3.ap-south-1.amazonaws.com/", false, "https://bucket.s3.ap-south-1.amazonaws.com/drives/a.pdf"},
+	}
+	for _, tt := range tests {
+		s, err := NewS3(S3Config{Endpoint: tt.endpoint, Bucket: "bucket", AccessKey: "key", SecretKey: "secret", PathStyle: tt.pathStyle})
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := s.objectURL("drives/a.pdf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.String() != tt.want {
+			t.Errorf("objectURL() = %s, want %s", u, tt.want)
+		}
+	}
+}
+
+// TestS3 runs against a real bucket when S3_ENDPOINT is set, for example
+// the local MinIO described in the README:
+//
+//	S3_ENDPOINT=http://localhost:9000 S3_BUCKET=campus-nest \
+//	S3_ACCESS_KEY=minioadmin S3_SECRET_KEY=minioadmin go test ./storage
+func TestS3(t *testing.T) {
+	if os.Getenv("S3_ENDPOINT") == "" {
+		t.Skip("S3_ENDPOINT is not set")
+	}
+	t.Setenv("STORAGE_DRIVER", "s3")
+	s, err := FromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testRoundTrip(t, s, "storage-test/"+time.Now().Format("20060102T150405.000000000")+"/")
+	testInvalidKeys(t, s)
+}
@@ -4,8 +4,8 @@ import 'package:frontend/admin/admin_profile.dart';
 import 'package:frontend/custom_header.dart';
 import 'package:frontend/notifications.dart';
 import 'package:google_fonts/google_fonts.dart';
-import 'package:http/http.dart' as http;
 import 'package:shared_preferences/shared_preferences.dart';
+import 'package:frontend/pagination.dart';
 
 class OnboardCompanyPage extends StatelessWidget {
   final String? companyId;
@@ -52,12 +52,12 @@ class _CompaniesScreenState extends State<CompaniesScreen> {
       return;
     }
     // Assuming this is the GET endpoint for all companies
-    final url = Uri.parse('https://campusnest-backend-lkue.onrender.com/api/v1/admin/companies');
+    final url = Uri.parse('https://campusnest-backend-lkue.onrender.com/api/v1/admin/companies?limit=100');
     try {
-      final response = await http.get(url, headers: {'Authorization': 'Bearer $token'});
+      final response = await getAllPages(url, headers: {'Authorization': 'Bearer $token'});
       if (response.statusCode == 200 && mounted) {
         setState(() {
-          _companies = jsonDecode(response.body)['companies'] ?? [];
+          _companies = jsonDecode(response.body)['items'] ?? [];
           _isLoading = false;
         });
       } else {
@@ -5,6 +5,7 @@ import 'package:http/http.dart' as http;
 import 'package:shared_preferences/shared_preferences.dart';
 
 import '../toast.dart'; // Make sure the path to your toast.dart is correct
+import 'package:frontend/pagination.dart';
 
 // Enum to manage which form is visible
 enum OnboardMode { newCompany, existingCompany }
@@ -81,13 +82,13 @@ class _OnboardCompanyPageState extends State<OnboardCompanyPage> {
 
   Future<void> _fetchCompanies() async {
     // Assuming this is the endpoint to get all companies
-    final url = Uri.parse("https://campusnest-backend-lkue.onrender.com/api/v1/admin/companies");
+    final url = Uri.parse("https://campusnest-backend-lkue.onrender.com/api/v1/admin/companies?limit=100");
     try {
       final token = await _getToken();
-      final response = await http.get(url, headers: {'Authorization': 'Bearer $token'});
+      final response = await getAllPages(url, headers: {'Authorization': 'Bearer $token'});
       if (response.statusCode == 200 && mounted) {
         setState(() {
-          _companies = jsonDecode(response.body)['companies'] ?? [];
+          _companies = jsonDecode(response.body)['items'] ?? [];
           _isLoadingCompanies = false;
         });
       } else {
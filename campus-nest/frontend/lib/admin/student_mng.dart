@@ -10,6 +10,7 @@ import 'package:frontend/toast.dart';
 import 'package:google_fonts/google_fonts.dart';
 import 'package:http/http.dart' as http;
 import 'package:shared_preferences/shared_preferences.dart';
+import 'package:frontend/pagination.dart';
 
 class StudentDetailsPage extends StatelessWidget {
   final String studentId;
@@ -72,9 +73,9 @@ class _ManageStudentsScreenState extends State<ManageStudentsScreen> {
       return;
     }
 
-    final url = Uri.parse('https://campusnest-backend-lkue.onrender.com/api/v1/tpo/students');
+    final url = Uri.parse('https://campusnest-backend-lkue.onrender.com/api/v1/tpo/students?limit=100');
     try {
-      final response = await http.get(
+      final response = await getAllPages(
         url,
         headers: {
           'Content-Type': 'application/json',
@@ -85,8 +86,8 @@ class _ManageStudentsScreenState extends State<ManageStudentsScreen> {
       if (response.statusCode == 200 && mounted) {
         final data = jsonDecode(response.body);
         setState(() {
-          _allStudents = data['students'] ?? [];
-          _stats = data['statistics'] ?? {};
+          _allStudents = data['items'] ?? [];
+          _stats = data['summary'] ?? {};
           _isLoading = false;
         });
       } else {
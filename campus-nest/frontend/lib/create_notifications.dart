@@ -6,6 +6,7 @@ import 'package:http/http.dart' as http;
 import 'package:shared_preferences/shared_preferences.dart';
 
 import '../toast.dart'; 
+import 'package:frontend/pagination.dart';
 
 class CreateNotificationPage extends StatefulWidget {
   const CreateNotificationPage({Key? key}) : super(key: key);
@@ -57,13 +58,13 @@ class _CreateNotificationPageState extends State<CreateNotificationPage> {
       if (mounted) setState(() => _isLoadingStudents = false);
       return;
     }
-    final url = Uri.parse('https://campusnest-backend-lkue.onrender.com/api/v1/tpo/students');
+    final url = Uri.parse('https://campusnest-backend-lkue.onrender.com/api/v1/tpo/students?limit=100');
     try {
-      final response = await http.get(url, headers: {'Authorization': 'Bearer $token'});
+      final response = await getAllPages(url, headers: {'Authorization': 'Bearer $token'});
       if (response.statusCode == 200 && mounted) {
         print("Students fetched: ${response.body}");
         setState(() {
-          _allStudents = jsonDecode(response.body)['students'] ?? [];
+          _allStudents = jsonDecode(response.body)['items'] ?? [];
           _isLoadingStudents = false;
         });
       } else {
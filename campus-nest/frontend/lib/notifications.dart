@@ -3,9 +3,9 @@ import 'package:flutter/material.dart';
 import 'package:frontend/create_notifications.dart';
 import 'package:frontend/toast.dart';
 import 'package:google_fonts/google_fonts.dart';
-import 'package:http/http.dart' as http;
 import 'package:shared_preferences/shared_preferences.dart';
 import 'package:intl/intl.dart';
+import 'package:frontend/pagination.dart';
 
 class NotificationItem {
   final String id;
@@ -110,10 +110,10 @@ class _NotificationsPageState extends State<NotificationsPage>
     }
     final url;
       url = Uri.parse(
-        'https://campusnest-backend-lkue.onrender.com/api/v1/$role/notifications',
+        'https://campusnest-backend-lkue.onrender.com/api/v1/$role/notifications?limit=100',
       );
     try {
-      final response = await http.get(
+      final response = await getAllPages(
         url,
         headers: {
           'Content-Type': 'application/json',
@@ -126,7 +126,7 @@ class _NotificationsPageState extends State<NotificationsPage>
       if (response.statusCode == 200) {
         final data = jsonDecode(response.body);
         print(data);
-        final List<dynamic> notificationList = data['notifications'] ?? [];
+        final List<dynamic> notificationList = data['items'] ?? [];
 
         setState(() {
           _notifications = notificationList
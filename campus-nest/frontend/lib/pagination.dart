@@ -0,0 +1,30 @@
+import 'dart:convert';
+import 'package:http/http.dart' as http;
+
+/// List endpoints return at most 100 items per page together with a
+/// `nextCursor` for the rest. getAllPages follows the cursor and returns a
+/// response whose body is the first page's envelope with the items of every
+/// page. A page that fails is returned as it is.
+Future<http.Response> getAllPages(Uri url, {Map<String, String>? headers}) async {
+  final first = await http.get(url, headers: headers);
+  if (first.statusCode != 200) return first;
+
+  final Map<String, dynamic> data = jsonDecode(first.body);
+  final List<dynamic> items = List<dynamic>.from(data['items'] ?? []);
+  var cursor = data['nextCursor'];
+  while (cursor != null && cursor != '') {
+    final pageUrl = url.replace(
+      queryParameters: {...url.queryParameters, 'cursor': cursor.toString()},
+    );
+    final response = await http.get(pageUrl, headers: headers);
+    if (response.statusCode != 200) return response;
+
+    final page = jsonDecode(response.body);
+    items.addAll(page['items'] ?? []);
+    cursor = page['nextCursor'];
+  }
+
+  data['items'] = items;
+  data.remove('nextCursor');
+  return http.Response(jsonEncode(data), 200, headers: first.headers);
+}
@@ -9,6 +9,7 @@ import 'package:http/http.dart' as http;
 import 'package:shared_preferences/shared_preferences.dart';
 
 import '../toast.dart';
+import 'package:frontend/pagination.dart';
 
 class RecruiterApplicationsScreen extends StatefulWidget {
   const RecruiterApplicationsScreen({Key? key}) : super(key: key);
@@ -71,16 +72,16 @@ class _RecruiterApplicationsScreenState
       return;
     }
     final url = Uri.parse(
-      'https://campusnest-backend-lkue.onrender.com/api/v1/rec/job-drives',
+      'https://campusnest-backend-lkue.onrender.com/api/v1/rec/job-drives?limit=100',
     );
     try {
-      final response = await http.get(
+      final response = await getAllPages(
         url,
         headers: {'Authorization': 'Bearer $token'},
       );
       if (response.statusCode == 200 && mounted) {
         setState(() {
-          _allJobDrives = jsonDecode(response.body)['jobDrives'] ?? [];
+          _allJobDrives = jsonDecode(response.body)['items'] ?? [];
           _isLoadingDrives = false;
         });
       } else {
@@ -5,9 +5,9 @@ import 'package:frontend/custom_pallete.dart';
 import 'package:frontend/notifications.dart';
 import 'package:frontend/recruiter_screens/job_drive_details.dart';
 import 'package:google_fonts/google_fonts.dart';
-import 'package:http/http.dart' as http;
 import 'package:intl/intl.dart';
 import 'package:shared_preferences/shared_preferences.dart';
+import 'package:frontend/pagination.dart';
 
 class RecruiterDrivesScreen extends StatefulWidget {
   const RecruiterDrivesScreen({Key? key}) : super(key: key);
@@ -57,11 +57,11 @@ class _RecruiterDrivesScreenState extends State<RecruiterDrivesScreen> {
     }
 
     final url = Uri.parse(
-      'https://campusnest-backend-lkue.onrender.com/api/v1/rec/job-drives',
+      'https://campusnest-backend-lkue.onrender.com/api/v1/rec/job-drives?limit=100',
     );
 
     try {
-      final response = await http.get(
+      final response = await getAllPages(
         url,
         headers: {
           'Content-Type': 'application/json',
@@ -73,7 +73,7 @@ class _RecruiterDrivesScreenState extends State<RecruiterDrivesScreen> {
         final data = jsonDecode(response.body);
         print('Fetched job drives: $data');
         setState(() {
-          _jobDrives = data['jobDrives'] ?? [];
+          _jobDrives = data['items'] ?? [];
           _filteredDrives = _jobDrives;
           _isLoading = false;
         });
@@ -5,9 +5,9 @@ import 'package:frontend/notifications.dart';
 import 'package:frontend/student_screens/app_deets.dart';
 import 'package:frontend/student_screens/profile_screen.dart';
 import 'package:google_fonts/google_fonts.dart';
-import 'package:http/http.dart' as http;
 import 'package:intl/intl.dart';
 import 'package:shared_preferences/shared_preferences.dart';
+import 'package:frontend/pagination.dart';
 
 class ApplicationsScreen extends StatefulWidget {
   const ApplicationsScreen({super.key});
@@ -75,11 +75,11 @@ class _ApplicationsScreenState extends State<ApplicationsScreen>
     }
 
     final url = Uri.parse(
-      'https://campusnest-backend-lkue.onrender.com/api/v1/student/applications',
+      'https://campusnest-backend-lkue.onrender.com/api/v1/student/applications?limit=100',
     );
 
     try {
-      final response = await http.get(
+      final response = await getAllPages(
         url,
         headers: {
           'Content-Type': 'application/json',
@@ -92,7 +92,7 @@ class _ApplicationsScreenState extends State<ApplicationsScreen>
         print('Applications Data: $data');
 
         setState(() {
-          applications = data ?? [];
+          applications = data['items'] ?? [];
           filteredApplications = applications;
           isLoading = false;
         });
@@ -5,9 +5,9 @@ import 'package:frontend/notifications.dart';
 import 'package:frontend/student_screens/jobdeets_screen.dart';
 import 'package:frontend/student_screens/profile_screen.dart';
 import 'package:google_fonts/google_fonts.dart';
-import 'package:http/http.dart' as http;
 import 'package:intl/intl.dart';
 import 'package:shared_preferences/shared_preferences.dart';
+import 'package:frontend/pagination.dart';
 
 
 class JobsPage extends StatefulWidget {
@@ -520,11 +520,11 @@ void _toggleBookmark(dynamic jobId) {
     List<String> bookmarkedIds = prefs.getStringList('bookmarked_jobs') ?? [];
     Set<String> bookmarkSet = bookmarkedIds.toSet();
 
-    final url = Uri.parse('https://campusnest-backend-lkue.onrender.com/api/v1/student/jobs');
+    final url = Uri.parse('https://campusnest-backend-lkue.onrender.com/api/v1/student/jobs?limit=100');
     try {
-      final response = await http.get(url, headers: {'Content-Type': 'application/json', 'Authorization': 'Bearer $token'});
+      final response = await getAllPages(url, headers: {'Content-Type': 'application/json', 'Authorization': 'Bearer $token'});
       if (response.statusCode == 200) {
-        final data = jsonDecode(response.body);
+        final data = jsonDecode(response.body)['items'];
         print("Jobs: $data");
         for (var job in data) {
         if (bookmarkSet.contains(job['job']['id'])) {
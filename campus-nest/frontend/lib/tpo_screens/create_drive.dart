@@ -7,6 +7,7 @@ import 'package:intl/intl.dart';
 import 'package:shared_preferences/shared_preferences.dart';
 
 import '../toast.dart';
+import 'package:frontend/pagination.dart';
 
 class CreateDrivePage extends StatefulWidget {
   const CreateDrivePage({Key? key}) : super(key: key);
@@ -72,13 +73,13 @@ class _CreateDrivePageState extends State<CreateDrivePage> with SingleTickerProv
       if (mounted) setState(() => _isLoadingCompanies = false);
       return;
     }
-    final url = Uri.parse("https://campusnest-backend-lkue.onrender.com/api/v1/tpo/companies");
+    final url = Uri.parse("https://campusnest-backend-lkue.onrender.com/api/v1/tpo/companies?limit=100");
     try {
-      final response = await http.get(url, headers: {'Authorization': 'Bearer $token'});
+      final response = await getAllPages(url, headers: {'Authorization': 'Bearer $token'});
       if (response.statusCode == 200 && mounted) {
         print('Fetched companies: ${response.body}');
         setState(() {
-          _companies = jsonDecode(response.body)['companies'] ?? [];
+          _companies = jsonDecode(response.body)['items'] ?? [];
           _isLoadingCompanies = false;
         });
       } else {
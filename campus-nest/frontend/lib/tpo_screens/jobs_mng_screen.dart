@@ -1,11 +1,11 @@
 import 'dart:convert';
 import 'package:flutter/material.dart';
 import 'package:google_fonts/google_fonts.dart';
-import 'package:http/http.dart' as http;
 import 'package:intl/intl.dart';
 import 'package:shared_preferences/shared_preferences.dart';
 
 import 'create_drive.dart'; 
+import 'package:frontend/pagination.dart';
 
 class JobsManagementScreen extends StatefulWidget {
   const JobsManagementScreen({super.key});
@@ -53,11 +53,11 @@ class _JobsManagementScreenState extends State<JobsManagementScreen> {
     }
 
     final url = Uri.parse(
-      'https://campusnest-backend-lkue.onrender.com/api/v1/tpo/drives',
+      'https://campusnest-backend-lkue.onrender.com/api/v1/tpo/drives?limit=100',
     );
 
     try {
-      final response = await http.get(
+      final response = await getAllPages(
         url,
         headers: {
           'Content-Type': 'application/json',
@@ -69,7 +69,7 @@ class _JobsManagementScreenState extends State<JobsManagementScreen> {
         final data = jsonDecode(response.body);
         print('Fetched drives: $data');
         setState(() {
-          drives = data['drives'] ?? [];
+          drives = data['items'] ?? [];
           isLoading = false;
         });
       } else {
@@ -1,8 +1,8 @@
 import 'dart:convert';
 import 'package:flutter/material.dart';
 import 'package:google_fonts/google_fonts.dart';
-import 'package:http/http.dart' as http;
 import 'package:shared_preferences/shared_preferences.dart';
+import 'package:frontend/pagination.dart';
 
 class StudentsListScreen extends StatefulWidget {
   const StudentsListScreen({super.key});
@@ -51,10 +51,10 @@ class _StudentsListScreenState extends State<StudentsListScreen> {
       return;
     }
 
-    final url = Uri.parse('https://campusnest-backend-lkue.onrender.com/api/v1/tpo/students');
+    final url = Uri.parse('https://campusnest-backend-lkue.onrender.com/api/v1/tpo/students?limit=100');
 
     try {
-      final response = await http.get(
+      final response = await getAllPages(
         url,
         headers: {
           'Content-Type': 'application/json',
@@ -66,8 +66,8 @@ class _StudentsListScreenState extends State<StudentsListScreen> {
         final data = jsonDecode(response.body);
         print('Students data: $data');
         setState(() {
-          _allStudents = data['students'] ?? [];
-          _stats = data['statistics'] ?? {};
+          _allStudents = data['items'] ?? [];
+          _stats = data['summary'] ?? {};
           _isLoading = false;
         });
       } else {
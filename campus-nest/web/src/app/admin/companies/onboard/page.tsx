@@ -1,17 +1,16 @@
 import React, { Suspense } from "react";
 import OnboardCompanyForm from "@/components/OnboardCompanyForm";
 import { cookies } from "next/headers";
+import { fetchAllPages } from "@/lib/fetchAllPages";
 
 async function getCompanies() {
   const token = (await cookies()).get("auth_token")?.value;
   if (!token) throw new Error("Unauthorized");
 
-  const url = "https://campusnest-backend-lkue.onrender.com/api/v1/admin/companies";
+  const url = "https://campusnest-backend-lkue.onrender.com/api/v1/admin/companies?limit=100";
   try {
-    const res = await fetch(url, { headers: { Authorization: `Bearer ${token}` } });
-    if (!res.ok) throw new Error("Failed to fetch companies");
-    const data = await res.json();
-    return data.companies || [];
+    const data = await fetchAllPages(url, { headers: { Authorization: `Bearer ${token}` } });
+    return data.items || [];
   } catch (error) {
     console.error("Error fetching companies:", error);
     return [];
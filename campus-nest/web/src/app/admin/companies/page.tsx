@@ -3,21 +3,20 @@ import { cookies } from "next/headers";
 import Link from "next/link";
 import CompanyList from "@/components/CompanyList";
 import { Plus } from "lucide-react";
+import { fetchAllPages } from "@/lib/fetchAllPages";
 
 async function getCompanies() {
   const token = (await cookies()).get("auth_token")?.value;
   if (!token) throw new Error("Unauthorized");
 
-  const url = "https://campusnest-backend-lkue.onrender.com/api/v1/admin/companies";
+  const url = "https://campusnest-backend-lkue.onrender.com/api/v1/admin/companies?limit=100";
   try {
-    const res = await fetch(url, {
+    const data = await fetchAllPages(url, {
       headers: { Authorization: `Bearer ${token}` },
       next: { revalidate: 60 },
     });
-    if (!res.ok) throw new Error("Failed to fetch companies");
-    const data = await res.json();
     console.log(data)
-    return data.companies || [];
+    return data.items || [];
   } catch (error) {
     console.error("Error fetching companies:", error);
     return [];
@@ -3,6 +3,7 @@ import { cookies } from "next/headers";
 import Link from "next/link";
 import { ArrowLeft, Briefcase, Building, Calendar, Check, Mail, MapPin, School, Star, Users } from "lucide-react";
 import { format } from "date-fns";
+import { fetchAllPages } from "@/lib/fetchAllPages";
 
 // --- Data Fetching ---
 
@@ -24,14 +25,12 @@ async function getDriveDetails(id: string, token: string) {
 
 // 2. Fetches the list of applicants for that drive
 async function getDriveApplications(id: string, token: string) {
-  const url = `https://campusnest-backend-lkue.onrender.com/api/v1/admin/drives/${id}/applications`;
+  const url = `https://campusnest-backend-lkue.onrender.com/api/v1/admin/drives/${id}/applications?limit=100`;
   try {
-    const res = await fetch(url, {
+    return await fetchAllPages(url, {
       headers: { Authorization: `Bearer ${token}` },
       next: { revalidate: 60 },
     });
-    if (!res.ok) throw new Error(await res.text());
-    return res.json();
   } catch (error) {
     console.error(`Error fetching applications for ${id}:`, error);
     return null;
@@ -86,7 +85,7 @@ export default async function DriveDetailPage({
   }
 
   const drive = driveData.drive || {}; // Assuming data is nested
-  const applicants = applicationsData?.applications || []; // Assuming data is nested
+  const applicants = applicationsData?.items || []; // Assuming data is nested
 
   return (
     <div className="flex flex-col gap-6">
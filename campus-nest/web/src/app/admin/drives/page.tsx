@@ -1,24 +1,20 @@
 import React from "react";
 import { cookies } from "next/headers";
 import DriveListClient from "@/components/DriveListClient";
+import { fetchAllPages } from "@/lib/fetchAllPages";
 
 async function getAllDrives() {
   const token = (await cookies()).get("auth_token")?.value;
   if (!token) throw new Error("Unauthorized");
 
-  const url = "https://campusnest-backend-lkue.onrender.com/api/v1/admin/drives";
+  const url = "https://campusnest-backend-lkue.onrender.com/api/v1/admin/drives?limit=100";
 
   try {
-    const res = await fetch(url, {
+    const data = await fetchAllPages(url, {
       headers: { Authorization: `Bearer ${token}` },
       next: { revalidate: 60 },
     });
-    if (!res.ok) {
-      const errorData = await res.text();
-      throw new Error(errorData || "Failed to fetch drives");
-    }
-    const data = await res.json();
-    return data.drives || [];
+    return data.items || [];
   } catch (error) {
     console.error("Error fetching all drives:", error);
     return []; 
@@ -1,27 +1,21 @@
 import React from "react";
 import { cookies } from "next/headers";
 import StudentClientPage from "@/components/StudentClientPage";
+import { fetchAllPages } from "@/lib/fetchAllPages";
 
 // --- API Data Fetching (Server-Side) ---
 async function getStudentData() {
   const token = (await cookies()).get("auth_token")?.value;
   if (!token) throw new Error("Authentication token not found.");
 
-  const url = "https://campusnest-backend-lkue.onrender.com/api/v1/admin/students";
+  const url = "https://campusnest-backend-lkue.onrender.com/api/v1/admin/students?limit=100";
   
   try {
-    const res = await fetch(url, {
+    return await fetchAllPages(url, {
       headers: { Authorization: `Bearer ${token}` },
       next: { revalidate: 60 }, // Cache data for 60 seconds
     });
 
-    if (!res.ok) {
-      const errorText = await res.text();
-      throw new Error(`Failed to fetch students: ${errorText}`);
-    }
-    
-    return await res.json();
-
   } catch (error) {
     console.error("Error fetching student data:", error);
     return null; // Return null on error
@@ -38,8 +32,8 @@ export default async function ManageStudentsPage() {
   // Pass the fetched data as props to the Client Component
   return (
     <StudentClientPage 
-      allStudents={data.students || []} 
-      stats={data.statistics || {}} 
+      allStudents={data.items || []} 
+      stats={{ ...data.summary, totalReturned: data.total || 0 }} 
     />
   );
 }
@@ -2,26 +2,22 @@ import React from "react";
 import { cookies } from "next/headers";
 import TpoClientPage from "@/components/TpoClientPage";
 import { redirect } from "next/navigation";
+import { fetchAllPages } from "@/lib/fetchAllPages";
 
 // --- Data Fetching ---
 async function getTpos() {
     const token = (await cookies()).get("auth_token")?.value;
   if (!token) redirect("/login"); 
 
-  const url = "https://campusnest-backend-lkue.onrender.com/api/v1/admin/tpos";
+  const url = "https://campusnest-backend-lkue.onrender.com/api/v1/admin/tpos?limit=100";
 
   try {
-    const res = await fetch(url, {
+    const data = await fetchAllPages(url, {
       headers: { Authorization: `Bearer ${token}` },
       next: { revalidate: 60 }, // Cache for 1 minute
     });
-    if (!res.ok) {
-      const errorData = await res.text();
-      throw new Error(errorData || "Failed to fetch TPOs");
-    }
-    const data = await res.json();
     console.log(data);
-    return data.tpos || []; // Assuming the list is in a 'tpos' key
+    return data.items || [];
   } catch (error) {
     console.error("Error fetching TPOs:", error);
     return []; // Return empty on error
@@ -0,0 +1,29 @@
+// List endpoints return at most 100 items per page along with a nextCursor
+// for the rest. fetchAllPages follows the cursor and returns the first page's
+// envelope with the items of every page.
+export async function fetchAllPages(
+  url: string,
+  init?: RequestInit & { next?: { revalidate?: number } }
+) {
+  let data: any = null;
+  let cursor: string | null = null;
+  do {
+    const pageUrl = new URL(url);
+    if (cursor) pageUrl.searchParams.set("cursor", cursor);
+
+    const res = await fetch(pageUrl.toString(), init);
+    if (!res.ok) {
+      const errorText = await res.text();
+      throw new Error(errorText || `Failed to fetch ${pageUrl.pathname}`);
+    }
+    const page = await res.json();
+    if (data === null) {
+      data = { ...page, items: [] };
+    }
+    data.items.push(...(page.items || []));
+    cursor = page.nextCursor || null;
+  } while (cursor);
+
+  delete data.nextCursor;
+  return data;
+}